@@ -154,7 +154,7 @@ fi
 
 # Create directories
 echo -e "${BLUE}📁 Creating directories...${NC}"
-sudo mkdir -p /home/portico/{apps,reverse-proxy,templates,www,logs/apps,addons/definitions,addons/instances,repos,bin,.tmp}
+sudo mkdir -p /home/portico/{apps,caddy/apps,reverse-proxy,templates,www,logs/apps,addons/definitions,addons/instances,repos,sites,bin,.tmp}
 sudo chown -R portico:portico /home/portico
 
 # Create symbolic link for Docker container logs
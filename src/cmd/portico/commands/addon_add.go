@@ -12,6 +12,8 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
 )
 
 // NewAddonAddCmd adds an inline addon (redis/valkey) as a service to an app
@@ -117,11 +119,12 @@ func NewAddonAddCmd() *cobra.Command {
 			appDir := filepath.Join(cfg.AppsDir, appName)
 			envDir := filepath.Join(appDir, "env")
 
+			store := secrets.NewStore(cfg.SecretsKey)
 			var addonPassword string
 			for _, secretName := range versionConfig.Secrets {
 				secretPath := filepath.Join(envDir, secretName)
 				defaultValue := generateSecret(secretName)
-				if err := os.WriteFile(secretPath, []byte(defaultValue), 0o600); err != nil {
+				if err := store.WriteFile(secretPath, []byte(defaultValue)); err != nil {
 					fmt.Printf("Warning: could not create secret %s: %v\n", secretName, err)
 				}
 				if strings.Contains(strings.ToLower(secretName), "password") {
@@ -190,10 +193,15 @@ func NewAddonAddCmd() *cobra.Command {
 				return
 			}
 
+			event := journal.Event{
+				App: appName, Action: "addons add", Summary: fmt.Sprintf("%s %s", addonType, version),
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			fmt.Printf("Addon %s (version %s) added to app %s\n", addonType, version, appName)
 		},
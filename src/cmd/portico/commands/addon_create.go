@@ -11,6 +11,9 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/addon"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/ports"
+	"github.com/maxvegac/portico/src/internal/secrets"
 )
 
 // NewAddonCreateCmd creates a new addon instance
@@ -78,18 +81,17 @@ func NewAddonCreateCmd() *cobra.Command {
 				return
 			}
 
-			// Determine port (find available port)
-			port := def.DefaultPort
-			portInUse := make(map[int]bool)
-			for _, inst := range config.Instances {
-				if inst.Type == addonType {
-					portInUse[inst.Port] = true
-				}
+			// Determine port: first free host port from the default port,
+			// checked against all apps, addon instances and the host
+			registry, err := ports.Scan(cfg.AppsDir, cfg.AddonsDir)
+			if err != nil {
+				fmt.Printf("Error loading port registry: %v\n", err)
+				return
 			}
-
-			// Find next available port
-			for portInUse[port] {
-				port++
+			port, err := registry.Allocate("tcp", def.DefaultPort, 65535)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
 			}
 
 			// Create instance directory
@@ -108,10 +110,11 @@ func NewAddonCreateCmd() *cobra.Command {
 			}
 
 			// Generate secrets
+			store := secrets.NewStore(cfg.SecretsKey)
 			for _, secretName := range versionConfig.Secrets {
 				secretPath := filepath.Join(secretsDir, secretName)
 				defaultValue := generateSecret(secretName)
-				if err := os.WriteFile(secretPath, []byte(defaultValue), 0o600); err != nil {
+				if err := store.WriteFile(secretPath, []byte(defaultValue)); err != nil {
 					fmt.Printf("Error creating secret %s: %v\n", secretName, err)
 					return
 				}
@@ -142,11 +145,14 @@ func NewAddonCreateCmd() *cobra.Command {
 			}
 
 			// Generate docker-compose.yml for the instance
-			if err := generateAddonCompose(instanceDir, instance, def, versionConfig); err != nil {
+			if err := generateAddonCompose(instanceDir, addonSecretsSource(cfg, instanceName), instance, def, versionConfig); err != nil {
 				fmt.Printf("Error generating docker-compose.yml: %v\n", err)
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: instanceName, Action: "addons create", Summary: fmt.Sprintf("%s %s (%s)", addonType, version, mode),
+			})
 			fmt.Printf("Addon instance %s created successfully!\n", instanceName)
 			fmt.Printf("Type: %s, Version: %s, Mode: %s, Port: %d\n", addonType, version, mode, port)
 			if mode == "dedicated" {
@@ -162,8 +168,9 @@ func NewAddonCreateCmd() *cobra.Command {
 	return cmd
 }
 
-// generateAddonCompose generates docker-compose.yml for an addon instance
-func generateAddonCompose(instanceDir string, inst addon.Instance, def *addon.Definition, versionConfig *addon.VersionConfig) error {
+// generateAddonCompose generates docker-compose.yml for an addon instance.
+// secretsSource is the directory mounted at /run/secrets (see addonSecretsSource)
+func generateAddonCompose(instanceDir, secretsSource string, inst addon.Instance, def *addon.Definition, versionConfig *addon.VersionConfig) error {
 	composeFile := filepath.Join(instanceDir, "docker-compose.yml")
 
 	// Build service configuration
@@ -185,7 +192,7 @@ func generateAddonCompose(instanceDir string, inst addon.Instance, def *addon.De
 		hostPath := strings.Replace(vol.HostPath, "./data", filepath.Join(instanceDir, "data"), 1)
 		volumes = append(volumes, fmt.Sprintf("%s:%s", hostPath, vol.ContainerPath))
 	}
-	volumes = append(volumes, fmt.Sprintf("%s/secrets:/run/secrets:ro", instanceDir))
+	volumes = append(volumes, fmt.Sprintf("%s:/run/secrets:ro", secretsSource))
 	serviceMap["volumes"] = volumes
 
 	// Secrets
@@ -219,7 +226,7 @@ func generateAddonCompose(instanceDir string, inst addon.Instance, def *addon.De
 	secretsMap := make(map[string]interface{})
 	for _, secret := range versionConfig.Secrets {
 		secretsMap[secret] = map[string]string{
-			"file": fmt.Sprintf("./secrets/%s", secret),
+			"file": fmt.Sprintf("%s/%s", secretsSource, secret),
 		}
 	}
 	compose["secrets"] = secretsMap
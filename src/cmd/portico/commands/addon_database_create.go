@@ -11,6 +11,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/addon"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewAddonDatabaseCreateCmd creates a database in an addon instance
@@ -96,6 +97,9 @@ func NewAddonDatabaseCreateCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: addonInstanceName, Action: "addons database create", Summary: dbName,
+			})
 			fmt.Printf("Database %s created successfully in %s\n", dbName, addonInstanceName)
 		},
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/addon"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewAddonDatabaseDeleteCmd deletes a database from an addon instance
@@ -95,6 +96,9 @@ func NewAddonDatabaseDeleteCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: addonInstanceName, Action: "addons database delete", Summary: dbName,
+			})
 			fmt.Printf("Database %s deleted successfully from %s\n", dbName, addonInstanceName)
 		},
 	}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
 
@@ -12,6 +11,8 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
 )
 
 // NewAddonLinkCmd links an app to an addon instance and adds environment variables
@@ -77,10 +78,11 @@ func NewAddonLinkCmd() *cobra.Command {
 			secretsDir := filepath.Join(instanceDir, "secrets")
 
 			// Read connection credentials
-			dbUser := readSecret(filepath.Join(secretsDir, "db_user"))
-			dbPassword := readSecret(filepath.Join(secretsDir, "db_password"))
+			store := secrets.NewStore(cfg.SecretsKey)
+			dbUser := readSecret(store, filepath.Join(secretsDir, "db_user"))
+			dbPassword := readSecret(store, filepath.Join(secretsDir, "db_password"))
 			if dbUser == "" {
-				dbUser = readSecret(filepath.Join(secretsDir, "db_name")) // Fallback
+				dbUser = readSecret(store, filepath.Join(secretsDir, "db_name")) // Fallback
 			}
 
 			// Generate environment variables based on database type
@@ -178,10 +180,15 @@ func NewAddonLinkCmd() *cobra.Command {
 				return
 			}
 
+			event := journal.Event{
+				App: appName, Action: "addons link", Summary: fmt.Sprintf("%s, database %s", addonInstanceName, dbName),
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			fmt.Printf("App %s linked to addon %s with database %s\n", appName, addonInstanceName, dbName)
 			fmt.Printf("Environment variables added to all services\n")
@@ -206,9 +213,9 @@ func getEnvPrefix(dbType string) string {
 	}
 }
 
-// readSecret reads a secret file
-func readSecret(path string) string {
-	data, err := os.ReadFile(path)
+// readSecret reads a secret file, decrypting it if secrets are encrypted at rest
+func readSecret(store *secrets.Store, path string) string {
+	data, err := store.ReadFile(path)
 	if err != nil {
 		return ""
 	}
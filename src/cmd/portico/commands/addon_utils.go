@@ -1,14 +1,29 @@
 package commands
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
-// generateSecret generates a default secret value
+// defaultSecretLength is the length of generated passwords and keys
+const defaultSecretLength = 32
+
+// generateSecret generates a default secret value: a random value for
+// passwords and keys, a conventional name otherwise
 func generateSecret(secretName string) string {
 	nameLower := strings.ToLower(secretName)
-	if strings.Contains(nameLower, "password") {
-		return "changeme123"
+	for _, word := range []string{"password", "secret", "key", "token"} {
+		if strings.Contains(nameLower, word) {
+			if value, err := util.RandomString(defaultSecretLength, "alnum"); err == nil {
+				return value
+			}
+			return "changeme123"
+		}
 	}
 	if strings.Contains(nameLower, "user") {
 		return "admin"
@@ -21,3 +36,27 @@ func generateSecret(secretName string) string {
 	}
 	return "default"
 }
+
+// addonSecretsSource returns the host directory mounted at /run/secrets for an
+// addon instance: its secrets/ directory, or its tmpfs runtime directory when
+// secrets are encrypted at rest
+func addonSecretsSource(cfg *config.Config, instanceName string) string {
+	if secrets.NewStore(cfg.SecretsKey).Enabled() {
+		return secrets.RuntimeDir("addons", instanceName)
+	}
+	return filepath.Join(cfg.AddonsDir, "instances", instanceName, "secrets")
+}
+
+// materializeAddonSecrets decrypts the secrets of an addon instance into its
+// tmpfs runtime directory. Does nothing when secrets are stored in plaintext
+func materializeAddonSecrets(cfg *config.Config, instanceName string) error {
+	store := secrets.NewStore(cfg.SecretsKey)
+	if !store.Enabled() {
+		return nil
+	}
+	secretsDir := filepath.Join(cfg.AddonsDir, "instances", instanceName, "secrets")
+	if err := store.Materialize(secretsDir, secrets.RuntimeDir("addons", instanceName)); err != nil {
+		return fmt.Errorf("error decrypting secrets: %w", err)
+	}
+	return nil
+}
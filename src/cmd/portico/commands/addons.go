@@ -20,6 +20,9 @@ func NewAddonsCmd() *cobra.Command {
 	// Instance management (addons [instance-name] up/down/delete)
 	cmd.AddCommand(NewAddonsInstanceCmd())
 
+	// Database backups
+	cmd.AddCommand(NewAddonsBackupCmd())
+
 	// Database management subcommand
 	databaseCmd := NewAddonDatabaseCmd()
 	databaseCmd.AddCommand(NewAddonDatabaseCreateCmd())
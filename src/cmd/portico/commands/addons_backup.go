@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/notify"
+)
+
+// NewAddonsBackupCmd dumps the databases of addon instances
+func NewAddonsBackupCmd() *cobra.Command {
+	var all bool
+	var keep int
+
+	cmd := &cobra.Command{
+		Use:   "backup [instance-name]",
+		Short: "Back up the databases of addon instances",
+		Long: `Dump every database of a running addon instance (PostgreSQL, MySQL, MariaDB,
+MongoDB) into a gzipped file in /home/portico/backups/<instance>/, and remove the
+oldest backups beyond --keep. A failed backup is notified (backup_failed).
+
+Examples:
+  portico addons backup my-postgres
+  portico addons backup --all --keep 14`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if (len(args) == 0) == !all {
+				fmt.Println("Error: give an instance name or --all")
+				fmt.Println("Usage: portico addons backup [instance-name] [--all] [--keep 7]")
+				os.Exit(1)
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				os.Exit(1)
+			}
+
+			am := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+			addonConfig, err := am.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading addons config: %v\n", err)
+				os.Exit(1)
+			}
+
+			var names []string
+			if all {
+				for name, instance := range addonConfig.Instances {
+					if addon.CanBackup(instance.Type) {
+						names = append(names, name)
+					}
+				}
+				sort.Strings(names)
+				if len(names) == 0 {
+					fmt.Println("No addon instances to back up")
+					return
+				}
+			} else {
+				instance, exists := addonConfig.Instances[args[0]]
+				if !exists {
+					fmt.Printf("Error: addon instance %s not found\n", args[0])
+					os.Exit(1)
+				}
+				if !addon.CanBackup(instance.Type) {
+					fmt.Printf("Error: addon instance %s (%s) can't be backed up; only PostgreSQL, MySQL, MariaDB and MongoDB can\n", args[0], instance.Type)
+					os.Exit(1)
+				}
+				names = args
+			}
+
+			failed := false
+			for _, name := range names {
+				dir := addon.BackupDir(cfg.PorticoHome, name)
+				path, err := am.Backup(name, addonConfig.Instances[name], dir)
+				if err != nil {
+					fmt.Printf("Error backing up %s: %v\n", name, err)
+					event := notify.NewEvent(notify.EventBackupFailed, name,
+						fmt.Sprintf("Backup of %s failed", name), err.Error())
+					notify.Notify(event)
+					failed = true
+					continue
+				}
+				fmt.Printf("✅ Backup of %s: %s\n", name, path)
+
+				removed, err := addon.PruneBackups(dir, keep)
+				if err != nil {
+					fmt.Printf("Warning: could not remove old backups of %s: %v\n", name, err)
+				}
+				if len(removed) > 0 {
+					fmt.Printf("   Removed %d old backup(s)\n", len(removed))
+				}
+			}
+
+			// Errors exit non-zero: the daemon runs this as a job and checks the exit code
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Back up every database instance")
+	cmd.Flags().IntVar(&keep, "keep", 7, "Number of backups to keep per instance (0 keeps all)")
+
+	return cmd
+}
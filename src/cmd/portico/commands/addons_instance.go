@@ -10,6 +10,8 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/addon"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
 )
 
 // NewAddonsInstanceCmd creates a command for managing a specific addon instance
@@ -92,6 +94,12 @@ func NewAddonsInstanceUpCmd() *cobra.Command {
 				return
 			}
 
+			// Decrypt secrets into tmpfs for the /run/secrets mount
+			if err := materializeAddonSecrets(cfg, instanceName); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
 			// Run docker compose up
 			execCmd := exec.Command("docker", "compose", "-f", composeFile, "up", "-d")
 			execCmd.Dir = instanceDir
@@ -102,6 +110,9 @@ func NewAddonsInstanceUpCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: instanceName, Action: "addons up",
+			})
 			fmt.Printf("Addon instance %s started successfully\n", instanceName)
 		},
 	}
@@ -160,6 +171,12 @@ func NewAddonsInstanceDownCmd() *cobra.Command {
 				return
 			}
 
+			// Decrypted secrets are only needed while containers run
+			_ = secrets.Clear(secrets.RuntimeDir("addons", instanceName))
+
+			journal.Record(journal.Event{
+				App: instanceName, Action: "addons down",
+			})
 			fmt.Printf("Addon instance %s stopped successfully\n", instanceName)
 		},
 	}
@@ -224,7 +241,11 @@ func NewAddonsInstanceDeleteCmd() *cobra.Command {
 			if err := os.RemoveAll(instanceDir); err != nil {
 				fmt.Printf("Warning: could not remove instance directory: %v\n", err)
 			}
+			_ = secrets.Clear(secrets.RuntimeDir("addons", instanceName))
 
+			journal.Record(journal.Event{
+				App: instanceName, Action: "addons delete",
+			})
 			fmt.Printf("Addon instance %s deleted successfully\n", instanceName)
 		},
 	}
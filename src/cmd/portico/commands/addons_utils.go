@@ -16,6 +16,7 @@ func getAppNameFromAddonsArgs(_ *cobra.Command) (string, error) {
 		"instances": true,
 		"create":    true,
 		"database":  true,
+		"backup":    true,
 		"add":       true,
 		"link":      true,
 		"up":        true,
@@ -53,6 +54,7 @@ func getInstanceNameFromAddonsArgs(_ *cobra.Command) (string, error) {
 		"instances": true,
 		"create":    true,
 		"database":  true,
+		"backup":    true,
 		"add":       true,
 		"link":      true,
 		"up":        true,
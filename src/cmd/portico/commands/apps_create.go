@@ -11,6 +11,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 	"github.com/maxvegac/portico/src/internal/util"
 )
@@ -21,6 +22,11 @@ func NewAppsCreateCmd() *cobra.Command {
 	var image string
 	var noHTTPPort bool
 	var servicePort int
+	var static bool
+	var buildImage string
+	var buildCommand string
+	var outputDir string
+	var spa bool
 
 	cmd := &cobra.Command{
 		Use:   "create [app-name]",
@@ -42,6 +48,9 @@ Examples:
   # Create app with a background worker
   portico create my-app --with-service worker --image myregistry.com/worker:v1.0.0 --no-http-port
 
+  # Create a static site served directly by Caddy (published on git push)
+  portico create my-docs --static --build-image node:20-alpine --build-command "npm ci && npm run build" --output-dir dist --spa
+
   # Deploy will create services automatically
   portico deploy my-app`,
 		Args: cobra.ExactArgs(1),
@@ -129,6 +138,62 @@ Examples:
 				return
 			}
 
+			event := journal.Event{App: appName, Action: "apps create", After: appConfig.Domain}
+			if static {
+				event.Summary = "static site"
+			} else if withService != "" {
+				event.Summary = fmt.Sprintf("service %s (%s)", withService, image)
+			}
+			journal.Record(event)
+
+			// Static site: no services, Caddy serves the published release
+			if static {
+				if withService != "" {
+					fmt.Println("Error: --with-service cannot be used with --static")
+					return
+				}
+				if outputDir == "" {
+					outputDir = "."
+				}
+
+				err := dockerManager.UpdatePorticoMetadata(appDir, func(m *docker.PorticoMetadata) {
+					m.Port = 0
+					m.HttpEnabled = true
+					m.Static = &docker.StaticConfig{
+						BuildImage:   buildImage,
+						BuildCommand: buildCommand,
+						OutputDir:    outputDir,
+						SPA:          spa,
+					}
+				})
+				if err != nil {
+					fmt.Printf("Error saving static site settings: %v\n", err)
+					return
+				}
+
+				if err := os.MkdirAll(filepath.Join(appManager.StaticSiteDir(appName), "releases"), 0o755); err != nil {
+					fmt.Printf("Error creating site directory: %v\n", err)
+					return
+				}
+
+				if err := appManager.CreateDefaultCaddyfile(appName); err != nil {
+					fmt.Printf("Warning: could not create Caddyfile: %v\n", err)
+				}
+
+				proxyManager := proxy.NewCaddyManager(config.ProxyDir, config.TemplatesDir)
+				if err := proxyManager.UpdateCaddyfile(config.AppsDir); err != nil {
+					fmt.Printf("Error updating Caddyfile: %v\n", err)
+					return
+				}
+
+				fmt.Printf("✅ Static site %s created successfully!\n", appName)
+				fmt.Println()
+				fmt.Println("Next steps:")
+				fmt.Printf("  Push your site to publish it:\n")
+				fmt.Printf("    git push portico main\n")
+				return
+			}
+
 			// If --with-service flag is provided, create the service
 			if withService != "" {
 				if image == "" {
@@ -233,6 +298,11 @@ Examples:
 	cmd.Flags().StringVar(&image, "image", "", "Docker image for the service (required with --with-service)")
 	cmd.Flags().BoolVar(&noHTTPPort, "no-http-port", false, "Create a background worker without HTTP port")
 	cmd.Flags().IntVar(&servicePort, "port", 0, "Internal port for the service (default: 3000 for web services)")
+	cmd.Flags().BoolVar(&static, "static", false, "Create a static site served directly by Caddy (no containers)")
+	cmd.Flags().StringVar(&buildImage, "build-image", "", "Image to run the static site build in (e.g. node:20-alpine)")
+	cmd.Flags().StringVar(&buildCommand, "build-command", "", "Command that builds the static site (e.g. npm ci && npm run build)")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Directory to publish, relative to the repository root (default: .)")
+	cmd.Flags().BoolVar(&spa, "spa", false, "Single page app: serve index.html for unknown paths")
 
 	return cmd
 }
@@ -20,6 +20,7 @@ func NewAppsDeployCmd() *cobra.Command {
 	var dockerfile string
 	var imageName string
 	var buildArgs []string
+	var noWatch bool
 
 	cmd := &cobra.Command{
 		Use:   "deploy [app-name]",
@@ -95,6 +96,9 @@ Examples:
 				}
 			}
 
+			// The running release, to roll back to if the new one crash-loops
+			previous := currentRelease(cfg, appDir)
+
 			// Build Docker image
 			fmt.Printf("Building Docker image: %s\n", imageName)
 			fmt.Printf("Source: %s\n", absSourcePath)
@@ -172,7 +176,7 @@ Examples:
 			}
 
 			// Deploy the application
-			if err := dockerManager.DeployApp(appDir, dockerServices); err != nil {
+			if err := deployWithWatch(cfg, appDir, dockerServices, previous, !noWatch); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
 				return
 			}
@@ -193,6 +197,7 @@ Examples:
 	cmd.Flags().StringVar(&dockerfile, "dockerfile", "Dockerfile", "Dockerfile name or path (default: Dockerfile)")
 	cmd.Flags().StringVar(&imageName, "image", "", "Docker image name (default: portico-<app-name>:latest)")
 	cmd.Flags().StringArrayVar(&buildArgs, "build-arg", []string{}, "Build arguments for docker build (can be specified multiple times)")
+	cmd.Flags().BoolVar(&noWatch, "no-watch", false, "Don't watch the new release for crash loops (no automatic rollback)")
 
 	return cmd
 }
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+)
+
+// NewAppsDeploysCmd lists the deploy history of an app
+func NewAppsDeploysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploys [app-name]",
+		Short: "Show the deploy history of an application",
+		Long: `Show the deploys of an application, newest first: time, result, duration and
+images, and the automatic rollbacks with their reason.
+
+Example:
+  portico deploys my-app -n 50`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			appName := args[0]
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			history, err := docker.DeployHistory(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error reading deploy history: %v\n", err)
+				return
+			}
+			if len(history) == 0 {
+				fmt.Printf("No deploys recorded for %s\n", appName)
+				return
+			}
+
+			fmt.Printf("%-20s %-9s %-7s %-9s %s\n", "TIME", "ACTION", "RESULT", "DURATION", "IMAGES")
+			for i := len(history) - 1; i >= 0 && (limit <= 0 || i >= len(history)-limit); i-- {
+				record := history[i]
+				action := "deploy"
+				if record.IsRollback() {
+					action = "rollback"
+				}
+				result := "ok"
+				if !record.Success {
+					result = "failed"
+				}
+				when := record.Time
+				if t, err := time.Parse(time.RFC3339, record.Time); err == nil {
+					when = t.Local().Format("2006-01-02 15:04:05")
+				}
+				duration := (time.Duration(record.Duration * float64(time.Second))).Round(time.Second)
+
+				var images []string
+				for service, image := range record.Images {
+					images = append(images, service+"="+image)
+				}
+				sort.Strings(images)
+				fmt.Printf("%-20s %-9s %-7s %-9s %s\n", when, action, result, duration, orDash(strings.Join(images, ", ")))
+				if record.Reason != "" {
+					fmt.Printf("%20s reason: %s\n", "", record.Reason)
+				}
+				if record.Error != "" {
+					fmt.Printf("%20s error: %s\n", "", record.Error)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().IntP("limit", "n", 20, "Number of deploys to show (0: all)")
+
+	return cmd
+}
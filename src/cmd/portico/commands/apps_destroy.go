@@ -10,6 +10,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -74,12 +75,24 @@ func NewAppsDestroyCmd() *cobra.Command {
 				}
 			}
 
+			// Delete static site releases if they exist
+			siteDir := appManager.StaticSiteDir(appName)
+			if _, err := os.Stat(siteDir); err == nil {
+				fmt.Printf("Removing static site releases...\n")
+				if err := os.RemoveAll(siteDir); err != nil {
+					fmt.Printf("Warning: Error removing static site releases: %v\n", err)
+				}
+			}
+
 			// Update Caddyfile
 			proxyManager := proxy.NewCaddyManager(config.ProxyDir, config.TemplatesDir)
 			if err := proxyManager.UpdateCaddyfile(config.AppsDir); err != nil {
 				fmt.Printf("Warning: Error updating Caddyfile: %v\n", err)
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "apps destroy",
+			})
 			fmt.Printf("Application %s destroyed successfully!\n", appName)
 		},
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewAppsDownCmd baja los servicios (docker compose down) de una app
@@ -89,6 +90,9 @@ func NewAppsDownCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "down",
+			})
 			fmt.Printf("Services for %s are down.\n", appName)
 		},
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewAppsPreserveCmd creates the apps preserve command
@@ -114,6 +115,9 @@ func NewAppsPreserveCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "preserve", Summary: "docker-compose.yml",
+			})
 			fmt.Printf("Manual changes to docker-compose.yml have been preserved.\n")
 			fmt.Printf("Portico will maintain your customizations in future regenerations.\n")
 		},
@@ -9,6 +9,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -76,11 +77,16 @@ func NewAppsResetCmd() *cobra.Command {
 				return
 			}
 
+			event := journal.Event{
+				App: appName, Action: "apps reset", Summary: "regenerated docker-compose.yml",
+			}
 			// Deploy the application
 			if err := dockerManager.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error redeploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Update Caddyfile
 			proxyManager := proxy.NewCaddyManager(config.ProxyDir, config.TemplatesDir)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -34,6 +35,7 @@ func NewAppsSetDomainCmd() *cobra.Command {
 				return
 			}
 
+			previousDomain := a.Domain
 			a.Domain = domain
 			if err := am.SaveApp(a); err != nil {
 				fmt.Printf("Error saving app: %v\n", err)
@@ -51,6 +53,9 @@ func NewAppsSetDomainCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "apps set-domain", Summary: domain, Before: previousDomain, After: domain,
+			})
 			fmt.Printf("Domain for %s set to %s\n", appName, domain)
 		},
 	}
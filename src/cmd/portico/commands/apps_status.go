@@ -4,22 +4,117 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
-// ContainerInfo represents container information from docker compose ps
-type ContainerInfo struct {
-	Name    string `json:"Name"`
-	Service string `json:"Service"`
-	State   string `json:"State"`
-	Status  string `json:"Status"`
+// AppStatus is the status of an app, as printed by status --json
+type AppStatus struct {
+	App      string          `json:"app"`
+	Domain   string          `json:"domain,omitempty"`
+	Port     int             `json:"http_port,omitempty"`
+	Static   bool            `json:"static,omitempty"`
+	Release  string          `json:"release,omitempty"` // Static sites
+	Services []ServiceStatus `json:"services"`
+}
+
+// ServiceStatus is the status of a service and its containers (one per replica)
+type ServiceStatus struct {
+	Name       string                   `json:"name"`
+	Image      string                   `json:"image"`
+	Replicas   int                      `json:"replicas"` // Desired replicas
+	Running    int                      `json:"running"`
+	Resources  *docker.ResourcesConfig  `json:"resources,omitempty"` // Limits and reservations per replica
+	Containers []docker.ContainerStatus `json:"containers"`
+}
+
+// getAppStatus returns the status of an app and its containers; CPU and memory
+// are only read with stats, since docker stats takes about a second
+func getAppStatus(cfg *config.Config, a *app.App, stats bool) (*AppStatus, error) {
+	appDir := filepath.Join(cfg.AppsDir, a.Name)
+	dm := docker.NewManager(cfg.Registry.URL)
+
+	status := &AppStatus{
+		App:      a.Name,
+		Domain:   a.Domain,
+		Port:     a.Port,
+		Services: []ServiceStatus{},
+	}
+	metadata, err := dm.GetPorticoMetadata(appDir)
+	if err == nil && metadata.Static != nil {
+		status.Static = true
+		status.Release = metadata.Static.Release
+		return status, nil
+	}
+
+	containers, err := dm.GetContainerStatus(appDir)
+	if err != nil {
+		return nil, err
+	}
+	if stats {
+		if err := dm.AddContainerStats(containers); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, svc := range a.Services {
+		replicas := svc.Replicas
+		if replicas == 0 {
+			replicas = 1
+		}
+		serviceStatus := ServiceStatus{
+			Name:       svc.Name,
+			Image:      svc.Image,
+			Replicas:   replicas,
+			Containers: []docker.ContainerStatus{},
+		}
+		if metadata != nil {
+			if resources, ok := metadata.Resources[svc.Name]; ok && !resources.IsEmpty() {
+				serviceStatus.Resources = &resources
+			}
+		}
+		for _, c := range containers {
+			if c.Service != svc.Name {
+				continue
+			}
+			serviceStatus.Containers = append(serviceStatus.Containers, c)
+			if c.Running() {
+				serviceStatus.Running++
+			}
+		}
+		status.Services = append(status.Services, serviceStatus)
+	}
+	return status, nil
+}
+
+// containerStateText returns the icon and text for the state of a container
+func containerStateText(c docker.ContainerStatus) (string, string) {
+	switch c.State {
+	case "running":
+		if c.Health == "unhealthy" {
+			return "✗", "Running (unhealthy)"
+		}
+		if c.Health != "" {
+			return "✓", fmt.Sprintf("Running (%s)", c.Health)
+		}
+		return "✓", "Running"
+	case "exited":
+		return "✗", fmt.Sprintf("Stopped (exit code %d)", c.ExitCode)
+	case "restarting":
+		return "↻", "Restarting"
+	case "":
+		return "○", "Not running"
+	default:
+		return "○", strings.ToUpper(c.State[:1]) + c.State[1:]
+	}
 }
 
 // NewAppsStatusCmd creates the apps status command
@@ -27,10 +122,11 @@ func NewAppsStatusCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status [app-name]",
 		Short: "Show application services and their status",
-		Long:  "Display the status of all services in an application, including running containers and their states.",
+		Long:  "Display the status of all services in an application: every replica with its state, health, restarts, uptime, exit code, image and CPU/memory usage.\n\nUse --json for scripts.",
 		Args:  cobra.ExactArgs(1),
-		Run: func(_ *cobra.Command, args []string) {
+		Run: func(cmd *cobra.Command, args []string) {
 			appName := args[0]
+			asJSON, _ := cmd.Flags().GetBool("json")
 
 			cfg, err := config.LoadConfig()
 			if err != nil {
@@ -54,30 +150,20 @@ func NewAppsStatusCmd() *cobra.Command {
 				return
 			}
 
-			// Get container status using docker compose ps
-			cmd := exec.Command("docker", "compose", "-f", composeFile, "ps", "--format", "json")
-			cmd.Dir = appDir
-
-			output, err := cmd.Output()
+			status, err := getAppStatus(cfg, a, true)
 			if err != nil {
-				// If no containers are running, output might be empty
-				output = []byte{}
+				fmt.Printf("Error getting status: %v\n", err)
+				return
 			}
 
-			// Parse container information
-			containers := make(map[string]ContainerInfo)
-			if len(output) > 0 {
-				lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-				for _, line := range lines {
-					line = strings.TrimSpace(line)
-					if line == "" {
-						continue
-					}
-					var container ContainerInfo
-					if err := json.Unmarshal([]byte(line), &container); err == nil {
-						containers[container.Service] = container
-					}
+			if asJSON {
+				data, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding JSON: %v\n", err)
+					return
 				}
+				fmt.Println(string(data))
+				return
 			}
 
 			// Display header
@@ -88,6 +174,27 @@ func NewAppsStatusCmd() *cobra.Command {
 			if a.Port > 0 {
 				fmt.Printf("🔌 Port: %d\n", a.Port)
 			}
+
+			// Show request limits (body size, timeout, rate limit)
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(appDir)
+			if err == nil && len(metadata.Limits) > 0 {
+				fmt.Println("🚦 Limits:")
+				for _, rule := range metadata.Limits {
+					fmt.Printf("   %s\n", formatLimitRule(rule))
+				}
+			}
+
+			// Static sites have no services
+			if status.Static {
+				fmt.Println("📄 Type: static site (served by Caddy)")
+				if status.Release != "" {
+					fmt.Printf("🏷️  Release: %s\n", status.Release)
+				} else {
+					fmt.Println("🏷️  Release: none (push to publish)")
+				}
+				return
+			}
 			fmt.Println()
 
 			if len(a.Services) == 0 {
@@ -99,76 +206,87 @@ func NewAppsStatusCmd() *cobra.Command {
 			fmt.Println("Services:")
 			fmt.Println(strings.Repeat("─", 80))
 
+			runningCount := 0
 			for i, svc := range a.Services {
 				if i > 0 {
 					fmt.Println()
 				}
+				s := status.Services[i]
 
-				container, exists := containers[svc.Name]
 				statusIcon := "○"
-				statusText := "Not running"
-				state := ""
-
-				if exists {
-					state = container.State
-					switch state {
-					case "running":
-						statusIcon = "✓"
-						statusText = "Running"
-					case "exited":
+				switch {
+				case s.Running >= s.Replicas:
+					statusIcon = "✓"
+					runningCount++
+				case s.Running > 0:
+					statusIcon = "◐"
+				}
+				for _, c := range s.Containers {
+					if c.Health == "unhealthy" || c.State == "restarting" {
 						statusIcon = "✗"
-						statusText = "Stopped"
-					case "restarting":
-						statusIcon = "↻"
-						statusText = "Restarting"
-					default:
-						// Capitalize first letter
-						stateLower := strings.ToLower(state)
-						if len(stateLower) > 0 {
-							stateCapitalized := strings.ToUpper(stateLower[:1]) + stateLower[1:]
-							statusText = stateCapitalized
-						} else {
-							statusText = state
-						}
 					}
 				}
 
-				fmt.Printf("  %s %s\n", statusIcon, svc.Name)
+				fmt.Printf("  %s %s (%d/%d running)\n", statusIcon, svc.Name, s.Running, s.Replicas)
 				fmt.Printf("    Image:     %s\n", svc.Image)
 
 				if svc.Port > 0 {
 					fmt.Printf("    Port:      %d\n", svc.Port)
 				}
 
-				fmt.Printf("    Status:    %s", statusText)
-				if exists && container.Status != "" && state != "running" {
-					fmt.Printf(" (%s)", container.Status)
+				// Show extra ports if any
+				if len(svc.ExtraPorts) > 0 {
+					fmt.Printf("    Ports:     %s\n", strings.Join(svc.ExtraPorts, ", "))
 				}
-				fmt.Println()
 
-				if container.Name != "" {
-					fmt.Printf("    Container: %s\n", container.Name)
+				if s.Resources != nil {
+					fmt.Printf("    Resources: %s\n", s.Resources.String())
 				}
 
-				// Show extra ports if any
-				if len(svc.ExtraPorts) > 0 {
-					fmt.Printf("    Ports:     %s\n", strings.Join(svc.ExtraPorts, ", "))
+				if len(s.Containers) == 0 {
+					fmt.Println("    Status:    Not running")
+					continue
+				}
+				for _, c := range s.Containers {
+					icon, text := containerStateText(c)
+					fmt.Printf("    %s #%d %s: %s\n", icon, c.Replica, c.Name, text)
+					var details []string
+					if c.Running() && c.StartedAt != "" {
+						if started, err := time.Parse(time.RFC3339Nano, c.StartedAt); err == nil {
+							details = append(details, "up "+time.Since(started).Round(time.Second).String())
+						}
+					}
+					details = append(details, fmt.Sprintf("restarts %d", c.RestartCount))
+					if c.Running() {
+						if s.Resources != nil && s.Resources.CPUs != "" {
+							details = append(details, fmt.Sprintf("CPU %.1f%% / %s CPUs", c.CPUPercent, s.Resources.CPUs))
+						} else {
+							details = append(details, fmt.Sprintf("CPU %.1f%%", c.CPUPercent))
+						}
+						if c.MemoryLimit > 0 {
+							details = append(details, fmt.Sprintf("mem %s / %s", util.FormatBytes(c.MemoryUsage), util.FormatBytes(c.MemoryLimit)))
+						}
+					}
+					fmt.Printf("        %s\n", strings.Join(details, ", "))
+					if c.ImageDigest != "" {
+						fmt.Printf("        Digest: %s\n", c.ImageDigest)
+					} else if c.ImageID != "" {
+						fmt.Printf("        Image ID: %s\n", c.ImageID)
+					}
 				}
 			}
 
 			fmt.Println(strings.Repeat("─", 80))
 
 			// Summary
-			runningCount := 0
-			for _, svc := range a.Services {
-				if container, exists := containers[svc.Name]; exists && container.State == "running" {
-					runningCount++
-				}
-			}
-
 			fmt.Printf("\nSummary: %d/%d services running\n", runningCount, len(a.Services))
+			if metadata != nil && len(metadata.Resources) > 0 {
+				warnMemoryOvercommit(cfg)
+			}
 		},
 	}
 
+	cmd.Flags().Bool("json", false, "Print the status as JSON")
+
 	return cmd
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewAppsUpCmd levanta los servicios (docker compose up -d) de una app
@@ -89,11 +90,14 @@ func NewAppsUpCmd() *cobra.Command {
 			}
 
 			// Deploy
+			event := journal.Event{App: appName, Action: "up"}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error starting services: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
 
+			recordChange(event, false, nil)
 			fmt.Printf("Services for %s are up!\n", appName)
 		},
 	}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewCheckUpdateCmd creates a command to check for updates without downloading
@@ -106,6 +108,9 @@ func enableAutoUpdate() {
 		return
 	}
 
+	journal.Record(journal.Event{
+		Action: "auto-update", After: "enabled",
+	})
 	fmt.Println("✅ Auto-update enabled")
 	fmt.Println("Portico will check for updates every time you run a command")
 }
@@ -120,6 +125,9 @@ func disableAutoUpdate() {
 		return
 	}
 
+	journal.Record(journal.Event{
+		Action: "auto-update", After: "disabled",
+	})
 	fmt.Println("✅ Auto-update disabled")
 }
 
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// configCommands lists the subcommands of config
+var configCommands = map[string]bool{
+	"stage":   true,
+	"pending": true,
+	"commit":  true,
+}
+
+// NewConfigCmd is the root command for staged config changes: config [app-name] ...
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config [app-name]",
+		Short: "Stage config changes and deploy them at once",
+		Long: `Stage config changes (env, secrets, storage, ports) and deploy them at once.
+
+While an app is staging, config commands update docker-compose.yml but don't
+redeploy. The same happens for a single command with --no-restart, which also
+starts staging. "config commit" deploys all staged changes with one redeploy.
+Any other deploy of the app (git push, up, service image, rollback) deploys
+docker-compose.yml with the staged changes too, and ends staging.
+
+Examples:
+  portico config my-app stage
+  portico env my-app add API_URL https://api.example.com
+  portico secrets my-app add api_key sk-abc123
+  portico config my-app pending
+  portico config my-app commit`,
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true, // Flags belong to the subcommands
+		Run: func(parentCmd *cobra.Command, args []string) {
+			// Parse os.Args directly to find subcommand
+			allArgs := os.Args[1:] // Skip program name
+
+			var subcommandName string
+			var subcommandIndex int
+
+			// Find "config" in arguments
+			configIndex := -1
+			for i, arg := range allArgs {
+				if arg == "config" {
+					configIndex = i
+					break
+				}
+			}
+
+			if configIndex == -1 {
+				_ = parentCmd.Help()
+				return
+			}
+
+			// Find subcommand after "config" and app-name
+			for i := configIndex + 1; i < len(allArgs); i++ {
+				if configCommands[allArgs[i]] {
+					subcommandName = allArgs[i]
+					subcommandIndex = i
+					break
+				}
+			}
+
+			// If no subcommand found, show help
+			if subcommandName == "" {
+				_ = parentCmd.Help()
+				return
+			}
+
+			// Find and execute subcommand
+			for _, subCmd := range parentCmd.Commands() {
+				if subCmd.Name() == subcommandName {
+					// Get arguments for subcommand (everything after subcommand name)
+					subcommandArgs := allArgs[subcommandIndex+1:]
+
+					// Parse flags manually for the subcommand
+					if err := subCmd.ParseFlags(subcommandArgs); err != nil {
+						fmt.Printf("Error parsing flags: %v\n", err)
+						_ = subCmd.Help()
+						return
+					}
+
+					// Get non-flag arguments
+					nonFlagArgs := subCmd.Flags().Args()
+
+					// Call the subcommand's Run function directly
+					if subCmd.Run != nil {
+						subCmd.Run(subCmd, nonFlagArgs)
+					} else {
+						_ = subCmd.Help()
+					}
+					return
+				}
+			}
+
+			// Subcommand not found
+			_ = parentCmd.Help()
+		},
+	}
+	return cmd
+}
+
+// getAppNameFromConfigArgs extracts app-name from config command arguments
+// It parses os.Args to find the app-name after "config"
+func getAppNameFromConfigArgs(cmd *cobra.Command) (string, error) {
+	// Parse os.Args to find app-name after "config"
+	args := os.Args[1:] // Skip program name
+	for i, arg := range args {
+		if arg == "config" {
+			// App-name comes before the subcommand
+			for j := i + 1; j < len(args); j++ {
+				if configCommands[args[j]] {
+					break
+				}
+				// Skip if it's a flag
+				if args[j][0] == '-' {
+					continue
+				}
+				// This should be the app-name
+				return args[j], nil
+			}
+			break
+		}
+	}
+	return "", nil
+}
+
+// stageConfigChange records a config change instead of deploying it when
+// --no-restart was given or the app is staging changes. docker-compose.yml
+// must already be updated. Returns true if the change was staged
+func stageConfigChange(cmd *cobra.Command, cfg *config.Config, appName, serviceName, change string, restart bool) bool {
+	appDir := filepath.Join(cfg.AppsDir, appName)
+	dm := docker.NewManager(cfg.Registry.URL)
+
+	noRestart, _ := cmd.Flags().GetBool("no-restart")
+	if !noRestart {
+		metadata, err := dm.GetPorticoMetadata(appDir)
+		if err != nil || metadata.Pending == nil {
+			return false
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	var count int
+	err := dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+		if metadata.Pending == nil {
+			metadata.Pending = &docker.PendingConfig{Since: now}
+		}
+		metadata.Pending.Changes = append(metadata.Pending.Changes, docker.PendingChange{
+			Time:    now,
+			Change:  change,
+			Service: serviceName,
+			Restart: restart,
+		})
+		count = len(metadata.Pending.Changes)
+	})
+	if err != nil {
+		// Deploy right away rather than losing track of the change
+		fmt.Printf("Warning: could not stage change: %v\n", err)
+		return false
+	}
+
+	fmt.Printf("Staged: %s (not deployed)\n", change)
+	fmt.Printf("%d pending change(s). Run 'portico config %s commit' to deploy them\n", count, appName)
+	return true
+}
+
+// recordChange records a config change in the journal once it is staged or
+// deployed, with the error if the deploy failed
+func recordChange(event journal.Event, staged bool, err error) {
+	if staged {
+		event.Summary += " (staged)"
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	journal.Record(event)
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewConfigCommitCmd deploys the staged config changes of an app
+func NewConfigCommitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "commit",
+		Short: "Deploy staged config changes",
+		Long:  "Deploy all staged config changes with a single redeploy and stop staging. Services with changed secrets are restarted once.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (config)
+			appName, err := getAppNameFromConfigArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico config [app-name] commit")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(appDir)
+			if err != nil {
+				fmt.Printf("Error loading app metadata: %v\n", err)
+				return
+			}
+			if metadata.Pending == nil {
+				fmt.Printf("App %s is not staging changes\n", appName)
+				return
+			}
+			pending := metadata.Pending
+			var changes []string
+			for _, change := range pending.Changes {
+				changes = append(changes, change.Change)
+			}
+			event := journal.Event{App: appName, Action: "config commit", Summary: strings.Join(changes, "; ")}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			if len(pending.Changes) > 0 {
+				var dockerServices []docker.Service
+				for _, s := range a.Services {
+					replicas := s.Replicas
+					if replicas == 0 {
+						replicas = 1 // Default to 1 if not specified
+					}
+					dockerServices = append(dockerServices, docker.Service{
+						Name:        s.Name,
+						Image:       s.Image,
+						Port:        s.Port,
+						ExtraPorts:  s.ExtraPorts,
+						Environment: s.Environment,
+						Volumes:     s.Volumes,
+						Secrets:     s.Secrets,
+						DependsOn:   s.DependsOn,
+						Replicas:    replicas,
+					})
+				}
+
+				// docker compose up recreates the containers whose config changed;
+				// DeployApp then restarts the services with changed secrets once
+				// and clears the staged changes
+				if err := dm.DeployApp(appDir, dockerServices); err != nil {
+					fmt.Printf("Error deploying app: %v\n", err)
+					fmt.Println("Changes are still staged")
+					recordChange(event, false, err)
+					return
+				}
+			}
+
+			if err := dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+				metadata.Pending = nil
+			}); err != nil {
+				fmt.Printf("Error updating app metadata: %v\n", err)
+				return
+			}
+
+			recordChange(event, false, nil)
+
+			fmt.Printf("Deployed %d staged change(s) for %s\n", len(pending.Changes), appName)
+		},
+	}
+}
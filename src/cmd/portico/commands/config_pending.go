@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+)
+
+// NewConfigPendingCmd lists the staged config changes of an app
+func NewConfigPendingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List staged config changes",
+		Long:  "List the config changes written to docker-compose.yml but not deployed yet.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (config)
+			appName, err := getAppNameFromConfigArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico config [app-name] pending")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error loading app metadata: %v\n", err)
+				return
+			}
+
+			if metadata.Pending == nil {
+				fmt.Printf("App %s is not staging changes\n", appName)
+				return
+			}
+
+			fmt.Printf("Staging config changes for %s since %s:\n", appName, metadata.Pending.Since)
+			if len(metadata.Pending.Changes) == 0 {
+				fmt.Println("  (none)")
+				return
+			}
+			for _, change := range metadata.Pending.Changes {
+				line := fmt.Sprintf("  %s  %s", change.Time, change.Change)
+				if change.Service != "" {
+					line += fmt.Sprintf(" (service %s", change.Service)
+					if change.Restart {
+						line += ", restart"
+					}
+					line += ")"
+				}
+				fmt.Println(line)
+			}
+			fmt.Printf("\nRun 'portico config %s commit' to deploy them\n", appName)
+		},
+	}
+}
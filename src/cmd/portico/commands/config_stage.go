@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewConfigStageCmd starts staging config changes for an app
+func NewConfigStageCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stage",
+		Short: "Start staging config changes",
+		Long:  "Start staging config changes: env, secrets, storage and ports commands update docker-compose.yml without redeploying until 'config commit'.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (config)
+			appName, err := getAppNameFromConfigArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico config [app-name] stage")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			dm := docker.NewManager(cfg.Registry.URL)
+			alreadyStaging := false
+			err = dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+				if metadata.Pending != nil {
+					alreadyStaging = true
+					return
+				}
+				metadata.Pending = &docker.PendingConfig{Since: time.Now().UTC().Format(time.RFC3339)}
+			})
+			if err != nil {
+				fmt.Printf("Error updating app metadata: %v\n", err)
+				return
+			}
+
+			if alreadyStaging {
+				fmt.Printf("App %s is already staging changes. Run 'portico config %s pending' to list them\n", appName, appName)
+				return
+			}
+			journal.Record(journal.Event{
+				App: appName, Action: "config stage",
+			})
+			fmt.Printf("Staging config changes for %s. Run 'portico config %s commit' to deploy them\n", appName, appName)
+		},
+	}
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/daemon"
+)
+
+// NewDaemonCmd creates the daemon command
+func NewDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run the Portico daemon (container events, restarts, jobs)",
+		Long: `Run the Portico daemon in the foreground, usually as a systemd service.
+
+The daemon follows docker events for the containers of apps and addon instances.
+When a container dies on its own it notifies container_died and restarts it
+according to daemon.restart_policy (on-failure, always, no), with a backoff;
+after daemon.max_restarts restarts within daemon.restart_window it gives up and
+notifies crash_loop. Containers that become unhealthy notify container_unhealthy.
+Restarts are recorded in the event journal. Containers of an app whose deploy
+is being watched are not restarted: the watch rolls the deploy back.
+
+The daemon also runs the scheduled jobs of daemon.jobs (by default, secrets
+expire every 15 minutes and addons backup --all every day) and serves its state on a Unix socket, read by
+'portico daemon status'.
+
+Examples:
+  portico daemon
+  portico daemon status`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			d, err := daemon.New(cfg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := d.Run(ctx); err != nil {
+				fmt.Printf("Error running daemon: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.AddCommand(NewDaemonStatusCmd())
+
+	return cmd
+}
+
+// NewDaemonStatusCmd shows the state of the running daemon
+func NewDaemonStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of the running daemon",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			state, err := daemon.Status(cfg.Daemon.Socket)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(state, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding JSON: %v\n", err)
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			events := "connected"
+			if !state.EventsConnected {
+				events = "disconnected (reconnecting)"
+			}
+			fmt.Printf("Running since:  %s (%s)\n", state.Started.Local().Format("2006-01-02 15:04:05"), time.Since(state.Started).Round(time.Second))
+			fmt.Printf("Restart policy: %s\n", state.RestartPolicy)
+			fmt.Printf("Docker events:  %s\n", events)
+			if !state.LastEvent.IsZero() {
+				fmt.Printf("Last event:     %s\n", state.LastEvent.Local().Format("2006-01-02 15:04:05"))
+			}
+
+			fmt.Println()
+			if len(state.Apps) == 0 {
+				fmt.Println("No apps or addon instances.")
+			} else {
+				fmt.Printf("%-20s %-6s %-32s %-12s %-10s %-5s %s\n", "APP", "KIND", "CONTAINER", "STATE", "HEALTH", "EXIT", "RESTARTS")
+				for _, a := range state.Apps {
+					if len(a.Containers) == 0 {
+						fmt.Printf("%-20s %-6s %-32s %-12s %-10s %-5s %s\n", a.Name, a.Kind, "-", "-", "-", "-", "-")
+						continue
+					}
+					for _, c := range a.Containers {
+						restarts := fmt.Sprintf("%d", c.Restarts)
+						if c.GaveUp {
+							restarts += " (gave up)"
+						}
+						exitCode := "-"
+						if c.State == "exited" || c.State == "restarting" {
+							exitCode = fmt.Sprintf("%d", c.ExitCode)
+						}
+						fmt.Printf("%-20s %-6s %-32s %-12s %-10s %-5s %s\n", a.Name, a.Kind, c.Name, c.State, orDash(c.Health), exitCode, restarts)
+					}
+				}
+			}
+
+			fmt.Println()
+			fmt.Printf("%-20s %-8s %-20s %-20s %-20s %s\n", "JOB", "EVERY", "COMMAND", "LAST RUN", "NEXT RUN", "RESULT")
+			for _, job := range state.Jobs {
+				lastRun, result := "-", "-"
+				if !job.LastRun.IsZero() {
+					lastRun = job.LastRun.Local().Format("2006-01-02 15:04:05")
+					result = fmt.Sprintf("ok (%.1fs)", job.LastDuration)
+					if job.LastError != "" {
+						result = "failed: " + job.LastError
+					}
+				}
+				fmt.Printf("%-20s %-8s %-20s %-20s %-20s %s\n", job.Name, job.Every, job.Command, lastRun, job.NextRun.Local().Format("2006-01-02 15:04:05"), result)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Print the state as JSON")
+
+	return cmd
+}
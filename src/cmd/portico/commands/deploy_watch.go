@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// currentRelease returns the release an app runs, to roll back to if the next
+// deploy fails the deploy watch (nil if the app has none)
+func currentRelease(cfg *config.Config, appDir string) *docker.Release {
+	dm := docker.NewManager(cfg.Registry.URL)
+	release, err := dm.CurrentRelease(appDir)
+	if err != nil {
+		fmt.Printf("Warning: could not read the current release, automatic rollback is disabled: %v\n", err)
+		return nil
+	}
+	return release
+}
+
+// deployWithWatch deploys an app and watches it for the deploy_watch window of
+// config.yml. If the new release crash-loops or fails its health checks, the
+// app is rolled back to previous and an error is returned. The deploy is
+// recorded in the journal
+func deployWithWatch(cfg *config.Config, appDir string, services []docker.Service, previous *docker.Release, watch bool) error {
+	err := deployAndWatch(cfg, appDir, services, previous, watch)
+
+	event := journal.Event{App: filepath.Base(appDir), Action: "deploy"}
+	if previous != nil {
+		var running []string
+		for image := range previous.Images {
+			running = append(running, image)
+		}
+		sort.Strings(running)
+		event.Before = strings.Join(running, ", ")
+	}
+	var images []string
+	for _, svc := range services {
+		images = append(images, svc.Name+"="+svc.Image)
+	}
+	event.After = strings.Join(images, ", ")
+	if err != nil {
+		event.Error = err.Error()
+	}
+	journal.Record(event)
+
+	return err
+}
+
+// deployAndWatch deploys an app, watches it and rolls it back if it fails
+func deployAndWatch(cfg *config.Config, appDir string, services []docker.Service, previous *docker.Release, watch bool) error {
+	dm := docker.NewManager(cfg.Registry.URL)
+	if err := dm.DeployApp(appDir, services); err != nil {
+		return err
+	}
+
+	window, err := time.ParseDuration(cfg.DeployWatch)
+	if cfg.DeployWatch == "0" || cfg.DeployWatch == "off" {
+		window, err = 0, nil
+	}
+	if err != nil {
+		fmt.Printf("Warning: invalid deploy_watch %q in config.yml, the deploy is not watched\n", cfg.DeployWatch)
+		return nil
+	}
+	if !watch || window <= 0 {
+		return nil
+	}
+
+	fmt.Printf("Watching %s for %s...\n", filepath.Base(appDir), window)
+	err = dm.WatchDeploy(appDir, docker.DeployWatch{Window: window, MaxRestarts: cfg.WatchRestarts})
+	var failure *docker.WatchFailure
+	if err != nil && !errors.As(err, &failure) {
+		fmt.Printf("Warning: could not watch the deploy: %v\n", err)
+		return nil
+	}
+	if failure == nil {
+		fmt.Println("✅ All containers are running")
+		return nil
+	}
+
+	if previous == nil {
+		return fmt.Errorf("the new release failed: %v (no previous release to roll back to)", failure)
+	}
+	fmt.Printf("❌ The new release failed: %v. Rolling back...\n", failure)
+	if err := dm.RollbackApp(appDir, previous, failure.Error()); err != nil {
+		return fmt.Errorf("the new release failed (%v) and the rollback failed: %w", failure, err)
+	}
+	return fmt.Errorf("the new release failed (%v), rolled back to the previous release", failure)
+}
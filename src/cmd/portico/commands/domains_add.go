@@ -7,6 +7,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -40,6 +41,7 @@ func NewDomainsAddCmd() *cobra.Command {
 				return
 			}
 
+			previousDomain := a.Domain
 			a.Domain = domain
 			if err := am.SaveApp(a); err != nil {
 				fmt.Printf("Error saving app: %v\n", err)
@@ -57,6 +59,9 @@ func NewDomainsAddCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "domains add", Summary: domain, Before: previousDomain, After: domain,
+			})
 			fmt.Printf("Domain %s added to %s\n", domain, appName)
 		},
 	}
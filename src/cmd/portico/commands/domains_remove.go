@@ -7,6 +7,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -63,6 +64,9 @@ func NewDomainsRemoveCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "domains remove", Summary: domain, Before: domain, After: a.Domain,
+			})
 			fmt.Printf("Domain %s removed from %s\n", domain, appName)
 		},
 	}
@@ -3,27 +3,40 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
+// envCommands lists the subcommands of env
+var envCommands = map[string]bool{
+	"add":    true,
+	"del":    true,
+	"delete": true,
+	"edit":   true,
+	"list":   true,
+	"import": true,
+	"export": true,
+}
+
 // NewEnvCmd is the root command for environment variables: env [app-name] ...
 func NewEnvCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "env [app-name] [service-name]",
-		Short: "Manage environment variables",
-		Long:  "Manage environment variables for application services.",
-		Args:  cobra.ArbitraryArgs,
+		Use:                "env [app-name] [service-name]",
+		Short:              "Manage environment variables",
+		Long:               "Manage environment variables for application services.\n\nWith --app-level, add/edit/del/list manage variables shared by all services of the app.\nService variables override app-level variables with the same name.",
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true, // Flags belong to the subcommands
 		Run: func(parentCmd *cobra.Command, args []string) {
 			// Parse os.Args directly to find subcommand
 			allArgs := os.Args[1:] // Skip program name
-			knownCommands := map[string]bool{
-				"add":    true,
-				"del":    true,
-				"delete": true,
-				"edit":   true,
-				"list":   true,
-			}
 
 			var subcommandName string
 			var subcommandIndex int
@@ -44,7 +57,7 @@ func NewEnvCmd() *cobra.Command {
 
 			// Find subcommand after "env"
 			for i := envIndex + 1; i < len(allArgs); i++ {
-				if knownCommands[allArgs[i]] {
+				if envCommands[allArgs[i]] {
 					subcommandName = allArgs[i]
 					subcommandIndex = i
 					break
@@ -104,14 +117,14 @@ func getAppNameFromEnvArgs(cmd *cobra.Command) (string, error) {
 		if arg == "env" {
 			// Next non-flag argument should be app-name
 			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if envCommands[args[j]] {
+					break
+				}
 				// Skip if it's a flag
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
-				if args[j] == "add" || args[j] == "del" || args[j] == "delete" || args[j] == "edit" || args[j] == "list" {
-					continue
-				}
 				// This should be the app-name
 				return args[j], nil
 			}
@@ -131,14 +144,14 @@ func getServiceNameFromEnvArgs(cmd *cobra.Command) (string, error) {
 			// Find app-name first
 			appNameFound := false
 			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if envCommands[args[j]] {
+					break
+				}
 				// Skip if it's a flag
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
-				if args[j] == "add" || args[j] == "del" || args[j] == "delete" || args[j] == "edit" || args[j] == "list" {
-					continue
-				}
 				if !appNameFound {
 					appNameFound = true
 					continue
@@ -151,3 +164,98 @@ func getServiceNameFromEnvArgs(cmd *cobra.Command) (string, error) {
 	}
 	return "", nil
 }
+
+// isAppLevelEnv reports whether --app-level was given to the env command
+// (before or after the subcommand)
+func isAppLevelEnv() bool {
+	args := os.Args[1:] // Skip program name
+	for i, arg := range args {
+		if arg == "env" {
+			for _, a := range args[i+1:] {
+				if a == "--app-level" {
+					return true
+				}
+			}
+			break
+		}
+	}
+	return false
+}
+
+// saveAppLevelEnv saves the app-level environment of an app and redeploys all its
+// services, unless the change is staged. Returns true if the change was staged
+func saveAppLevelEnv(cmd *cobra.Command, cfg *config.Config, am *app.Manager, a *app.App, change string, event journal.Event) (bool, error) {
+	if err := am.SaveApp(a); err != nil {
+		return false, fmt.Errorf("error saving app: %w", err)
+	}
+
+	dm := docker.NewManager(cfg.Registry.URL)
+	appDir := filepath.Join(cfg.AppsDir, a.Name)
+
+	var dockerServices []docker.Service
+	for _, s := range a.Services {
+		replicas := s.Replicas
+		if replicas == 0 {
+			replicas = 1 // Default to 1 if not specified
+		}
+		dockerServices = append(dockerServices, docker.Service{
+			Name:        s.Name,
+			Image:       s.Image,
+			Port:        s.Port,
+			ExtraPorts:  s.ExtraPorts,
+			Environment: s.Environment,
+			Volumes:     s.Volumes,
+			Secrets:     s.Secrets,
+			DependsOn:   s.DependsOn,
+			Replicas:    replicas,
+		})
+	}
+
+	// Stage instead of redeploying (--no-restart or "config stage")
+	if stageConfigChange(cmd, cfg, a.Name, "", change, false) {
+		recordChange(event, true, nil)
+		return true, nil
+	}
+	// docker compose up recreates the containers whose environment changed
+	if err := dm.DeployApp(appDir, dockerServices); err != nil {
+		recordChange(event, false, err)
+		return false, fmt.Errorf("error deploying app: %w", err)
+	}
+	recordChange(event, false, nil)
+
+	return false, nil
+}
+
+// maskedEnv returns KEY=value for the journal, with the value masked if it is sensitive
+func maskedEnv(cfg *config.Config, appName, key, value string) string {
+	return key + "=" + util.MaskEnvValue(key, value, sensitiveEnvKeys(cfg, appName))
+}
+
+// sensitiveEnvKeys returns the variables of an app flagged with --sensitive
+func sensitiveEnvKeys(cfg *config.Config, appName string) []string {
+	dm := docker.NewManager(cfg.Registry.URL)
+	metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+	if err != nil {
+		return nil
+	}
+	return metadata.Sensitive
+}
+
+// markSensitiveEnv flags a variable as sensitive when --sensitive was given, so
+// listings mask it even if its name doesn't look like a credential
+func markSensitiveEnv(cmd *cobra.Command, cfg *config.Config, appName, key string) error {
+	sensitive, _ := cmd.Flags().GetBool("sensitive")
+	if !sensitive {
+		return nil
+	}
+	dm := docker.NewManager(cfg.Registry.URL)
+	return dm.UpdatePorticoMetadata(filepath.Join(cfg.AppsDir, appName), func(metadata *docker.PorticoMetadata) {
+		for _, k := range metadata.Sensitive {
+			if k == key {
+				return
+			}
+		}
+		metadata.Sensitive = append(metadata.Sensitive, key)
+		sort.Strings(metadata.Sensitive)
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
 // NewEnvAddCmd adds an environment variable for a service in an app
@@ -17,7 +19,7 @@ func NewEnvAddCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add [key] [value]",
 		Short: "Add an environment variable",
-		Long:  "Add an environment variable for a service in the given app.\n\nExamples:\n  portico env my-app add NODE_ENV production\n    Adds NODE_ENV=production (uses default service if only one exists)\n\n  portico env my-app api add DATABASE_URL postgres://...\n    Adds DATABASE_URL for service 'api'",
+		Long:  "Add an environment variable for a service in the given app.\n\nExamples:\n  portico env my-app add NODE_ENV production\n    Adds NODE_ENV=production (uses default service if only one exists)\n\n  portico env my-app api add DATABASE_URL postgres://...\n    Adds DATABASE_URL for service 'api'\n\n  portico env my-app --app-level add APP_ENV production\n    Adds APP_ENV for all services (service variables with the same name override it)",
 		Args:  cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (env)
@@ -52,6 +54,32 @@ func NewEnvAddCmd() *cobra.Command {
 				return
 			}
 
+			if err := markSensitiveEnv(cmd, cfg, appName, key); err != nil {
+				fmt.Printf("Error flagging %s as sensitive: %v\n", key, err)
+				return
+			}
+
+			// App-level variable shared by all services
+			if isAppLevelEnv() {
+				if _, exists := a.Environment[key]; exists {
+					fmt.Printf("App-level environment variable %s already exists in %s. Use 'edit' to update it.\n", key, appName)
+					return
+				}
+				a.Environment[key] = value
+				staged, err := saveAppLevelEnv(cmd, cfg, am, a, fmt.Sprintf("env add %s --app-level", key), journal.Event{
+					App: appName, Action: "env add", Summary: key + " (app-level)", After: maskedEnv(cfg, appName, key, value),
+				})
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				if staged {
+					return
+				}
+				fmt.Printf("Added app-level environment variable %s=%s in %s\n", key, util.MaskEnvValue(key, value, sensitiveEnvKeys(cfg, appName)), appName)
+				return
+			}
+
 			// Auto-detect service if not specified
 			if serviceName == "" {
 				if len(a.Services) == 1 {
@@ -129,19 +157,35 @@ func NewEnvAddCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "env add", Summary: fmt.Sprintf("%s for service %s", key, serviceName), After: maskedEnv(cfg, appName, key, value),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("env add %s", key), false) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Restart the service to apply new environment variable
 			if err := dm.RestartService(appDir, serviceName); err != nil {
 				fmt.Printf("Warning: could not restart service: %v\n", err)
 			}
 
-			fmt.Printf("Added environment variable %s=%s for service %s in %s\n", key, value, serviceName, appName)
+			fmt.Printf("Added environment variable %s=%s for service %s in %s\n", key, util.MaskEnvValue(key, value, sensitiveEnvKeys(cfg, appName)), serviceName, appName)
 		},
 	}
 
+	cmd.Flags().Bool("sensitive", false, "Mask the value in listings even if the name doesn't look like a credential")
+
+	cmd.Flags().Bool("app-level", false, "Manage a variable shared by all services of the app")
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewEnvDeleteCmd deletes an environment variable for a service in an app
@@ -18,7 +19,7 @@ func NewEnvDeleteCmd() *cobra.Command {
 		Use:     "del [key]",
 		Aliases: []string{"delete"},
 		Short:   "Delete an environment variable",
-		Long:    "Delete an environment variable for a service in the given app.\n\nExamples:\n  portico env my-app del NODE_ENV\n    Deletes NODE_ENV (uses default service if only one exists)\n\n  portico env my-app api del DATABASE_URL\n    Deletes DATABASE_URL for service 'api'",
+		Long:    "Delete an environment variable for a service in the given app.\n\nExamples:\n  portico env my-app del NODE_ENV\n    Deletes NODE_ENV (uses default service if only one exists)\n\n  portico env my-app api del DATABASE_URL\n    Deletes DATABASE_URL for service 'api'\n\n  portico env my-app --app-level del APP_ENV\n    Deletes APP_ENV from all services (service variables are kept)",
 		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (env)
@@ -52,6 +53,28 @@ func NewEnvDeleteCmd() *cobra.Command {
 				return
 			}
 
+			// App-level variable shared by all services
+			if isAppLevelEnv() {
+				if _, exists := a.Environment[key]; !exists {
+					fmt.Printf("App-level environment variable %s not found in %s\n", key, appName)
+					return
+				}
+				before := maskedEnv(cfg, appName, key, a.Environment[key])
+				delete(a.Environment, key)
+				staged, err := saveAppLevelEnv(cmd, cfg, am, a, fmt.Sprintf("env del %s --app-level", key), journal.Event{
+					App: appName, Action: "env del", Summary: key + " (app-level)", Before: before,
+				})
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				if staged {
+					return
+				}
+				fmt.Printf("Deleted app-level environment variable %s from %s\n", key, appName)
+				return
+			}
+
 			// Auto-detect service if not specified
 			if serviceName == "" {
 				if len(a.Services) == 1 {
@@ -71,6 +94,7 @@ func NewEnvDeleteCmd() *cobra.Command {
 			// Find service and delete environment variable
 			found := false
 			deleted := false
+			before := ""
 			for i := range a.Services {
 				if a.Services[i].Name == serviceName {
 					found = true
@@ -78,7 +102,8 @@ func NewEnvDeleteCmd() *cobra.Command {
 						fmt.Printf("Environment variable %s not found for service %s in %s\n", key, serviceName, appName)
 						return
 					}
-					if _, exists := a.Services[i].Environment[key]; exists {
+					if current, exists := a.Services[i].Environment[key]; exists {
+						before = maskedEnv(cfg, appName, key, current)
 						delete(a.Services[i].Environment, key)
 						deleted = true
 					}
@@ -131,10 +156,20 @@ func NewEnvDeleteCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "env del", Summary: fmt.Sprintf("%s from service %s", key, serviceName), Before: before,
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("env del %s", key), false) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Restart the service to apply removed environment variable
 			if err := dm.RestartService(appDir, serviceName); err != nil {
@@ -145,5 +180,9 @@ func NewEnvDeleteCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Bool("app-level", false, "Manage a variable shared by all services of the app")
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
 // NewEnvEditCmd edits an environment variable for a service in an app
@@ -17,7 +19,7 @@ func NewEnvEditCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "edit [key] [value]",
 		Short: "Edit an environment variable",
-		Long:  "Edit an environment variable for a service in the given app.\n\nExamples:\n  portico env my-app edit NODE_ENV production\n    Updates NODE_ENV=production (uses default service if only one exists)\n\n  portico env my-app api edit DATABASE_URL postgres://...\n    Updates DATABASE_URL for service 'api'",
+		Long:  "Edit an environment variable for a service in the given app.\n\nExamples:\n  portico env my-app edit NODE_ENV production\n    Updates NODE_ENV=production (uses default service if only one exists)\n\n  portico env my-app api edit DATABASE_URL postgres://...\n    Updates DATABASE_URL for service 'api'\n\n  portico env my-app --app-level edit APP_ENV staging\n    Updates APP_ENV for all services",
 		Args:  cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (env)
@@ -52,6 +54,33 @@ func NewEnvEditCmd() *cobra.Command {
 				return
 			}
 
+			if err := markSensitiveEnv(cmd, cfg, appName, key); err != nil {
+				fmt.Printf("Error flagging %s as sensitive: %v\n", key, err)
+				return
+			}
+
+			// App-level variable shared by all services
+			if isAppLevelEnv() {
+				if _, exists := a.Environment[key]; !exists {
+					fmt.Printf("App-level environment variable %s not found in %s. Use 'add' to create it.\n", key, appName)
+					return
+				}
+				before := maskedEnv(cfg, appName, key, a.Environment[key])
+				a.Environment[key] = value
+				staged, err := saveAppLevelEnv(cmd, cfg, am, a, fmt.Sprintf("env edit %s --app-level", key), journal.Event{
+					App: appName, Action: "env edit", Summary: key + " (app-level)", Before: before, After: maskedEnv(cfg, appName, key, value),
+				})
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				if staged {
+					return
+				}
+				fmt.Printf("Updated app-level environment variable %s=%s in %s\n", key, util.MaskEnvValue(key, value, sensitiveEnvKeys(cfg, appName)), appName)
+				return
+			}
+
 			// Auto-detect service if not specified
 			if serviceName == "" {
 				if len(a.Services) == 1 {
@@ -70,6 +99,7 @@ func NewEnvEditCmd() *cobra.Command {
 
 			// Find service and edit environment variable
 			found := false
+			before := ""
 			for i := range a.Services {
 				if a.Services[i].Name == serviceName {
 					found = true
@@ -77,6 +107,9 @@ func NewEnvEditCmd() *cobra.Command {
 					if a.Services[i].Environment == nil {
 						a.Services[i].Environment = make(map[string]string)
 					}
+					if current, exists := a.Services[i].Environment[key]; exists {
+						before = maskedEnv(cfg, appName, key, current)
+					}
 					// Update the environment variable (edit can also add if it doesn't exist)
 					a.Services[i].Environment[key] = value
 					break
@@ -124,19 +157,36 @@ func NewEnvEditCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "env edit", Summary: fmt.Sprintf("%s for service %s", key, serviceName),
+				Before: before, After: maskedEnv(cfg, appName, key, value),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("env edit %s", key), false) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Restart the service to apply updated environment variable
 			if err := dm.RestartService(appDir, serviceName); err != nil {
 				fmt.Printf("Warning: could not restart service: %v\n", err)
 			}
 
-			fmt.Printf("Updated environment variable %s=%s for service %s in %s\n", key, value, serviceName, appName)
+			fmt.Printf("Updated environment variable %s=%s for service %s in %s\n", key, util.MaskEnvValue(key, value, sensitiveEnvKeys(cfg, appName)), serviceName, appName)
 		},
 	}
 
+	cmd.Flags().Bool("sensitive", false, "Mask the value in listings even if the name doesn't look like a credential")
+
+	cmd.Flags().Bool("app-level", false, "Manage a variable shared by all services of the app")
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
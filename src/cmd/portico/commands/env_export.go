@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewEnvExportCmd exports the environment variables of a service
+func NewEnvExportCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export environment variables",
+		Long: `Print the environment variables of a service as dotenv, JSON or YAML.
+
+The dotenv output is quoted so that "env import" and docker compose read it back unchanged.
+
+Examples:
+  portico env my-app export > .env
+  portico env my-app api export --format json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (env)
+			appName, err := getAppNameFromEnvArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico env [app-name] [service-name] export [--format dotenv|json|yaml]")
+				return
+			}
+
+			// Get service-name from args (optional)
+			serviceName, _ := getServiceNameFromEnvArgs(cmd)
+
+			if format != "dotenv" && format != "json" && format != "yaml" {
+				fmt.Printf("Error: unknown format %s (use dotenv, json or yaml)\n", format)
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			// Auto-detect service if not specified
+			if serviceName == "" {
+				if len(a.Services) == 1 {
+					serviceName = a.Services[0].Name
+				} else {
+					var serviceNames []string
+					for _, s := range a.Services {
+						serviceNames = append(serviceNames, s.Name)
+					}
+					fmt.Printf("Error: app %s has %d services. Please specify service name\n", appName, len(a.Services))
+					fmt.Printf("Available services: %v\n", serviceNames)
+					fmt.Println("Usage: portico env [app-name] [service-name] export [--format dotenv|json|yaml]")
+					return
+				}
+			}
+
+			var env map[string]string
+			found := false
+			for _, s := range a.Services {
+				if s.Name == serviceName {
+					found = true
+					env = s.Environment
+					break
+				}
+			}
+			if !found {
+				fmt.Printf("Service %s not found in app %s\n", serviceName, appName)
+				return
+			}
+			if env == nil {
+				env = map[string]string{}
+			}
+
+			switch format {
+			case "json":
+				data, err := json.MarshalIndent(env, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding JSON: %v\n", err)
+					return
+				}
+				fmt.Println(string(data))
+			case "yaml":
+				data, err := yaml.Marshal(env)
+				if err != nil {
+					fmt.Printf("Error encoding YAML: %v\n", err)
+					return
+				}
+				fmt.Print(string(data))
+			default:
+				keys := make([]string, 0, len(env))
+				for key := range env {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					fmt.Println(util.FormatDotenv(key, env[key]))
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "dotenv", "Output format: dotenv, json or yaml")
+
+	return cmd
+}
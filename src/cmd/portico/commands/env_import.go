@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewEnvImportCmd imports environment variables from a dotenv file
+func NewEnvImportCmd() *cobra.Command {
+	var replace bool
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import environment variables from a .env file",
+		Long: `Import environment variables from a dotenv file ("-" reads stdin) and apply them
+in a single redeploy.
+
+The file is read with the same rules as docker compose: "export" prefixes and
+comments are allowed, 'single quoted' values are literal, "double quoted" values
+support escapes (\n, \t, \", \$) and may span several lines, and $VAR / ${VAR}
+are expanded in unquoted and double quoted values from earlier variables of the
+file. Other references (${PORTICO_APP}, ${addon:my-postgres.host}, variables
+already set on the service) are kept and resolved on deploy, like any env
+value; the environment of the shell running portico is never read.
+
+Existing variables not in the file are kept, unless --replace is given.
+
+Examples:
+  portico env my-app import .env
+    Adds or updates the variables of .env (uses default service if only one exists)
+
+  portico env my-app api import .env --replace
+    Makes the environment of service 'api' exactly the variables of .env
+
+  heroku config --shell -a my-app | portico env my-app import -`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (env)
+			appName, err := getAppNameFromEnvArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico env [app-name] [service-name] import [file] [--replace]")
+				return
+			}
+
+			// Get service-name from args (optional)
+			serviceName, _ := getServiceNameFromEnvArgs(cmd)
+
+			var content []byte
+			if args[0] == "-" {
+				content, err = io.ReadAll(os.Stdin)
+			} else {
+				content, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", args[0], err)
+				return
+			}
+
+			vars, err := util.ParseDotenv(string(content))
+			if err != nil {
+				fmt.Printf("Error parsing %s: %v\n", args[0], err)
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			// Auto-detect service if not specified
+			if serviceName == "" {
+				if len(a.Services) == 1 {
+					serviceName = a.Services[0].Name
+				} else {
+					var serviceNames []string
+					for _, s := range a.Services {
+						serviceNames = append(serviceNames, s.Name)
+					}
+					fmt.Printf("Error: app %s has %d services. Please specify service name\n", appName, len(a.Services))
+					fmt.Printf("Available services: %v\n", serviceNames)
+					fmt.Println("Usage: portico env [app-name] [service-name] import [file] [--replace]")
+					return
+				}
+			}
+
+			// Find service and apply the variables
+			var added, updated, removed []string
+			found := false
+			for i := range a.Services {
+				if a.Services[i].Name != serviceName {
+					continue
+				}
+				found = true
+				if a.Services[i].Environment == nil {
+					a.Services[i].Environment = make(map[string]string)
+				}
+				env := a.Services[i].Environment
+
+				imported := make(map[string]bool)
+				for _, v := range vars {
+					imported[v.Key] = true
+					current, exists := env[v.Key]
+					switch {
+					case !exists:
+						added = append(added, v.Key)
+					case current != v.Value:
+						updated = append(updated, v.Key)
+					default:
+						continue
+					}
+					env[v.Key] = v.Value
+				}
+
+				if replace {
+					for key := range env {
+						if !imported[key] {
+							delete(env, key)
+							removed = append(removed, key)
+						}
+					}
+				}
+				break
+			}
+			if !found {
+				fmt.Printf("Service %s not found in app %s\n", serviceName, appName)
+				return
+			}
+
+			if len(added) == 0 && len(updated) == 0 && len(removed) == 0 {
+				fmt.Printf("No changes: environment of service %s in %s already matches %s\n", serviceName, appName, args[0])
+				return
+			}
+
+			// Regenerate docker-compose and redeploy once for the whole set
+			if err := am.SaveApp(a); err != nil {
+				fmt.Printf("Error saving app: %v\n", err)
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			appDir := filepath.Join(cfg.AppsDir, appName)
+
+			var dockerServices []docker.Service
+			for _, s := range a.Services {
+				replicas := s.Replicas
+				if replicas == 0 {
+					replicas = 1 // Default to 1 if not specified
+				}
+				dockerServices = append(dockerServices, docker.Service{
+					Name:        s.Name,
+					Image:       s.Image,
+					Port:        s.Port,
+					ExtraPorts:  s.ExtraPorts,
+					Environment: s.Environment,
+					Volumes:     s.Volumes,
+					Secrets:     s.Secrets,
+					DependsOn:   s.DependsOn,
+					Replicas:    replicas,
+				})
+			}
+
+			event := journal.Event{
+				App: appName, Action: "env import", Summary: fmt.Sprintf("%s for service %s", args[0], serviceName),
+				After: importChanges(added, updated, removed),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("env import %s (%d added, %d updated, %d removed)", args[0], len(added), len(updated), len(removed)), false) {
+				recordChange(event, true, nil)
+				return
+			}
+			// docker compose up recreates the containers whose environment changed
+			if err := dm.DeployApp(appDir, dockerServices); err != nil {
+				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
+				return
+			}
+			recordChange(event, false, nil)
+
+			fmt.Printf("Imported environment for service %s in %s: %d added, %d updated, %d removed\n", serviceName, appName, len(added), len(updated), len(removed))
+			for _, change := range []struct {
+				label string
+				keys  []string
+			}{{"Added", added}, {"Updated", updated}, {"Removed", removed}} {
+				if len(change.keys) > 0 {
+					sort.Strings(change.keys)
+					fmt.Printf("  %s: %s\n", change.label, strings.Join(change.keys, ", "))
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&replace, "replace", false, "Remove variables that are not in the file")
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
+
+// importChanges lists the variables an import added, updated and removed, by
+// name only, for the journal
+func importChanges(added, updated, removed []string) string {
+	var parts []string
+	for _, change := range []struct {
+		label string
+		keys  []string
+	}{{"added", added}, {"updated", updated}, {"removed", removed}} {
+		if len(change.keys) > 0 {
+			keys := append([]string(nil), change.keys...)
+			sort.Strings(keys)
+			parts = append(parts, change.label+": "+strings.Join(keys, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
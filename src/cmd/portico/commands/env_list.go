@@ -2,11 +2,13 @@ package commands
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/spf13/cobra"
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
 // NewEnvListCmd lists environment variables for services in an app
@@ -14,7 +16,7 @@ func NewEnvListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List environment variables",
-		Long:  "List environment variables for services in an app. If only one service exists, lists that service. Otherwise lists all services.",
+		Long:  "List environment variables for services in an app. If only one service exists, lists that service. Otherwise lists all services.\n\nEach service shows the effective variables: its own, and the app-level ones it inherits (service variables take precedence).\nWith --app-level, lists only the app-level variables.\n\nValues of variables whose name looks like a credential (PASSWORD, SECRET, TOKEN, API_KEY, ...) or flagged with\n'env add --sensitive' are masked, as are passwords in URLs. Use --reveal to show them.",
 		Args:  cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (env)
@@ -41,6 +43,28 @@ func NewEnvListCmd() *cobra.Command {
 				return
 			}
 
+			// Values that look like credentials are masked unless --reveal is given
+			reveal, _ := cmd.Flags().GetBool("reveal")
+			sensitive := sensitiveEnvKeys(cfg, appName)
+			show := func(key, value string) string {
+				if reveal {
+					return value
+				}
+				return util.MaskEnvValue(key, value, sensitive)
+			}
+
+			// App-level variables only
+			if isAppLevelEnv() {
+				fmt.Printf("App-level environment variables for %s:\n", appName)
+				if len(a.Environment) == 0 {
+					fmt.Println("  (none)")
+				}
+				for _, k := range sortedKeys(a.Environment) {
+					fmt.Printf("  %s=%s\n", k, show(k, a.Environment[k]))
+				}
+				return
+			}
+
 			// Auto-detect service if not specified
 			if serviceName == "" {
 				if len(a.Services) == 1 {
@@ -55,13 +79,7 @@ func NewEnvListCmd() *cobra.Command {
 					if s.Name == serviceName {
 						found = true
 						fmt.Printf("Environment variables for service %s:\n", serviceName)
-						if len(s.Environment) == 0 {
-							fmt.Println("  (none)")
-						} else {
-							for k, v := range s.Environment {
-								fmt.Printf("  %s=%s\n", k, v)
-							}
-						}
+						printServiceEnv(a.Environment, s.Environment, show)
 						break
 					}
 				}
@@ -73,18 +91,56 @@ func NewEnvListCmd() *cobra.Command {
 				fmt.Printf("Environment variables for all services in %s:\n\n", appName)
 				for _, s := range a.Services {
 					fmt.Printf("Service: %s\n", s.Name)
-					if len(s.Environment) == 0 {
-						fmt.Println("  (none)")
-					} else {
-						for k, v := range s.Environment {
-							fmt.Printf("  %s=%s\n", k, v)
-						}
-					}
+					printServiceEnv(a.Environment, s.Environment, show)
 					fmt.Println()
 				}
 			}
 		},
 	}
 
+	cmd.Flags().Bool("app-level", false, "List only the variables shared by all services of the app")
+	cmd.Flags().Bool("reveal", false, "Show the values of sensitive variables (masked by default)")
+
 	return cmd
 }
+
+// printServiceEnv prints the effective environment of a service, showing where
+// each variable comes from (service variables override app-level ones).
+// show returns the value to print (masked or not)
+func printServiceEnv(appEnv, serviceEnv map[string]string, show func(key, value string) string) {
+	if len(appEnv) == 0 && len(serviceEnv) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	effective := make(map[string]string)
+	for k, v := range appEnv {
+		effective[k] = v
+	}
+	for k, v := range serviceEnv {
+		effective[k] = v
+	}
+
+	for _, k := range sortedKeys(effective) {
+		_, inService := serviceEnv[k]
+		_, inApp := appEnv[k]
+		switch {
+		case inService && inApp:
+			fmt.Printf("  %s=%s (overrides app-level)\n", k, show(k, effective[k]))
+		case inApp:
+			fmt.Printf("  %s=%s (app-level)\n", k, show(k, effective[k]))
+		default:
+			fmt.Printf("  %s=%s\n", k, show(k, effective[k]))
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewEventsCmd creates the events command to read the journal
+func NewEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Show the journal of changes made on the server",
+		Long: `Show who changed what, oldest first: every command that changes an app, an addon
+instance, SSH keys or the server (create, destroy, deploy, env, secrets, storage,
+ports, resources, domains, headers, set, addons, ssh) records an event in logs/events.log
+with the time, the actor (SSH key name or Unix user), the app and a summary.
+Secret values are never recorded; sensitive env values are masked.
+
+Examples:
+  portico events
+  portico events --app my-app --since 7d
+  portico events --action "env edit" --json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			appName, _ := cmd.Flags().GetString("app")
+			action, _ := cmd.Flags().GetString("action")
+			sinceFlag, _ := cmd.Flags().GetString("since")
+			limit, _ := cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			var since time.Time
+			if sinceFlag != "" {
+				window, err := parseSince(sinceFlag)
+				if err != nil {
+					fmt.Printf("Error: invalid --since %q (e.g. 30m, 1h, 7d)\n", sinceFlag)
+					return
+				}
+				since = time.Now().Add(-window)
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			events, err := journal.Read(cfg.PorticoHome)
+			if err != nil {
+				fmt.Printf("Error reading journal: %v\n", err)
+				return
+			}
+
+			var selected []journal.Event
+			for _, e := range events {
+				if appName != "" && e.App != appName {
+					continue
+				}
+				if action != "" && e.Action != action && !strings.HasPrefix(e.Action, action+" ") {
+					continue
+				}
+				if !since.IsZero() {
+					if t, err := time.Parse(time.RFC3339, e.Time); err != nil || t.Before(since) {
+						continue
+					}
+				}
+				selected = append(selected, e)
+			}
+			if limit > 0 && len(selected) > limit {
+				selected = selected[len(selected)-limit:]
+			}
+
+			if asJSON {
+				if selected == nil {
+					selected = []journal.Event{}
+				}
+				data, err := json.MarshalIndent(selected, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding JSON: %v\n", err)
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			if len(selected) == 0 {
+				fmt.Println("No events recorded.")
+				return
+			}
+
+			fmt.Printf("%-20s %-14s %-16s %-24s %s\n", "TIME", "ACTOR", "APP", "ACTION", "SUMMARY")
+			for _, e := range selected {
+				when := e.Time
+				if t, err := time.Parse(time.RFC3339, e.Time); err == nil {
+					when = t.Local().Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%-20s %-14s %-16s %-24s %s\n", when, e.Actor, orDash(e.App), e.Action, e.Summary)
+				if e.Before != "" || e.After != "" {
+					fmt.Printf("%20s %s -> %s\n", "", orDash(e.Before), orDash(e.After))
+				}
+				if e.Error != "" {
+					fmt.Printf("%20s error: %s\n", "", e.Error)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().String("app", "", "Only events of this app or addon instance")
+	cmd.Flags().String("action", "", "Only events of this action (e.g. deploy, env, \"secrets rotate\")")
+	cmd.Flags().String("since", "", "Only events in this time window (e.g. 1h, 7d)")
+	cmd.Flags().IntP("limit", "n", 0, "Show only the last n events (0: all)")
+	cmd.Flags().Bool("json", false, "Print the events as JSON")
+
+	return cmd
+}
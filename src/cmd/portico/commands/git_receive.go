@@ -13,6 +13,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -93,6 +94,17 @@ func NewGitReceiveCmd() *cobra.Command {
 				_ = os.Chdir(oldCwd)
 			}()
 
+			// Static sites are built and published without containers
+			dockerManager := docker.NewManager(cfg.Registry.URL)
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			if metadata, err := dockerManager.GetPorticoMetadata(appDir); err == nil && metadata.Static != nil {
+				if err := deployStaticSite(cfg, appName, tmpDir, metadata.Static); err != nil {
+					fmt.Printf("Error deploying static site: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+
 			// Deploy using Portico
 			appManager := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
 			appConfig, err := appManager.LoadApp(appName)
@@ -120,6 +132,9 @@ func NewGitReceiveCmd() *cobra.Command {
 			// Generate image name
 			imageName := fmt.Sprintf("portico-%s:latest", appName)
 
+			// The running release, to roll back to if the new one crash-loops
+			previous := currentRelease(cfg, appDir)
+
 			// Build Docker image
 			fmt.Printf("Building Docker image: %s\n", imageName)
 			buildCmd := exec.Command("docker", "build", "-t", imageName, "-f", dockerfile, ".")
@@ -154,9 +169,6 @@ func NewGitReceiveCmd() *cobra.Command {
 			}
 
 			// Generate docker-compose.yml
-			dockerManager := docker.NewManager(cfg.Registry.URL)
-			appDir := filepath.Join(cfg.AppsDir, appName)
-
 			var dockerServices []docker.Service
 			for _, svc := range appConfig.Services {
 				replicas := svc.Replicas
@@ -187,7 +199,7 @@ func NewGitReceiveCmd() *cobra.Command {
 			}
 
 			// Deploy the application
-			if err := dockerManager.DeployApp(appDir, dockerServices); err != nil {
+			if err := deployWithWatch(cfg, appDir, dockerServices, previous, true); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
 				os.Exit(1)
 			}
@@ -205,3 +217,69 @@ func NewGitReceiveCmd() *cobra.Command {
 
 	return cmd
 }
+
+// deployStaticSite builds a static site (in a builder container if configured),
+// publishes the output directory as a new release and refreshes Caddy
+func deployStaticSite(cfg *config.Config, appName, srcDir string, static *docker.StaticConfig) error {
+	if static.BuildCommand != "" {
+		var buildCmd *exec.Cmd
+		if static.BuildImage != "" {
+			// Run as the current user so the build output can be cleaned up
+			fmt.Printf("Building static site in %s: %s\n", static.BuildImage, static.BuildCommand)
+			buildCmd = exec.Command("docker", "run", "--rm",
+				"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+				"-e", "HOME=/tmp",
+				"-v", srcDir+":/src",
+				"-w", "/src",
+				static.BuildImage, "sh", "-c", static.BuildCommand)
+		} else {
+			fmt.Printf("Building static site: %s\n", static.BuildCommand)
+			buildCmd = exec.Command("sh", "-c", static.BuildCommand)
+			buildCmd.Dir = srcDir
+		}
+		buildCmd.Stdout = os.Stdout
+		buildCmd.Stderr = os.Stderr
+		if err := buildCmd.Run(); err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+	}
+
+	outputDir := filepath.Join(srcDir, filepath.Clean(static.OutputDir))
+	if rel, err := filepath.Rel(srcDir, outputDir); err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("output directory %s is outside the repository", static.OutputDir)
+	}
+	if info, err := os.Stat(outputDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("output directory %s not found after build", static.OutputDir)
+	}
+
+	appManager := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+	release, err := appManager.PublishStaticRelease(appName, outputDir)
+	if err != nil {
+		return err
+	}
+
+	dockerManager := docker.NewManager(cfg.Registry.URL)
+	err = dockerManager.UpdatePorticoMetadata(filepath.Join(cfg.AppsDir, appName), func(m *docker.PorticoMetadata) {
+		if m.Static != nil {
+			m.Static.Release = release
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error recording release: %w", err)
+	}
+
+	if err := appManager.CreateDefaultCaddyfile(appName); err != nil {
+		fmt.Printf("Warning: could not update Caddyfile: %v\n", err)
+	}
+
+	proxyManager := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
+	if err := proxyManager.UpdateCaddyfile(cfg.AppsDir); err != nil {
+		return fmt.Errorf("error updating Caddyfile: %w", err)
+	}
+
+	journal.Record(journal.Event{
+		App: appName, Action: "deploy", Summary: "static site", After: "release " + release,
+	})
+	fmt.Printf("✅ Static site %s published (release %s)\n", appName, release)
+	return nil
+}
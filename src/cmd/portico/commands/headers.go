@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/proxy"
+)
+
+// headersCommands lists the subcommands of headers
+var headersCommands = map[string]bool{
+	"set":     true,
+	"unset":   true,
+	"profile": true,
+	"list":    true,
+}
+
+// NewHeadersCmd is the root command for response headers: headers [app-name] ...
+func NewHeadersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "headers [app-name]",
+		Short:              "Manage response headers, CORS and security profiles",
+		Long:               "Manage the response headers Caddy sets for an application: security header profiles, CORS and custom headers.",
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true, // Flags belong to the subcommands
+		Run: func(parentCmd *cobra.Command, args []string) {
+			// Parse os.Args directly to find subcommand
+			allArgs := os.Args[1:] // Skip program name
+
+			var subcommandName string
+			var subcommandIndex int
+
+			// Find "headers" in arguments
+			headersIndex := -1
+			for i, arg := range allArgs {
+				if arg == "headers" {
+					headersIndex = i
+					break
+				}
+			}
+
+			if headersIndex == -1 {
+				_ = parentCmd.Help()
+				return
+			}
+
+			// Find subcommand after "headers" and app-name
+			for i := headersIndex + 1; i < len(allArgs); i++ {
+				if headersCommands[allArgs[i]] {
+					subcommandName = allArgs[i]
+					subcommandIndex = i
+					break
+				}
+			}
+
+			// If no subcommand found, show help
+			if subcommandName == "" {
+				_ = parentCmd.Help()
+				return
+			}
+
+			// Find and execute subcommand
+			for _, subCmd := range parentCmd.Commands() {
+				if subCmd.Name() == subcommandName {
+					// Get arguments for subcommand (everything after subcommand name)
+					subcommandArgs := allArgs[subcommandIndex+1:]
+
+					// Parse flags manually for the subcommand
+					if err := subCmd.ParseFlags(subcommandArgs); err != nil {
+						fmt.Printf("Error parsing flags: %v\n", err)
+						_ = subCmd.Help()
+						return
+					}
+
+					// Get non-flag arguments
+					nonFlagArgs := subCmd.Flags().Args()
+
+					// Call the subcommand's Run function directly
+					if subCmd.Run != nil {
+						subCmd.Run(subCmd, nonFlagArgs)
+					} else {
+						_ = subCmd.Help()
+					}
+					return
+				}
+			}
+
+			// Subcommand not found
+			_ = parentCmd.Help()
+		},
+	}
+	return cmd
+}
+
+// getAppNameFromHeadersArgs extracts app-name from headers command arguments
+// It parses os.Args to find the app-name after "headers"
+func getAppNameFromHeadersArgs(cmd *cobra.Command) (string, error) {
+	// Parse os.Args to find app-name after "headers"
+	args := os.Args[1:] // Skip program name
+	for i, arg := range args {
+		if arg == "headers" {
+			// App-name comes before the subcommand
+			for j := i + 1; j < len(args); j++ {
+				if headersCommands[args[j]] {
+					break
+				}
+				// Skip if it's a flag
+				if args[j][0] == '-' {
+					continue
+				}
+				// This should be the app-name
+				return args[j], nil
+			}
+			break
+		}
+	}
+	return "", nil
+}
+
+// updateHeadersConfig applies changes to the app headers configuration,
+// then regenerates the app Caddyfile and refreshes the reverse proxy
+func updateHeadersConfig(appName string, update func(*docker.HeadersConfig)) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	appDir := filepath.Join(cfg.AppsDir, appName)
+	dm := docker.NewManager(cfg.Registry.URL)
+	err = dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+		if metadata.Headers == nil {
+			metadata.Headers = &docker.HeadersConfig{}
+		}
+		update(metadata.Headers)
+		// Drop the section when everything is back to defaults
+		if metadata.Headers.Profile == "" && len(metadata.Headers.CORSOrigins) == 0 && len(metadata.Headers.Custom) == 0 {
+			metadata.Headers = nil
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error updating app metadata: %w", err)
+	}
+
+	am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+	if err := am.CreateDefaultCaddyfile(appName); err != nil {
+		fmt.Printf("Warning: could not update Caddyfile: %v\n", err)
+	}
+
+	pm := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
+	if err := pm.UpdateCaddyfile(cfg.AppsDir); err != nil {
+		return fmt.Errorf("error updating proxy Caddyfile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+)
+
+// NewHeadersListCmd lists the response headers of an app
+func NewHeadersListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List response headers",
+		Long:  "List the response headers Caddy sets for the application, with the profile or custom source of each one.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (headers)
+			appName, err := getAppNameFromHeadersArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico headers [app-name] list")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error loading app metadata: %v\n", err)
+				return
+			}
+
+			profile := app.DefaultHeaderProfile
+			if metadata.Headers != nil && metadata.Headers.Profile != "" {
+				profile = metadata.Headers.Profile
+			}
+
+			fmt.Printf("Response headers for %s (profile: %s):\n", appName, profile)
+			for _, line := range app.EffectiveHeaders(metadata.Headers) {
+				if line.Remove {
+					fmt.Printf("  %-28s (removed) [%s]\n", line.Name, line.Source)
+					continue
+				}
+				fmt.Printf("  %-28s %s [%s]\n", line.Name, line.Value, line.Source)
+			}
+
+			if metadata.Headers != nil && len(metadata.Headers.CORSOrigins) > 0 {
+				fmt.Printf("\nCORS allowed origins: %s\n", strings.Join(metadata.Headers.CORSOrigins, ", "))
+			}
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewHeadersProfileCmd selects the security header profile of an app
+func NewHeadersProfileCmd() *cobra.Command {
+	var origins string
+
+	cmd := &cobra.Command{
+		Use:   "profile [name]",
+		Short: "Select a security header profile",
+		Long: `Select the security header profile of an application.
+
+Profiles:
+  default    - X-Content-Type-Options, X-Frame-Options DENY, X-XSS-Protection
+  strict     - HSTS, Content-Security-Policy, no framing, no Server header
+  relaxed    - Allows embedding in other sites (widgets, iframes)
+  api-cors   - For APIs called from browsers; requires --origins
+
+Custom headers (headers set) are kept and override profile headers.
+--origins enables CORS for the given origins with any profile ("*" allows any).
+
+Examples:
+  portico headers my-app profile strict
+  portico headers my-app profile relaxed
+  portico headers my-app profile api-cors --origins https://app.example.com,https://admin.example.com`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (headers)
+			appName, err := getAppNameFromHeadersArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico headers [app-name] profile [name] [--origins https://a.example.com,...]")
+				return
+			}
+
+			profile := args[0]
+			if _, ok := app.HeaderProfiles[profile]; !ok {
+				fmt.Printf("Error: unknown profile %s\n", profile)
+				fmt.Printf("Available profiles: %s\n", strings.Join(app.HeaderProfileNames(), ", "))
+				return
+			}
+
+			// Parse allowed origins
+			var corsOrigins []string
+			for _, origin := range strings.Split(origins, ",") {
+				origin = strings.TrimSpace(strings.TrimSuffix(origin, "/"))
+				if origin == "" {
+					continue
+				}
+				if err := app.ValidateOrigin(origin); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				if origin != "*" {
+					u, err := url.Parse(origin)
+					if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+						fmt.Printf("Error: invalid origin %s (use scheme://host[:port])\n", origin)
+						return
+					}
+				}
+				corsOrigins = append(corsOrigins, origin)
+			}
+			if profile == "api-cors" && len(corsOrigins) == 0 {
+				fmt.Println("Error: the api-cors profile requires --origins")
+				return
+			}
+
+			previous := app.DefaultHeaderProfile
+			err = updateHeadersConfig(appName, func(headers *docker.HeadersConfig) {
+				if headers.Profile != "" {
+					previous = headers.Profile
+				}
+				headers.Profile = profile
+				if profile == app.DefaultHeaderProfile {
+					headers.Profile = ""
+				}
+				headers.CORSOrigins = corsOrigins
+			})
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			journal.Record(journal.Event{
+				App: appName, Action: "headers profile", Summary: strings.Join(append([]string{profile}, corsOrigins...), " "), Before: previous, After: profile,
+			})
+			fmt.Printf("Header profile %s applied to app %s\n", profile, appName)
+			if len(corsOrigins) > 0 {
+				fmt.Printf("CORS allowed origins: %s\n", strings.Join(corsOrigins, ", "))
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&origins, "origins", "", "Comma-separated origins allowed by CORS (* for any)")
+
+	return cmd
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// headerNamePattern matches valid HTTP header names
+var headerNamePattern = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+.^_|~-]+$`)
+
+// NewHeadersSetCmd sets a custom response header
+func NewHeadersSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set [name] [value]",
+		Short: "Set a response header",
+		Long: `Set a response header. Custom headers override headers from the profile.
+
+Examples:
+  portico headers my-app set Content-Security-Policy "default-src 'self'"
+  portico headers my-app set X-Frame-Options SAMEORIGIN`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (headers)
+			appName, err := getAppNameFromHeadersArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico headers [app-name] set [name] [value]")
+				return
+			}
+
+			name := http.CanonicalHeaderKey(args[0])
+			value := args[1]
+			if !headerNamePattern.MatchString(name) {
+				fmt.Printf("Error: invalid header name %s\n", args[0])
+				return
+			}
+			if value == "" {
+				fmt.Println("Error: header value is required (use unset to remove a header)")
+				return
+			}
+			if err := app.ValidateHeaderValue(value); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			previous := ""
+			err = updateHeadersConfig(appName, func(headers *docker.HeadersConfig) {
+				if headers.Custom == nil {
+					headers.Custom = make(map[string]string)
+				}
+				previous = headers.Custom[name]
+				headers.Custom[name] = value
+			})
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			journal.Record(journal.Event{
+				App: appName, Action: "headers set", Summary: name, Before: previous, After: value,
+			})
+			fmt.Printf("Header %s set for app %s\n", name, appName)
+		},
+	}
+}
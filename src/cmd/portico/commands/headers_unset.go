@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewHeadersUnsetCmd removes a response header
+func NewHeadersUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset [name]",
+		Short: "Remove a response header",
+		Long: `Remove a response header.
+
+A custom header is dropped (the profile value applies again, if any).
+A header that only comes from the profile is removed from responses.
+
+Examples:
+  portico headers my-app unset X-Frame-Options`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (headers)
+			appName, err := getAppNameFromHeadersArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico headers [app-name] unset [name]")
+				return
+			}
+
+			name := http.CanonicalHeaderKey(args[0])
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error loading app metadata: %v\n", err)
+				return
+			}
+
+			// Find where the header comes from
+			source := ""
+			for _, line := range app.EffectiveHeaders(metadata.Headers) {
+				if http.CanonicalHeaderKey(line.Name) == name && !line.Remove {
+					source = line.Source
+				}
+			}
+			if source == "" {
+				fmt.Printf("Header %s is not set for app %s\n", name, appName)
+				return
+			}
+
+			err = updateHeadersConfig(appName, func(headers *docker.HeadersConfig) {
+				if source == "custom" {
+					delete(headers.Custom, name)
+					return
+				}
+				// Profile header: record the removal
+				if headers.Custom == nil {
+					headers.Custom = make(map[string]string)
+				}
+				headers.Custom[name] = ""
+			})
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			journal.Record(journal.Event{
+				App: appName, Action: "headers unset", Summary: fmt.Sprintf("%s (%s)", name, source),
+			})
+			fmt.Printf("Header %s removed from app %s\n", name, appName)
+		},
+	}
+}
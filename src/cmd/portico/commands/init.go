@@ -9,6 +9,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/embed"
+	"github.com/maxvegac/portico/src/internal/proxy"
 	"github.com/maxvegac/portico/src/internal/util"
 )
 
@@ -27,6 +28,7 @@ Files are extracted to their correct locations:
   - /home/portico/config.yml
   - /home/portico/reverse-proxy/docker-compose.yml
   - /home/portico/reverse-proxy/Caddyfile
+  - /home/portico/reverse-proxy/Dockerfile (Caddy build with rate limiting)
   - /home/portico/addons/definitions/*.yml
 
 Templates can be customized by editing files in /home/portico/templates/`,
@@ -89,6 +91,19 @@ Templates can be customized by editing files in /home/portico/templates/`,
 				return
 			}
 
+			// Copy the app Caddyfiles to the directory the proxy imports them from
+			pm := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
+			if err := pm.UpdateCaddyfile(cfg.AppsDir); err != nil {
+				fmt.Printf("Warning: could not copy app Caddyfiles: %v\n", err)
+			}
+
+			// Extract Dockerfile to reverse-proxy directory (custom Caddy build)
+			reverseProxyDockerfile := filepath.Join(cfg.ProxyDir, "Dockerfile")
+			if err := embed.ExtractStaticFile("static/reverse-proxy/Dockerfile", reverseProxyDockerfile); err != nil {
+				fmt.Printf("Error extracting Dockerfile to reverse-proxy: %v\n", err)
+				return
+			}
+
 			// Extract addon definitions
 			addonsDir := filepath.Join(cfg.AddonsDir, "definitions")
 			addonTypes := []string{"postgresql", "mysql", "mariadb", "mongodb", "redis", "valkey"}
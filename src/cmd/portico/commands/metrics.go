@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/metrics"
+)
+
+// NewMetricsCmd creates the metrics command for the Prometheus exporter
+func NewMetricsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Export metrics for Prometheus",
+		Long: `Export metrics of apps and addon instances for Prometheus: container CPU, memory,
+network and restarts, deploys, addon instances up/down and HTTP requests per app
+(from the Caddy access logs).
+
+Run 'portico metrics serve' as an exporter, or 'portico metrics textfile' for the
+textfile collector of node_exporter.`,
+	}
+
+	cmd.AddCommand(NewMetricsServeCmd())
+	cmd.AddCommand(NewMetricsTextfileCmd())
+
+	return cmd
+}
+
+// accessLogsDir returns the directory of the Caddy access logs of the apps
+func accessLogsDir(cfg *config.Config) string {
+	return filepath.Join(cfg.PorticoHome, "logs", "apps")
+}
+
+// collectMetrics returns the metrics of all apps and addon instances in the
+// Prometheus text format. The request counters are updated from the new lines
+// of the access logs
+func collectMetrics(cfg *config.Config, accessLogs *metrics.AccessLogs) (string, error) {
+	start := time.Now()
+
+	entries, err := getOverview(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	addonManager := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+	addonConfig, err := addonManager.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("error loading addons config: %w", err)
+	}
+
+	var apps []string
+	for _, e := range entries {
+		if e.Kind != "addon" {
+			apps = append(apps, e.Name)
+		}
+	}
+	accessLogsErr := accessLogs.Update(apps)
+
+	w := &metrics.Writer{}
+
+	w.Family("portico_app_info", "gauge", "Apps and addon instances managed by Portico.")
+	for _, e := range entries {
+		w.Sample("portico_app_info", 1, "kind", e.Kind, "app", e.Name, "domain", e.Domain)
+	}
+
+	w.Family("portico_app_replicas_desired", "gauge", "Containers an app or addon instance should run.")
+	for _, e := range entries {
+		if e.Error == "" && e.Kind != "static" {
+			w.Sample("portico_app_replicas_desired", float64(e.Desired), "kind", e.Kind, "app", e.Name)
+		}
+	}
+	w.Family("portico_app_replicas_running", "gauge", "Containers of an app or addon instance running.")
+	for _, e := range entries {
+		if e.Error == "" && e.Kind != "static" {
+			w.Sample("portico_app_replicas_running", float64(e.Running), "kind", e.Kind, "app", e.Name)
+		}
+	}
+	w.Family("portico_app_status_error", "gauge", "1 if the status of an app or addon instance could not be read.")
+	for _, e := range entries {
+		w.Sample("portico_app_status_error", metrics.Bool(e.Error != ""), "kind", e.Kind, "app", e.Name)
+	}
+
+	w.Family("portico_addon_up", "gauge", "1 if all containers of an addon instance are running.")
+	for _, e := range entries {
+		if e.Kind == "addon" {
+			up := e.Error == "" && e.Desired > 0 && e.Running >= e.Desired
+			w.Sample("portico_addon_up", metrics.Bool(up), "instance", e.Name, "type", addonConfig.Instances[e.Name].Type)
+		}
+	}
+
+	writeContainerMetrics(w, entries)
+	writeDeployMetrics(w, cfg, entries)
+	writeRequestMetrics(w, accessLogs, apps)
+
+	w.Family("portico_access_log_error", "gauge", "1 if an access log could not be read.")
+	w.Sample("portico_access_log_error", metrics.Bool(accessLogsErr != nil))
+	w.Family("portico_scrape_duration_seconds", "gauge", "Time taken to collect the metrics.")
+	w.Sample("portico_scrape_duration_seconds", time.Since(start).Seconds())
+
+	return w.String(), nil
+}
+
+// containerMetric is a metric with a value per container
+type containerMetric struct {
+	name, typ, help string
+	value           func(c docker.ContainerStatus) float64
+	only            func(c docker.ContainerStatus) bool // Containers with the metric (nil: all)
+}
+
+// running selects running containers: docker stats only has those
+func running(c docker.ContainerStatus) bool { return c.Running() }
+
+// withExactNetwork selects containers with network bytes read from /proc: the
+// rounded docker stats values can go backwards, which breaks counters
+func withExactNetwork(c docker.ContainerStatus) bool { return c.Running() && c.NetworkExact }
+
+// withHealthCheck selects containers with a health check
+func withHealthCheck(c docker.ContainerStatus) bool { return c.Health != "" }
+
+// containerMetrics are the metrics of each container
+var containerMetrics = []containerMetric{
+	{"portico_container_up", "gauge", "1 if the container is running.", func(c docker.ContainerStatus) float64 { return metrics.Bool(c.Running()) }, nil},
+	{"portico_container_healthy", "gauge", "1 if the health check of the container passes (containers with a health check).", func(c docker.ContainerStatus) float64 { return metrics.Bool(c.Health == "healthy") }, withHealthCheck},
+	{"portico_container_restarts_total", "counter", "Times Docker restarted the container.", func(c docker.ContainerStatus) float64 { return float64(c.RestartCount) }, nil},
+	{"portico_container_cpu_percent", "gauge", "CPU use of the container (100 = one core).", func(c docker.ContainerStatus) float64 { return c.CPUPercent }, running},
+	{"portico_container_memory_bytes", "gauge", "Memory use of the container.", func(c docker.ContainerStatus) float64 { return float64(c.MemoryUsage) }, running},
+	{"portico_container_memory_limit_bytes", "gauge", "Memory limit of the container.", func(c docker.ContainerStatus) float64 { return float64(c.MemoryLimit) }, running},
+	{"portico_container_network_receive_bytes_total", "counter", "Bytes received by the container since it started.", func(c docker.ContainerStatus) float64 { return float64(c.NetworkRx) }, withExactNetwork},
+	{"portico_container_network_transmit_bytes_total", "counter", "Bytes sent by the container since it started.", func(c docker.ContainerStatus) float64 { return float64(c.NetworkTx) }, withExactNetwork},
+}
+
+// writeContainerMetrics writes the metrics of the containers of apps and addon instances
+func writeContainerMetrics(w *metrics.Writer, entries []*OverviewEntry) {
+	for _, m := range containerMetrics {
+		w.Family(m.name, m.typ, m.help)
+		for _, e := range entries {
+			for _, c := range e.containers {
+				if m.only != nil && !m.only(c) {
+					continue
+				}
+				w.Sample(m.name, m.value(c), "kind", e.Kind, "app", e.Name, "service", c.Service, "replica", strconv.Itoa(c.Replica))
+			}
+		}
+	}
+}
+
+// writeDeployMetrics writes the deploy counters and durations of the apps,
+// from their deploy logs
+func writeDeployMetrics(w *metrics.Writer, cfg *config.Config, entries []*OverviewEntry) {
+	type deployStats struct {
+		succeeded, failed int
+		rollbacks         int
+		durationSum       float64
+		last              docker.DeployRecord
+	}
+	stats := make(map[string]*deployStats)
+	var apps []string
+	for _, e := range entries {
+		if e.Kind != "app" {
+			continue
+		}
+		history, err := docker.DeployHistory(filepath.Join(cfg.AppsDir, e.Name))
+		if err != nil || len(history) == 0 {
+			continue
+		}
+		s := &deployStats{}
+		for _, record := range history {
+			if record.IsRollback() {
+				s.rollbacks++
+				continue
+			}
+			s.last = record
+			if record.Success {
+				s.succeeded++
+			} else {
+				s.failed++
+			}
+			s.durationSum += record.Duration
+		}
+		stats[e.Name] = s
+		apps = append(apps, e.Name)
+	}
+
+	w.Family("portico_deploys_total", "counter", "Deploys (docker compose up) of the app, by result.")
+	for _, app := range apps {
+		w.Sample("portico_deploys_total", float64(stats[app].succeeded), "app", app, "result", "success")
+		w.Sample("portico_deploys_total", float64(stats[app].failed), "app", app, "result", "failure")
+	}
+	w.Family("portico_deploy_duration_seconds", "summary", "Duration of the deploys of the app.")
+	for _, app := range apps {
+		s := stats[app]
+		w.Sample("portico_deploy_duration_seconds_sum", s.durationSum, "app", app)
+		w.Sample("portico_deploy_duration_seconds_count", float64(s.succeeded+s.failed), "app", app)
+	}
+	w.Family("portico_rollbacks_total", "counter", "Automatic rollbacks of the app after a release crash-looped or failed its health checks.")
+	for _, app := range apps {
+		w.Sample("portico_rollbacks_total", float64(stats[app].rollbacks), "app", app)
+	}
+	w.Family("portico_last_deploy_timestamp_seconds", "gauge", "When the last deploy of the app started.")
+	for _, app := range apps {
+		if t, err := time.Parse(time.RFC3339, stats[app].last.Time); err == nil {
+			w.Sample("portico_last_deploy_timestamp_seconds", float64(t.Unix()), "app", app)
+		}
+	}
+	w.Family("portico_last_deploy_success", "gauge", "1 if the last deploy of the app succeeded.")
+	for _, app := range apps {
+		w.Sample("portico_last_deploy_success", metrics.Bool(stats[app].last.Success), "app", app)
+	}
+}
+
+// writeRequestMetrics writes the HTTP request counters of the apps
+func writeRequestMetrics(w *metrics.Writer, accessLogs *metrics.AccessLogs, apps []string) {
+	w.Family("portico_http_requests_total", "counter", "HTTP requests handled by Caddy for the app, by status code.")
+	for _, app := range apps {
+		stats := accessLogs.Apps[app]
+		if stats == nil {
+			continue
+		}
+		var codes []int
+		for code := range stats.Requests {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			w.Sample("portico_http_requests_total", float64(stats.Requests[code]), "app", app, "code", strconv.Itoa(code))
+		}
+	}
+	w.Family("portico_http_request_duration_seconds", "summary", "Duration of the HTTP requests of the app.")
+	for _, app := range apps {
+		stats := accessLogs.Apps[app]
+		if stats == nil {
+			continue
+		}
+		var count uint64
+		for _, n := range stats.Requests {
+			count += n
+		}
+		w.Sample("portico_http_request_duration_seconds_sum", stats.DurationSum, "app", app)
+		w.Sample("portico_http_request_duration_seconds_count", float64(count), "app", app)
+	}
+	w.Family("portico_http_response_bytes_total", "counter", "Bytes of the HTTP response bodies of the app.")
+	for _, app := range apps {
+		if stats := accessLogs.Apps[app]; stats != nil {
+			w.Sample("portico_http_response_bytes_total", float64(stats.BytesSent), "app", app)
+		}
+	}
+}
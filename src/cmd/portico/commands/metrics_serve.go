@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/metrics"
+)
+
+// NewMetricsServeCmd creates the metrics serve command
+func NewMetricsServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve metrics over HTTP for Prometheus to scrape",
+		Long: `Serve the metrics at http://<listen>/metrics. Each scrape reads the status of all
+containers (docker compose ps and docker stats, about a second) and the lines
+appended to the Caddy access logs since the previous scrape.
+
+Examples:
+  portico metrics serve
+  portico metrics serve --listen 127.0.0.1:9110`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			listen, _ := cmd.Flags().GetString("listen")
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			// Scrapes run one at a time: collecting reads config.yml and
+			// updates the request counters
+			var mu sync.Mutex
+			accessLogs := metrics.NewAccessLogs(accessLogsDir(cfg))
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				output, err := collectMetrics(cfg, accessLogs)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+				_, _ = w.Write([]byte(output))
+			})
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte("<html><body><h1>Portico exporter</h1><a href=\"/metrics\">Metrics</a></body></html>\n"))
+			})
+
+			server := &http.Server{
+				Addr:              listen,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			fmt.Printf("Serving metrics at http://%s/metrics\n", listen)
+			if err := server.ListenAndServe(); err != nil {
+				fmt.Printf("Error serving metrics: %v\n", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("listen", ":9110", "Address to listen on")
+
+	return cmd
+}
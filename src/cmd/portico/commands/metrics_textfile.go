@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/metrics"
+)
+
+// metricsStateFile keeps the request counters between textfile runs
+const metricsStateFile = ".metrics-access-logs.json"
+
+// NewMetricsTextfileCmd creates the metrics textfile command
+func NewMetricsTextfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "textfile",
+		Short: "Write metrics for the textfile collector of node_exporter",
+		Long: `Write the metrics to <dir>/portico.prom for the textfile collector of node_exporter
+(--collector.textfile.directory). The file is replaced atomically.
+
+Run it from cron or a systemd timer, or keep it running with --interval. The
+request counters are kept in logs/.metrics-access-logs.json between runs.
+
+Examples:
+  portico metrics textfile --dir /var/lib/prometheus/node-exporter
+  portico metrics textfile --dir /var/lib/prometheus/node-exporter --interval 30s`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			dir, _ := cmd.Flags().GetString("dir")
+			interval, _ := cmd.Flags().GetDuration("interval")
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			stateFile := filepath.Join(cfg.PorticoHome, "logs", metricsStateFile)
+			accessLogs, err := metrics.LoadAccessLogs(accessLogsDir(cfg), stateFile)
+			if err != nil {
+				fmt.Printf("Error loading request counters: %v\n", err)
+				return
+			}
+
+			for {
+				if err := writeMetricsTextfile(cfg, accessLogs, dir); err != nil {
+					fmt.Printf("Error writing metrics: %v\n", err)
+					if interval == 0 {
+						return
+					}
+				} else if err := accessLogs.Save(stateFile); err != nil {
+					fmt.Printf("Error saving request counters: %v\n", err)
+				}
+				if interval == 0 {
+					return
+				}
+				time.Sleep(interval)
+			}
+		},
+	}
+
+	cmd.Flags().String("dir", "/var/lib/prometheus/node-exporter", "Textfile collector directory")
+	cmd.Flags().Duration("interval", 0, "Keep running, writing the metrics at this interval (default: write once)")
+
+	return cmd
+}
+
+// writeMetricsTextfile collects the metrics and replaces <dir>/portico.prom
+func writeMetricsTextfile(cfg *config.Config, accessLogs *metrics.AccessLogs, dir string) error {
+	output, err := collectMetrics(cfg, accessLogs)
+	if err != nil {
+		return err
+	}
+
+	// node_exporter only reads *.prom files, so the temporary file is ignored
+	path := filepath.Join(dir, "portico.prom")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(output), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
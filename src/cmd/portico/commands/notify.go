@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/notify"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewNotifyCmd creates the notify command for notification channels
+func NewNotifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Manage deploy and incident notifications",
+		Long: `Notification channels are configured in config.yml (notifications and smtp).
+Portico notifies them when deploys start, succeed or fail, when a new release
+crash-loops and when an app is rolled back.`,
+	}
+
+	cmd.AddCommand(NewNotifyListCmd())
+	cmd.AddCommand(NewNotifyTestCmd())
+
+	return cmd
+}
+
+// NewNotifyTestCmd sends a test notification
+func NewNotifyTestCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test [channel]",
+		Short: "Send a test notification",
+		Long: `Send a test notification to every channel (or to one channel, by name) and
+report which ones failed. Test notifications ignore the events and apps filters.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+			if len(cfg.Notifications) == 0 {
+				fmt.Println("No notification channels configured (notifications in config.yml)")
+				return
+			}
+
+			event := notify.NewEvent(notify.EventTest, "", "Test notification from Portico", "Notifications are working.")
+			event.Actor = util.CurrentActor(cfg.PorticoHome)
+
+			found := false
+			for _, channel := range cfg.Notifications {
+				if len(args) == 1 && channel.Name != args[0] {
+					continue
+				}
+				found = true
+				if err := notify.SendTo(cfg, channel, event); err != nil {
+					fmt.Printf("❌ %s (%s): %v\n", channel.Name, channel.Type, err)
+					continue
+				}
+				fmt.Printf("✅ %s (%s): sent\n", channel.Name, channel.Type)
+			}
+			if !found {
+				fmt.Printf("Error: channel %s not found\n", args[0])
+			}
+		},
+	}
+}
+
+// NewNotifyListCmd lists the notification channels
+func NewNotifyListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List notification channels",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+			if len(cfg.Notifications) == 0 {
+				fmt.Println("No notification channels configured (notifications in config.yml)")
+				return
+			}
+
+			known := make(map[string]bool)
+			for _, event := range notify.Events {
+				known[event] = true
+			}
+
+			for _, channel := range cfg.Notifications {
+				// Webhook URLs often carry a token: only the host is shown
+				target := channel.URL
+				if u, err := url.Parse(channel.URL); err == nil && u.Host != "" {
+					target = u.Scheme + "://" + u.Host + "/..."
+				}
+				if channel.Type == "email" {
+					target = fmt.Sprintf("%v via %s", channel.To, cfg.SMTP.Host)
+				}
+				fmt.Printf("%s (%s): %s\n", channel.Name, channel.Type, target)
+
+				events := channel.Events
+				if len(events) == 0 {
+					events = notify.Events
+				}
+				fmt.Printf("  events: %v\n", events)
+				if len(channel.Apps) > 0 {
+					apps := append([]string(nil), channel.Apps...)
+					sort.Strings(apps)
+					fmt.Printf("  apps: %v\n", apps)
+				}
+				for _, event := range channel.Events {
+					if !known[event] {
+						fmt.Printf("  ⚠️  unknown event %s\n", event)
+					}
+				}
+				if _, err := notify.Render(channel, notify.NewEvent(notify.EventTest, "", "", "")); err != nil {
+					fmt.Printf("  ⚠️  template: %v\n", err)
+				}
+			}
+		},
+	}
+}
@@ -14,6 +14,8 @@ func NewPortsCmd() *cobra.Command {
 		Short: "Manage port mappings",
 		Long:  "Manage port mappings for an application's services.",
 		Args:  cobra.ArbitraryArgs,
+		// Flags belong to the subcommands
+		DisableFlagParsing: true,
 		Run: func(parentCmd *cobra.Command, args []string) {
 			// Parse os.Args directly to find subcommand
 			allArgs := os.Args[1:] // Skip program name
@@ -134,9 +136,9 @@ func getServiceNameFromPortsArgs(cmd *cobra.Command) (string, error) {
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
+				// Service-name comes before the subcommand
 				if args[j] == "add" || args[j] == "delete" || args[j] == "del" || args[j] == "list" {
-					continue
+					break
 				}
 				if !appNameFound {
 					appNameFound = true
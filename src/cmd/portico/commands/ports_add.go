@@ -11,12 +11,16 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/ports"
 )
 
 // NewPortsAddCmd adds a port mapping for a service in an app
 func NewPortsAddCmd() *cobra.Command {
+	var udp bool
+
 	cmd := &cobra.Command{
-		Use:   "add [internal-port] [external-port]",
+		Use:   "add [internal-port] [external-port|auto]",
 		Short: "Expose a service port to the host",
 		Long: `Expose a service port to the host for direct access.
 
@@ -28,6 +32,10 @@ This is useful for:
   - Databases: direct access from external tools
   - Non-HTTP services: APIs, WebSockets, etc.
 
+Host ports are checked against every port allocated by Portico (all apps and
+addon instances) and against ports already in use on the host. Use "auto" as
+external port to get a free port from the Portico range (20000-29999).
+
 Note: To configure HTTP port (used by Caddy), use 'portico set <app-name> http-port <port>'
 
 Examples:
@@ -37,7 +45,10 @@ Examples:
 
   # Expose API port for debugging (bypassing Caddy)
   portico ports my-app api add 3000 8080
-    Access API directly at localhost:8080 (in addition to Caddy proxy)`,
+    Access API directly at localhost:8080 (in addition to Caddy proxy)
+
+  # Expose a UDP game server on a free host port
+  portico ports my-app game add 27015 auto --udp`,
 		Args: cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (ports)
@@ -67,15 +78,9 @@ Examples:
 				return
 			}
 
-			// Validate external port - cannot be 80 or 443 (reserved for Caddy)
-			externalPort, err := strconv.Atoi(external)
-			if err != nil || externalPort <= 0 || externalPort > 65535 {
-				fmt.Println("Error: invalid external port")
-				return
-			}
-			if externalPort == 80 || externalPort == 443 {
-				fmt.Println("Error: ports 80 and 443 are reserved for Caddy proxy")
-				return
+			protocol := "tcp"
+			if udp {
+				protocol = "udp"
 			}
 
 			cfg, err := config.LoadConfig()
@@ -84,6 +89,38 @@ Examples:
 				return
 			}
 
+			// Check the host port against all ports allocated by Portico
+			registry, err := ports.Scan(cfg.AppsDir, cfg.AddonsDir)
+			if err != nil {
+				fmt.Printf("Error loading port registry: %v\n", err)
+				return
+			}
+
+			var externalPort int
+			if external == "auto" {
+				externalPort, err = registry.Allocate(protocol, ports.AutoRangeStart, ports.AutoRangeEnd)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				external = strconv.Itoa(externalPort)
+			} else {
+				// Validate external port - cannot be 80 or 443 (reserved for Caddy)
+				externalPort, err = strconv.Atoi(external)
+				if err != nil || externalPort <= 0 || externalPort > 65535 {
+					fmt.Println("Error: invalid external port")
+					return
+				}
+				if externalPort == 80 || externalPort == 443 {
+					fmt.Println("Error: ports 80 and 443 are reserved for Caddy proxy")
+					return
+				}
+				if err := registry.Check(externalPort, protocol); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+
 			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
 			a, err := am.LoadApp(appName)
 			if err != nil {
@@ -114,6 +151,9 @@ Examples:
 
 					// Add extra port mapping (expose to host)
 					mapping := external + ":" + internal
+					if protocol == "udp" {
+						mapping += "/udp"
+					}
 
 					// ensure unique
 					exists := false
@@ -128,7 +168,7 @@ Examples:
 						return
 					}
 					a.Services[i].ExtraPorts = append(a.Services[i].ExtraPorts, mapping)
-					fmt.Printf("Exposed port: host port %s -> container port %s for service %s in %s\n", external, internal, serviceName, appName)
+					fmt.Printf("Exposed port: host port %s/%s -> container port %s for service %s in %s\n", external, protocol, internal, serviceName, appName)
 					break
 				}
 			}
@@ -174,12 +214,26 @@ Examples:
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "ports add", Summary: fmt.Sprintf("%s:%s/%s for service %s", external, internal, protocol, serviceName),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("ports add %s:%s/%s", external, internal, protocol), false) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 		},
 	}
 
+	cmd.Flags().BoolVar(&udp, "udp", false, "Expose a UDP port (default: TCP)")
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
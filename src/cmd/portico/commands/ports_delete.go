@@ -9,6 +9,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewPortsDeleteCmd deletes a port mapping for a service in an app
@@ -123,14 +124,26 @@ func NewPortsDeleteCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "ports delete", Summary: fmt.Sprintf("%s from service %s", mapping, serviceName),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("ports delete %s", mapping), false) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			fmt.Printf("Deleted mapping %s for service %s in %s\n", mapping, serviceName, appName)
 		},
 	}
 
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
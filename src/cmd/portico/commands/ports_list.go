@@ -7,16 +7,25 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/ports"
 )
 
 // NewPortsListCmd lists port mappings for a service in an app
 func NewPortsListCmd() *cobra.Command {
+	var all bool
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List service port mappings",
-		Long:  "List the primary and extra port mappings for the selected service in an app.",
+		Long:  "List the primary and extra port mappings for the selected service in an app.\n\nUse --all to list the host ports allocated across all apps and addon instances:\n  portico ports list --all",
 		Args:  cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
+			// Global view of allocated host ports
+			if all {
+				listAllPorts()
+				return
+			}
+
 			// Get app-name from parent command (ports)
 			appName, err := getAppNameFromPortsArgs(cmd)
 			if err != nil || appName == "" {
@@ -117,5 +126,31 @@ func NewPortsListCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&all, "all", false, "List host ports allocated across all apps and addon instances")
+
 	return cmd
 }
+
+// listAllPorts prints the host ports allocated by Portico
+func listAllPorts() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	registry, err := ports.Scan(cfg.AppsDir, cfg.AddonsDir)
+	if err != nil {
+		fmt.Printf("Error loading port registry: %v\n", err)
+		return
+	}
+
+	fmt.Printf("%-12s %-10s %-8s %s\n", "HOST PORT", "CONTAINER", "KIND", "OWNER")
+	for _, alloc := range registry.Allocations {
+		container := "-"
+		if alloc.ContainerPort > 0 {
+			container = fmt.Sprintf("%d", alloc.ContainerPort)
+		}
+		fmt.Printf("%-12s %-10s %-8s %s\n", fmt.Sprintf("%d/%s", alloc.HostPort, alloc.Protocol), container, alloc.Kind, alloc.Owner)
+	}
+}
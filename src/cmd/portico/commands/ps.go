@@ -0,0 +1,332 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// OverviewEntry is an app or addon instance in the platform overview
+type OverviewEntry struct {
+	Kind        string   `json:"kind"` // app, static, addon
+	Name        string   `json:"name"`
+	Domain      string   `json:"domain,omitempty"`
+	Running     int      `json:"running"`
+	Desired     int      `json:"desired"`
+	Health      string   `json:"health"` // healthy, starting, degraded, unhealthy, stopped (apps with containers)
+	Images      []string `json:"images,omitempty"`
+	LastDeploy  string   `json:"last_deploy,omitempty"` // RFC3339, newest container creation
+	MemoryUsage uint64   `json:"memory_usage"`          // Bytes
+	Error       string   `json:"error,omitempty"`
+
+	containers []docker.ContainerStatus
+}
+
+// overviewWorkers is how many apps and addon instances are queried at once
+const overviewWorkers = 8
+
+// NewPsCmd creates the platform-wide overview command
+func NewPsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ps",
+		Aliases: []string{"overview"},
+		Short:   "Show the status of all apps and addon instances",
+		Long: `Show every app and addon instance in one table: domain, containers running out
+of the desired replicas, health, image tags, last deploy (newest container
+creation) and memory use.
+
+Examples:
+  portico ps
+  portico overview --json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			entries, err := getOverview(cfg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding JSON: %v\n", err)
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			if len(entries) == 0 {
+				fmt.Println("No applications or addon instances found.")
+				return
+			}
+
+			fmt.Println(overviewHeader())
+			for _, e := range entries {
+				fmt.Println(formatOverviewRow(e))
+			}
+
+			for _, e := range entries {
+				if e.Error != "" {
+					fmt.Printf("\n%s %s: %s\n", e.Kind, e.Name, e.Error)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Print the overview as JSON")
+
+	return cmd
+}
+
+// overviewFormat is the layout of the overview table
+const overviewFormat = "%-24s %-7s %-32s %-8s %-10s %-9s %-12s %s"
+
+// overviewHeader returns the header of the overview table
+func overviewHeader() string {
+	return fmt.Sprintf(overviewFormat, "NAME", "KIND", "DOMAIN", "RUNNING", "HEALTH", "MEMORY", "DEPLOYED", "IMAGES")
+}
+
+// formatOverviewRow returns the row of an entry in the overview table
+func formatOverviewRow(e *OverviewEntry) string {
+	running := "-"
+	memory := "-"
+	if e.Desired > 0 {
+		running = fmt.Sprintf("%d/%d", e.Running, e.Desired)
+	}
+	if e.MemoryUsage > 0 {
+		memory = util.FormatBytes(e.MemoryUsage)
+	}
+	health := e.Health
+	if e.Error != "" {
+		health = "error"
+	}
+	return fmt.Sprintf(overviewFormat, e.Name, e.Kind, orDash(e.Domain), running, orDash(health), memory, formatAge(e.LastDeploy), orDash(strings.Join(e.Images, ", ")))
+}
+
+// getOverview returns the status of all apps and addon instances, sorted by
+// kind and name. Apps and instances are queried concurrently, and memory use is
+// read with a single docker stats call
+func getOverview(cfg *config.Config) ([]*OverviewEntry, error) {
+	am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+	appNames, err := am.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+
+	addonManager := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+	addonConfig, err := addonManager.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading addons config: %w", err)
+	}
+
+	var entries []*OverviewEntry
+	var jobs []func()
+	for _, name := range appNames {
+		e := &OverviewEntry{Kind: "app", Name: name}
+		entries = append(entries, e)
+		// Apps are loaded here: loading may read config.yml, which is not safe concurrently
+		a, err := am.LoadApp(name)
+		if err != nil {
+			e.Error = err.Error()
+			continue
+		}
+		e.Domain = a.Domain
+		jobs = append(jobs, func() { overviewApp(cfg, a, e) })
+	}
+	for name, instance := range addonConfig.Instances {
+		e := &OverviewEntry{Kind: "addon", Name: name, Domain: instance.Domain}
+		entries = append(entries, e)
+		jobs = append(jobs, func() { overviewAddon(cfg, e) })
+	}
+
+	// Run the queries with a bounded number of workers
+	var wg sync.WaitGroup
+	queue := make(chan func())
+	for i := 0; i < overviewWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	// CPU, memory and network use of all containers at once
+	var all []docker.ContainerStatus
+	for _, e := range entries {
+		all = append(all, e.containers...)
+	}
+	dm := docker.NewManager(cfg.Registry.URL)
+	if err := dm.AddContainerStats(all); err == nil {
+		stats := make(map[string]docker.ContainerStatus)
+		for _, c := range all {
+			stats[c.ID] = c
+		}
+		for _, e := range entries {
+			for i, c := range e.containers {
+				e.containers[i] = stats[c.ID]
+				e.MemoryUsage += stats[c.ID].MemoryUsage
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if (entries[i].Kind == "addon") != (entries[j].Kind == "addon") {
+			return entries[j].Kind == "addon"
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// overviewApp fills the overview entry of an app
+func overviewApp(cfg *config.Config, a *app.App, e *OverviewEntry) {
+	status, err := getAppStatus(cfg, a, false)
+	if err != nil {
+		e.Error = err.Error()
+		return
+	}
+	if status.Static {
+		e.Kind = "static"
+		return
+	}
+
+	for _, s := range status.Services {
+		e.Desired += s.Replicas
+		e.Running += s.Running
+		e.Images = appendUnique(e.Images, imageTag(s.Image))
+		e.containers = append(e.containers, s.Containers...)
+	}
+	summarizeOverview(e)
+}
+
+// overviewAddon fills the overview entry of an addon instance
+func overviewAddon(cfg *config.Config, e *OverviewEntry) {
+	instanceDir := filepath.Join(cfg.AddonsDir, "instances", e.Name)
+	dm := docker.NewManager(cfg.Registry.URL)
+
+	compose, err := dm.LoadComposeFile(instanceDir)
+	if err != nil {
+		e.Error = err.Error()
+		return
+	}
+	for _, svc := range compose.Services {
+		e.Desired++
+		if svcMap, ok := svc.(map[string]interface{}); ok {
+			if image, ok := svcMap["image"].(string); ok {
+				e.Images = appendUnique(e.Images, imageTag(image))
+			}
+		}
+	}
+
+	containers, err := dm.GetContainerStatus(instanceDir)
+	if err != nil {
+		e.Error = err.Error()
+		return
+	}
+	for _, c := range containers {
+		if c.Running() {
+			e.Running++
+		}
+	}
+	e.containers = containers
+	summarizeOverview(e)
+}
+
+// summarizeOverview sets the health and last deploy of an entry from its containers
+func summarizeOverview(e *OverviewEntry) {
+	sort.Strings(e.Images)
+	e.Health = "healthy"
+	var lastDeploy time.Time
+	for _, c := range e.containers {
+		// docker trims trailing zeros of the nanoseconds: compare times, not strings
+		if created, err := time.Parse(time.RFC3339Nano, c.Created); err == nil && created.After(lastDeploy) {
+			lastDeploy = created
+			e.LastDeploy = c.Created
+		}
+		switch {
+		case c.Health == "unhealthy" || c.State == "restarting":
+			e.Health = "unhealthy"
+		case c.Health == "starting" && e.Health == "healthy":
+			e.Health = "starting"
+		}
+	}
+	if e.Health != "unhealthy" && e.Running < e.Desired {
+		e.Health = "degraded"
+		if e.Running == 0 {
+			e.Health = "stopped"
+		}
+	}
+}
+
+// imageTag shortens an image reference to name:tag (without registry and path)
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	return image
+}
+
+// appendUnique appends a value to a slice if it is not in it
+func appendUnique(slice []string, value string) []string {
+	for _, v := range slice {
+		if v == value {
+			return slice
+		}
+	}
+	return append(slice, value)
+}
+
+// orDash returns value, or "-" when it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// formatAge formats an RFC3339 time as the time elapsed since then (e.g. 3h ago)
+func formatAge(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// resourcesCommands lists the subcommands of resources
+var resourcesCommands = map[string]bool{
+	"set":   true,
+	"unset": true,
+	"list":  true,
+}
+
+// NewResourcesCmd is the root command for CPU and memory limits: resources [app-name] ...
+func NewResourcesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resources [app-name] [service-name]",
+		Short: "Manage CPU, memory and process limits",
+		Long: `Manage the CPU, memory and process limits of an application's services, and the
+resources reserved for them. Limits are written to docker-compose.yml
+(deploy.resources) and applied with a redeploy.
+
+A container that reaches its memory limit is OOM-killed instead of the host.
+Portico warns when the memory limits of all apps add up to more than the host memory.`,
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true, // Flags belong to the subcommands
+		Run: func(parentCmd *cobra.Command, args []string) {
+			// Parse os.Args directly to find subcommand
+			allArgs := os.Args[1:] // Skip program name
+
+			var subcommandName string
+			var subcommandIndex int
+
+			// Find "resources" in arguments
+			resourcesIndex := -1
+			for i, arg := range allArgs {
+				if arg == "resources" {
+					resourcesIndex = i
+					break
+				}
+			}
+
+			if resourcesIndex == -1 {
+				_ = parentCmd.Help()
+				return
+			}
+
+			// Find subcommand after "resources"
+			for i := resourcesIndex + 1; i < len(allArgs); i++ {
+				if resourcesCommands[allArgs[i]] {
+					subcommandName = allArgs[i]
+					subcommandIndex = i
+					break
+				}
+			}
+
+			// If no subcommand found, show help
+			if subcommandName == "" {
+				_ = parentCmd.Help()
+				return
+			}
+
+			// Find and execute subcommand
+			for _, subCmd := range parentCmd.Commands() {
+				if subCmd.Name() == subcommandName {
+					// Get arguments for subcommand (everything after subcommand name)
+					subcommandArgs := allArgs[subcommandIndex+1:]
+
+					// Parse flags manually for the subcommand
+					if err := subCmd.ParseFlags(subcommandArgs); err != nil {
+						fmt.Printf("Error parsing flags: %v\n", err)
+						_ = subCmd.Help()
+						return
+					}
+
+					// Get non-flag arguments
+					nonFlagArgs := subCmd.Flags().Args()
+
+					// Call the subcommand's Run function directly
+					if subCmd.Run != nil {
+						subCmd.Run(subCmd, nonFlagArgs)
+					} else {
+						_ = subCmd.Help()
+					}
+					return
+				}
+			}
+
+			// Subcommand not found
+			_ = parentCmd.Help()
+		},
+	}
+	return cmd
+}
+
+// getAppNameFromResourcesArgs extracts app-name from resources command arguments
+// It parses os.Args to find the app-name after "resources"
+func getAppNameFromResourcesArgs() string {
+	args := os.Args[1:] // Skip program name
+	for i, arg := range args {
+		if arg == "resources" {
+			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if resourcesCommands[args[j]] {
+					break
+				}
+				if args[j][0] == '-' {
+					continue
+				}
+				return args[j]
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// getServiceNameFromResourcesArgs extracts service-name from resources command arguments
+// It parses os.Args to find the service-name after "resources" and app-name
+func getServiceNameFromResourcesArgs() string {
+	args := os.Args[1:] // Skip program name
+	for i, arg := range args {
+		if arg == "resources" {
+			appNameFound := false
+			for j := i + 1; j < len(args); j++ {
+				if resourcesCommands[args[j]] {
+					break
+				}
+				if args[j][0] == '-' {
+					continue
+				}
+				if !appNameFound {
+					appNameFound = true
+					continue
+				}
+				return args[j]
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// resolveResourcesService returns the service given to resources, or the only
+// service of the app. Prints an error and returns "" if it can't
+func resolveResourcesService(a *app.App, serviceName, usage string) string {
+	if serviceName == "" {
+		if len(a.Services) == 1 {
+			return a.Services[0].Name
+		}
+		var serviceNames []string
+		for _, s := range a.Services {
+			serviceNames = append(serviceNames, s.Name)
+		}
+		fmt.Printf("Error: app %s has %d services. Please specify service name\n", a.Name, len(a.Services))
+		fmt.Printf("Available services: %v\n", serviceNames)
+		fmt.Println("Usage: " + usage)
+		return ""
+	}
+	for _, s := range a.Services {
+		if s.Name == serviceName {
+			return serviceName
+		}
+	}
+	fmt.Printf("Error: service %s not found in app %s\n", serviceName, a.Name)
+	return ""
+}
+
+// saveServiceResources stores the resources of a service in docker-compose.yml
+// and redeploys the app, unless the change is staged. Returns true if it was staged
+func saveServiceResources(cmd *cobra.Command, cfg *config.Config, a *app.App, serviceName string, resources docker.ResourcesConfig, event journal.Event) (bool, error) {
+	appDir := filepath.Join(cfg.AppsDir, a.Name)
+	dm := docker.NewManager(cfg.Registry.URL)
+
+	err := dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+		if resources.IsEmpty() {
+			delete(metadata.Resources, serviceName)
+			if len(metadata.Resources) == 0 {
+				metadata.Resources = nil
+			}
+			return
+		}
+		if metadata.Resources == nil {
+			metadata.Resources = make(map[string]docker.ResourcesConfig)
+		}
+		metadata.Resources[serviceName] = resources
+	})
+	if err != nil {
+		return false, fmt.Errorf("error updating app metadata: %w", err)
+	}
+
+	var dockerServices []docker.Service
+	for _, s := range a.Services {
+		replicas := s.Replicas
+		if replicas == 0 {
+			replicas = 1 // Default to 1 if not specified
+		}
+		dockerServices = append(dockerServices, docker.Service{
+			Name:        s.Name,
+			Image:       s.Image,
+			Port:        s.Port,
+			ExtraPorts:  s.ExtraPorts,
+			Environment: s.Environment,
+			Volumes:     s.Volumes,
+			Secrets:     s.Secrets,
+			DependsOn:   s.DependsOn,
+			Replicas:    replicas,
+		})
+	}
+	metadata := &docker.PorticoMetadata{
+		Domain: a.Domain,
+		Port:   a.Port,
+	}
+	if err := dm.GenerateDockerCompose(appDir, dockerServices, metadata); err != nil {
+		return false, fmt.Errorf("error generating docker compose: %w", err)
+	}
+
+	// Stage instead of redeploying (--no-restart or "config stage")
+	if stageConfigChange(cmd, cfg, a.Name, serviceName, event.Action+" "+serviceName+": "+resources.String(), false) {
+		recordChange(event, true, nil)
+		return true, nil
+	}
+	// docker compose up recreates the containers whose limits changed
+	if err := dm.DeployApp(appDir, dockerServices); err != nil {
+		recordChange(event, false, err)
+		return false, fmt.Errorf("error deploying app: %w", err)
+	}
+	recordChange(event, false, nil)
+	return false, nil
+}
+
+// warnMemoryOvercommit prints a warning when the memory limits of all apps
+// add up to more than the memory of the host
+func warnMemoryOvercommit(cfg *config.Config) {
+	dm := docker.NewManager(cfg.Registry.URL)
+	commitments, err := dm.CommittedMemory(cfg.AppsDir)
+	if err != nil || len(commitments) == 0 {
+		return
+	}
+	hostMemory, err := dm.HostMemory()
+	if err != nil {
+		return
+	}
+
+	var total uint64
+	for _, c := range commitments {
+		total += c.Limit * uint64(c.Replicas)
+	}
+	if total <= hostMemory {
+		return
+	}
+
+	fmt.Printf("⚠️  Memory overcommit: the memory limits of all apps add up to %s, the host has %s\n", util.FormatBytes(total), util.FormatBytes(hostMemory))
+	sort.Slice(commitments, func(i, j int) bool {
+		return commitments[i].Limit*uint64(commitments[i].Replicas) > commitments[j].Limit*uint64(commitments[j].Replicas)
+	})
+	for i, c := range commitments {
+		if i == 5 {
+			fmt.Printf("   ... and %d more\n", len(commitments)-i)
+			break
+		}
+		fmt.Printf("   %s/%s: %s x %d\n", c.App, c.Service, util.FormatBytes(c.Limit), c.Replicas)
+	}
+	fmt.Println("   If they all reach their limit, the host runs out of memory. Lower some limits or add memory.")
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+)
+
+// NewResourcesListCmd lists the limits of the services of an app
+func NewResourcesListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the CPU, memory and process limits of the services",
+		Long:  "List the limits and reservations of every service of an app (or of one service).\n\nActual usage is shown by 'portico apps status [app-name]'.",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			appName := getAppNameFromResourcesArgs()
+			if appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico resources [app-name] [service-name] list")
+				return
+			}
+			serviceName := getServiceNameFromResourcesArgs()
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error reading app metadata: %v\n", err)
+				return
+			}
+
+			fmt.Printf("%-20s %-8s %-10s %-8s %-12s %-12s %s\n", "SERVICE", "CPUS", "MEMORY", "PIDS", "RES. CPUS", "RES. MEMORY", "REPLICAS")
+			found := false
+			for _, s := range a.Services {
+				if serviceName != "" && s.Name != serviceName {
+					continue
+				}
+				found = true
+				r := metadata.Resources[s.Name]
+				pids := "-"
+				if r.Pids > 0 {
+					pids = fmt.Sprintf("%d", r.Pids)
+				}
+				replicas := s.Replicas
+				if replicas == 0 {
+					replicas = 1
+				}
+				fmt.Printf("%-20s %-8s %-10s %-8s %-12s %-12s %d\n", s.Name, orDash(r.CPUs), orDash(r.Memory), pids, orDash(r.CPUsReservation), orDash(r.MemoryReservation), replicas)
+			}
+			if !found {
+				fmt.Printf("Error: service %s not found in app %s\n", serviceName, appName)
+				return
+			}
+			warnMemoryOvercommit(cfg)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// minMemory is the smallest memory limit docker accepts
+const minMemory = 6 << 20
+
+// NewResourcesSetCmd sets the CPU, memory and process limits of a service
+func NewResourcesSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the CPU, memory and process limits of a service",
+		Long: `Set the CPU, memory and process limits of a service, and the resources reserved
+for it. Only the given flags change; use 0 to remove a single limit, or
+'portico resources [app-name] [service-name] unset' to remove them all.
+
+Limits apply to each replica. The service is redeployed (use --no-restart to
+deploy later with 'config commit').
+
+Examples:
+  portico resources my-app web set --memory 512m --cpus 0.5
+  portico resources my-app worker set --memory 1g --pids 200
+  portico resources my-app web set --memory-reservation 256m --cpus-reservation 0.25
+  portico resources my-app web set --cpus 0`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			usage := "portico resources [app-name] [service-name] set [--memory 512m] [--cpus 0.5] [--pids 200]"
+			appName := getAppNameFromResourcesArgs()
+			if appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: " + usage)
+				return
+			}
+			if !cmd.Flags().Changed("memory") && !cmd.Flags().Changed("cpus") && !cmd.Flags().Changed("pids") &&
+				!cmd.Flags().Changed("memory-reservation") && !cmd.Flags().Changed("cpus-reservation") {
+				fmt.Println("Error: nothing to set")
+				fmt.Println("Usage: " + usage)
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+			serviceName := resolveResourcesService(a, getServiceNameFromResourcesArgs(), usage)
+			if serviceName == "" {
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error reading app metadata: %v\n", err)
+				return
+			}
+			previous := metadata.Resources[serviceName]
+			resources := previous
+
+			// Memory sizes
+			for _, flag := range []struct {
+				name  string
+				value *string
+			}{
+				{"memory", &resources.Memory},
+				{"memory-reservation", &resources.MemoryReservation},
+			} {
+				if !cmd.Flags().Changed(flag.name) {
+					continue
+				}
+				value, _ := cmd.Flags().GetString(flag.name)
+				value = strings.ToLower(strings.TrimSpace(value))
+				size, err := util.ParseBytes(value, util.BinarySizes)
+				if err != nil {
+					fmt.Printf("Error: invalid --%s: %v\n", flag.name, err)
+					return
+				}
+				if size == 0 {
+					*flag.value = ""
+					continue
+				}
+				if size < minMemory {
+					fmt.Printf("Error: --%s must be at least 6m\n", flag.name)
+					return
+				}
+				*flag.value = value
+			}
+
+			// CPUs
+			for _, flag := range []struct {
+				name  string
+				value *string
+			}{
+				{"cpus", &resources.CPUs},
+				{"cpus-reservation", &resources.CPUsReservation},
+			} {
+				if !cmd.Flags().Changed(flag.name) {
+					continue
+				}
+				value, _ := cmd.Flags().GetString(flag.name)
+				value = strings.TrimSpace(value)
+				cpus, err := strconv.ParseFloat(value, 64)
+				if err != nil || cpus < 0 {
+					fmt.Printf("Error: invalid --%s %q (e.g. 0.5, 2)\n", flag.name, value)
+					return
+				}
+				if cpus == 0 {
+					*flag.value = ""
+					continue
+				}
+				if cpus < 0.01 {
+					fmt.Printf("Error: --%s must be at least 0.01\n", flag.name)
+					return
+				}
+				if cpus > float64(runtime.NumCPU()) {
+					fmt.Printf("Warning: --%s %s is more than the %d CPUs of this host\n", flag.name, value, runtime.NumCPU())
+				}
+				*flag.value = strconv.FormatFloat(cpus, 'f', -1, 64)
+			}
+
+			if cmd.Flags().Changed("pids") {
+				pids, _ := cmd.Flags().GetInt("pids")
+				if pids < 0 {
+					fmt.Println("Error: --pids must be positive (0 removes the limit)")
+					return
+				}
+				resources.Pids = pids
+			}
+
+			// Reservations can't exceed the limits
+			if resources.Memory != "" && resources.MemoryReservation != "" {
+				limit, _ := util.ParseBytes(resources.Memory, util.BinarySizes)
+				reservation, _ := util.ParseBytes(resources.MemoryReservation, util.BinarySizes)
+				if reservation > limit {
+					fmt.Printf("Error: memory reservation %s is more than the memory limit %s\n", resources.MemoryReservation, resources.Memory)
+					return
+				}
+			}
+			if resources.CPUs != "" && resources.CPUsReservation != "" {
+				limit, _ := strconv.ParseFloat(resources.CPUs, 64)
+				reservation, _ := strconv.ParseFloat(resources.CPUsReservation, 64)
+				if reservation > limit {
+					fmt.Printf("Error: CPU reservation %s is more than the CPU limit %s\n", resources.CPUsReservation, resources.CPUs)
+					return
+				}
+			}
+
+			event := journal.Event{
+				App: appName, Action: "resources set", Summary: serviceName, Before: previous.String(), After: resources.String(),
+			}
+			staged, err := saveServiceResources(cmd, cfg, a, serviceName, resources, event)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if staged {
+				fmt.Printf("Resources of service %s in %s staged: %s\n", serviceName, appName, resources.String())
+			} else {
+				fmt.Printf("✅ Resources of service %s in %s: %s\n", serviceName, appName, resources.String())
+			}
+			warnMemoryOvercommit(cfg)
+		},
+	}
+
+	cmd.Flags().String("memory", "", "Memory limit per replica (e.g. 512m, 1g; 0 removes it)")
+	cmd.Flags().String("cpus", "", "CPU limit per replica (e.g. 0.5; 0 removes it)")
+	cmd.Flags().Int("pids", 0, "Max number of processes per replica (0 removes it)")
+	cmd.Flags().String("memory-reservation", "", "Memory reserved per replica (soft limit)")
+	cmd.Flags().String("cpus-reservation", "", "CPUs reserved per replica")
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
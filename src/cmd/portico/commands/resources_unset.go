@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewResourcesUnsetCmd removes the limits of a service
+func NewResourcesUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset",
+		Short: "Remove the CPU, memory and process limits of a service",
+		Long: `Remove every limit and reservation of a service and redeploy it.
+
+Examples:
+  portico resources my-app web unset`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			usage := "portico resources [app-name] [service-name] unset"
+			appName := getAppNameFromResourcesArgs()
+			if appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: " + usage)
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+			serviceName := resolveResourcesService(a, getServiceNameFromResourcesArgs(), usage)
+			if serviceName == "" {
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+			if err != nil {
+				fmt.Printf("Error reading app metadata: %v\n", err)
+				return
+			}
+			previous, ok := metadata.Resources[serviceName]
+			if !ok || previous.IsEmpty() {
+				fmt.Printf("Service %s in %s has no limits\n", serviceName, appName)
+				return
+			}
+
+			event := journal.Event{
+				App: appName, Action: "resources unset", Summary: serviceName, Before: previous.String(), After: "unlimited",
+			}
+			staged, err := saveServiceResources(cmd, cfg, a, serviceName, docker.ResourcesConfig{}, event)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if staged {
+				fmt.Printf("Limits of service %s in %s removed (staged)\n", serviceName, appName)
+				return
+			}
+			fmt.Printf("✅ Limits of service %s in %s removed\n", serviceName, appName)
+		},
+	}
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
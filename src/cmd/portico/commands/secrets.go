@@ -2,28 +2,51 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
+// secretsCommands lists the subcommands of secrets
+var secretsCommands = map[string]bool{
+	"add":           true,
+	"del":           true,
+	"delete":        true,
+	"edit":          true,
+	"list":          true,
+	"migrate":       true,
+	"rekey":         true,
+	"materialize":   true,
+	"history":       true,
+	"rollback":      true,
+	"global":        true,
+	"link-global":   true,
+	"unlink-global": true,
+	"audit":         true,
+	"rotate":        true,
+	"expire":        true,
+}
+
 // NewSecretsCmd is the root command for secrets: secrets [app-name] ...
 func NewSecretsCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "secrets [app-name] [service-name]",
-		Short: "Manage secrets",
-		Long:  "Manage secrets (files in env/ directory) for application services.",
-		Args:  cobra.ArbitraryArgs,
+		Use:                "secrets [app-name] [service-name]",
+		Short:              "Manage secrets",
+		Long:               "Manage secrets (files in env/ directory) for application services.",
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true, // Flags belong to the subcommands
 		Run: func(parentCmd *cobra.Command, args []string) {
 			// Parse os.Args directly to find subcommand
 			allArgs := os.Args[1:] // Skip program name
-			knownCommands := map[string]bool{
-				"add":    true,
-				"del":    true,
-				"delete": true,
-				"edit":   true,
-				"list":   true,
-			}
 
 			var subcommandName string
 			var subcommandIndex int
@@ -44,7 +67,7 @@ func NewSecretsCmd() *cobra.Command {
 
 			// Find subcommand after "secrets"
 			for i := secretsIndex + 1; i < len(allArgs); i++ {
-				if knownCommands[allArgs[i]] {
+				if secretsCommands[allArgs[i]] {
 					subcommandName = allArgs[i]
 					subcommandIndex = i
 					break
@@ -104,14 +127,14 @@ func getAppNameFromSecretsArgs(cmd *cobra.Command) (string, error) {
 		if arg == "secrets" {
 			// Next non-flag argument should be app-name
 			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if secretsCommands[args[j]] {
+					break
+				}
 				// Skip if it's a flag
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
-				if args[j] == "add" || args[j] == "del" || args[j] == "delete" || args[j] == "edit" || args[j] == "list" {
-					continue
-				}
 				// This should be the app-name
 				return args[j], nil
 			}
@@ -131,14 +154,14 @@ func getServiceNameFromSecretsArgs(cmd *cobra.Command) (string, error) {
 			// Find app-name first
 			appNameFound := false
 			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if secretsCommands[args[j]] {
+					break
+				}
 				// Skip if it's a flag
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
-				if args[j] == "add" || args[j] == "del" || args[j] == "delete" || args[j] == "edit" || args[j] == "list" {
-					continue
-				}
 				if !appNameFound {
 					appNameFound = true
 					continue
@@ -151,3 +174,90 @@ func getServiceNameFromSecretsArgs(cmd *cobra.Command) (string, error) {
 	}
 	return "", nil
 }
+
+// secretDirs returns the secrets directories of all apps (env/), addon
+// instances (secrets/) and global secrets on this server
+func secretDirs(cfg *config.Config) ([]string, error) {
+	var dirs []string
+
+	am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+	appNames, err := am.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+	for _, appName := range appNames {
+		dirs = append(dirs, filepath.Join(cfg.AppsDir, appName, "env"))
+	}
+
+	addonManager := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+	addonConfig, err := addonManager.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading addons config: %w", err)
+	}
+	for instanceName := range addonConfig.Instances {
+		dirs = append(dirs, filepath.Join(cfg.AddonsDir, "instances", instanceName, "secrets"))
+	}
+
+	dirs = append(dirs, secrets.GlobalDir(cfg.PorticoHome))
+
+	return dirs, nil
+}
+
+// addSecretValueFlags adds the flags that give the value of a secret instead
+// of the value argument
+func addSecretValueFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from-file", "", "Read the value from a file (kept byte for byte, e.g. TLS keys or JSON credentials)")
+	cmd.Flags().Bool("stdin", false, "Read the value from stdin (kept byte for byte)")
+	cmd.Flags().Bool("generate", false, "Generate a random value")
+	cmd.Flags().Int("length", defaultSecretLength, "Length of the generated value")
+	cmd.Flags().String("charset", "alnum", "Charset of the generated value: "+strings.Join(util.CharsetNames(), ", "))
+}
+
+// readSecretValue returns the value of a secret from the value argument
+// (args[1]), --from-file, --stdin or --generate. The value is not trimmed
+func readSecretValue(cmd *cobra.Command, args []string) ([]byte, error) {
+	fromFile, _ := cmd.Flags().GetString("from-file")
+	fromStdin, _ := cmd.Flags().GetBool("stdin")
+	generate, _ := cmd.Flags().GetBool("generate")
+
+	sources := 0
+	if len(args) > 1 {
+		sources++
+	}
+	for _, set := range []bool{fromFile != "", fromStdin, generate} {
+		if set {
+			sources++
+		}
+	}
+	if sources == 0 {
+		return nil, fmt.Errorf("a value is required (argument, --from-file, --stdin or --generate)")
+	}
+	if sources > 1 {
+		return nil, fmt.Errorf("give only one of: value argument, --from-file, --stdin, --generate")
+	}
+
+	switch {
+	case fromFile != "":
+		data, err := os.ReadFile(fromFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", fromFile, err)
+		}
+		return data, nil
+	case fromStdin:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("error reading stdin: %w", err)
+		}
+		return data, nil
+	case generate:
+		length, _ := cmd.Flags().GetInt("length")
+		charset, _ := cmd.Flags().GetString("charset")
+		value, err := util.RandomString(length, charset)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(value), nil
+	default:
+		return []byte(args[1]), nil
+	}
+}
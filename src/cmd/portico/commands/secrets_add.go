@@ -11,6 +11,8 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
 	"github.com/maxvegac/portico/src/internal/util"
 )
 
@@ -19,8 +21,8 @@ func NewSecretsAddCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add [secret-name] [value]",
 		Short: "Add a secret",
-		Long:  "Add a secret file for a service in the given app.\n\nExamples:\n  portico secrets my-app add database_password mypassword123\n    Adds database_password secret (uses default service if only one exists)\n\n  portico secrets my-app api add api_key sk-abc123\n    Adds api_key secret for service 'api'",
-		Args:  cobra.ExactArgs(2),
+		Long:  "Add a secret file for a service in the given app.\n\nExamples:\n  portico secrets my-app add database_password mypassword123\n    Adds database_password secret (uses default service if only one exists)\n\n  portico secrets my-app api add api_key sk-abc123\n    Adds api_key secret for service 'api'\n\n  portico secrets my-app add tls_key --from-file ./server.key\n  gcloud iam service-accounts keys create - --iam-account sa@proj.iam.gserviceaccount.com | portico secrets my-app add gcp_credentials --stdin\n  portico secrets my-app add session_secret --generate --length 64 --charset symbols\n\nValues from --from-file and --stdin are stored byte for byte (use 'printf' or 'echo -n' to avoid a trailing newline).",
+		Args:  cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (secrets)
 			appName, err := getAppNameFromSecretsArgs(cmd)
@@ -34,19 +36,30 @@ func NewSecretsAddCmd() *cobra.Command {
 			serviceName, _ := getServiceNameFromSecretsArgs(cmd)
 
 			secretName := strings.TrimSpace(args[0])
-			value := strings.TrimSpace(args[1])
 
 			if secretName == "" {
 				fmt.Println("Error: secret-name is required")
 				return
 			}
 
+			value, err := readSecretValue(cmd, args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				fmt.Println("Usage: portico secrets [app-name] [service-name] add [secret-name] [value | --from-file path | --stdin | --generate]")
+				return
+			}
+
 			cfg, err := config.LoadConfig()
 			if err != nil {
 				fmt.Printf("Error loading config: %v\n", err)
 				return
 			}
 
+			if isLinkedGlobalSecret(cfg, appName, secretName) {
+				fmt.Printf("Secret %s is a global secret linked to %s. Use 'portico secrets global add' or 'unlink-global'\n", secretName, appName)
+				return
+			}
+
 			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
 			a, err := am.LoadApp(appName)
 			if err != nil {
@@ -102,15 +115,15 @@ func NewSecretsAddCmd() *cobra.Command {
 			}
 
 			// Create secret file
-			secretPath := filepath.Join(envDir, secretName)
-			if err := os.WriteFile(secretPath, []byte(value), 0o600); err != nil {
+			// Encrypted with the host master key when secrets are encrypted at rest
+			// Previous values are kept as versions in .secrets-history
+			store := secrets.NewStore(cfg.SecretsKey)
+			version, err := store.WriteVersion(envDir, secretName, value, util.CurrentActor(cfg.PorticoHome), "add")
+			if err != nil {
 				fmt.Printf("Error creating secret file: %v\n", err)
 				return
 			}
 
-			// Fix file ownership if running as root
-			_ = util.FixFileOwnership(secretPath)
-
 			// Add secret to service
 			if a.Services[serviceIndex].Secrets == nil {
 				a.Services[serviceIndex].Secrets = []string{}
@@ -153,10 +166,20 @@ func NewSecretsAddCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "secrets add", Summary: fmt.Sprintf("%s for service %s", secretName, serviceName), After: fmt.Sprintf("v%d", version),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("secrets add %s", secretName), true) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Restart the service to apply new secret
 			if err := dm.RestartService(appDir, serviceName); err != nil {
@@ -167,5 +190,9 @@ func NewSecretsAddCmd() *cobra.Command {
 		},
 	}
 
+	addSecretValueFlags(cmd)
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
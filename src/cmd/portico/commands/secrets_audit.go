@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// auditSecret is a secret value an app can read, for duplicate detection
+type auditSecret struct {
+	name   string // Secret name
+	source string // Where it lives: app, global, addon <instance>
+	value  string
+}
+
+// NewSecretsAuditCmd checks how an app uses its secrets
+func NewSecretsAuditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit",
+		Short: "Check the secrets of an app",
+		Long: `Check the secrets of an app and report:
+  - secret files in env/ that no service uses
+  - services using secrets whose file is missing (app or global)
+  - credentials duplicated as plain environment variables: variables with the
+    value of a secret of the app, of a linked global secret or of a linked addon
+    (e.g. the password 'addons link' copies into POSTGRES_PASSWORD)
+
+Values are never printed.
+
+Example:
+  portico secrets my-app audit`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets)
+			appName, err := getAppNameFromSecretsArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico secrets [app-name] audit")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			envDir := filepath.Join(appDir, "env")
+			globalDir := secrets.GlobalDir(cfg.PorticoHome)
+			store := secrets.NewStore(cfg.SecretsKey)
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			global := make(map[string]bool)
+			if metadata, err := dm.GetPorticoMetadata(appDir); err == nil {
+				for _, name := range metadata.Global {
+					global[name] = true
+				}
+			}
+
+			used := make(map[string]bool)
+			for _, s := range a.Services {
+				for _, name := range s.Secrets {
+					used[name] = true
+				}
+			}
+
+			// Secret files no service uses (global copies belong to the links)
+			files, err := secrets.ListFiles(envDir)
+			if err != nil {
+				fmt.Printf("Error listing %s: %v\n", envDir, err)
+				return
+			}
+			var unused []string
+			var known []auditSecret
+			for _, path := range files {
+				name := filepath.Base(path)
+				if global[name] {
+					continue
+				}
+				if !used[name] {
+					unused = append(unused, name)
+				}
+				if value, err := store.ReadFile(path); err == nil {
+					known = append(known, auditSecret{name: name, source: "app", value: string(value)})
+				}
+			}
+
+			// Services using secrets whose file is missing
+			var missing []string
+			for _, s := range a.Services {
+				for _, name := range s.Secrets {
+					path := filepath.Join(envDir, name)
+					kind := "secret"
+					if global[name] {
+						path = filepath.Join(globalDir, name)
+						kind = "global secret"
+					}
+					if _, err := os.Stat(path); err != nil {
+						missing = append(missing, fmt.Sprintf("service %s uses %s %s, but %s doesn't exist", s.Name, kind, name, path))
+					}
+				}
+			}
+
+			// Values the app can read as secrets
+			for name := range global {
+				if value, err := store.ReadFile(filepath.Join(globalDir, name)); err == nil {
+					known = append(known, auditSecret{name: name, source: "global", value: string(value)})
+				}
+			}
+			addonManager := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+			if addonConfig, err := addonManager.LoadConfig(); err == nil {
+				for instanceName, instance := range addonConfig.Instances {
+					linked := instance.App == appName
+					for _, linkedApp := range instance.Apps {
+						if linkedApp == appName {
+							linked = true
+						}
+					}
+					if !linked {
+						continue
+					}
+					secretFiles, _ := secrets.ListFiles(filepath.Join(cfg.AddonsDir, "instances", instanceName, "secrets"))
+					for _, path := range secretFiles {
+						if value, err := store.ReadFile(path); err == nil {
+							known = append(known, auditSecret{name: filepath.Base(path), source: "addon " + instanceName, value: string(value)})
+						}
+					}
+				}
+			}
+
+			// Credentials duplicated as plain environment variables
+			var duplicated []string
+			checkEnv := func(where string, env map[string]string) {
+				for _, key := range sortedKeys(env) {
+					value := env[key]
+					if value == "" {
+						continue
+					}
+					for _, secret := range known {
+						if secret.value != value {
+							continue
+						}
+						// User and database names are not credentials
+						if !util.IsSensitiveKey(key) && !util.IsSensitiveKey(secret.name) {
+							continue
+						}
+						duplicated = append(duplicated, fmt.Sprintf("%s: %s has the value of secret %s (%s)", where, key, secret.name, secret.source))
+					}
+				}
+			}
+			checkEnv("app-level", a.Environment)
+			for _, s := range a.Services {
+				checkEnv("service "+s.Name, s.Environment)
+			}
+
+			fmt.Printf("Secrets audit for %s:\n", appName)
+			report := func(title string, findings []string) {
+				if len(findings) == 0 {
+					fmt.Printf("\n✓ %s: none\n", title)
+					return
+				}
+				sort.Strings(findings)
+				fmt.Printf("\n✗ %s: %d\n", title, len(findings))
+				for _, finding := range findings {
+					fmt.Printf("  - %s\n", finding)
+				}
+			}
+			report("Secrets used by no service", unused)
+			report("Missing secret files", missing)
+			report("Credentials duplicated as plain env", duplicated)
+
+			if len(duplicated) > 0 {
+				fmt.Println("\nRead duplicated credentials from /run/secrets (e.g. POSTGRES_PASSWORD_FILE) and remove the variables with 'portico env <app> del <KEY>'")
+			}
+		},
+	}
+}
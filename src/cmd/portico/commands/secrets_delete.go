@@ -11,6 +11,9 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
 )
 
 // NewSecretsDeleteCmd deletes a secret file for a service in an app
@@ -46,6 +49,11 @@ func NewSecretsDeleteCmd() *cobra.Command {
 				return
 			}
 
+			if isLinkedGlobalSecret(cfg, appName, secretName) {
+				fmt.Printf("Secret %s is a global secret linked to %s. Use 'portico secrets global del' or 'unlink-global'\n", secretName, appName)
+				return
+			}
+
 			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
 			a, err := am.LoadApp(appName)
 			if err != nil {
@@ -99,10 +107,15 @@ func NewSecretsDeleteCmd() *cobra.Command {
 
 			// Delete secret file
 			appDir := filepath.Join(cfg.AppsDir, appName)
-			secretPath := filepath.Join(appDir, "env", secretName)
-			if err := os.Remove(secretPath); err != nil && !os.IsNotExist(err) {
+			envDir := filepath.Join(appDir, "env")
+			if err := os.Remove(filepath.Join(envDir, secretName)); err != nil && !os.IsNotExist(err) {
 				fmt.Printf("Warning: could not delete secret file: %v\n", err)
 			}
+			// The history keeps the last value, so the secret can be rolled back
+			store := secrets.NewStore(cfg.SecretsKey)
+			if _, err := store.RecordDelete(envDir, secretName, util.CurrentActor(cfg.PorticoHome)); err != nil {
+				fmt.Printf("Warning: could not record deletion in secret history: %v\n", err)
+			}
 
 			if err := am.SaveApp(a); err != nil {
 				fmt.Printf("Error saving app: %v\n", err)
@@ -140,10 +153,20 @@ func NewSecretsDeleteCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "secrets del", Summary: fmt.Sprintf("%s from service %s", secretName, serviceName),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("secrets del %s", secretName), false) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Restart the service to apply removed secret
 			if err := dm.RestartService(appDir, serviceName); err != nil {
@@ -154,5 +177,7 @@ func NewSecretsDeleteCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
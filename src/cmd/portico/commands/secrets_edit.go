@@ -11,6 +11,8 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
 	"github.com/maxvegac/portico/src/internal/util"
 )
 
@@ -19,8 +21,8 @@ func NewSecretsEditCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "edit [secret-name] [value]",
 		Short: "Edit a secret",
-		Long:  "Edit a secret file for a service in the given app.\n\nExamples:\n  portico secrets my-app edit database_password newpassword123\n    Updates database_password secret (uses default service if only one exists)\n\n  portico secrets my-app api edit api_key sk-newkey123\n    Updates api_key secret for service 'api'",
-		Args:  cobra.ExactArgs(2),
+		Long:  "Edit a secret file for a service in the given app.\n\nExamples:\n  portico secrets my-app edit database_password newpassword123\n    Updates database_password secret (uses default service if only one exists)\n\n  portico secrets my-app api edit api_key sk-newkey123\n    Updates api_key secret for service 'api'\n\n  portico secrets my-app edit tls_key --from-file ./server.key\n  portico secrets my-app edit session_secret --generate\n\nValues from --from-file and --stdin are stored byte for byte.",
+		Args:  cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Get app-name from parent command (secrets)
 			appName, err := getAppNameFromSecretsArgs(cmd)
@@ -34,19 +36,30 @@ func NewSecretsEditCmd() *cobra.Command {
 			serviceName, _ := getServiceNameFromSecretsArgs(cmd)
 
 			secretName := strings.TrimSpace(args[0])
-			value := strings.TrimSpace(args[1])
 
 			if secretName == "" {
 				fmt.Println("Error: secret-name is required")
 				return
 			}
 
+			value, err := readSecretValue(cmd, args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				fmt.Println("Usage: portico secrets [app-name] [service-name] edit [secret-name] [value | --from-file path | --stdin | --generate]")
+				return
+			}
+
 			cfg, err := config.LoadConfig()
 			if err != nil {
 				fmt.Printf("Error loading config: %v\n", err)
 				return
 			}
 
+			if isLinkedGlobalSecret(cfg, appName, secretName) {
+				fmt.Printf("Secret %s is a global secret linked to %s. Use 'portico secrets global edit' or 'unlink-global'\n", secretName, appName)
+				return
+			}
+
 			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
 			a, err := am.LoadApp(appName)
 			if err != nil {
@@ -110,15 +123,15 @@ func NewSecretsEditCmd() *cobra.Command {
 			}
 
 			// Update secret file
-			secretPath := filepath.Join(envDir, secretName)
-			if err := os.WriteFile(secretPath, []byte(value), 0o600); err != nil {
+			// Encrypted with the host master key when secrets are encrypted at rest
+			// Previous values are kept as versions in .secrets-history
+			store := secrets.NewStore(cfg.SecretsKey)
+			version, err := store.WriteVersion(envDir, secretName, value, util.CurrentActor(cfg.PorticoHome), "edit")
+			if err != nil {
 				fmt.Printf("Error updating secret file: %v\n", err)
 				return
 			}
 
-			// Fix file ownership if running as root
-			_ = util.FixFileOwnership(secretPath)
-
 			if err := am.SaveApp(a); err != nil {
 				fmt.Printf("Error saving app: %v\n", err)
 				return
@@ -155,10 +168,20 @@ func NewSecretsEditCmd() *cobra.Command {
 				fmt.Printf("Error generating docker compose: %v\n", err)
 				return
 			}
+			event := journal.Event{
+				App: appName, Action: "secrets edit", Summary: fmt.Sprintf("%s for service %s", secretName, serviceName), Before: fmt.Sprintf("v%d", version-1), After: fmt.Sprintf("v%d", version),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("secrets edit %s", secretName), true) {
+				recordChange(event, true, nil)
+				return
+			}
 			if err := dm.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
 
 			// Restart the service to apply updated secret
 			if err := dm.RestartService(appDir, serviceName); err != nil {
@@ -169,5 +192,9 @@ func NewSecretsEditCmd() *cobra.Command {
 		},
 	}
 
+	addSecretValueFlags(cmd)
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
 	return cmd
 }
@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewSecretsExpireCmd removes the previous values of rotated secrets once their grace period is over
+func NewSecretsExpireCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expire [secret-name]",
+		Short: "Remove previous values of rotated secrets after their grace period",
+		Long: `Remove the previous values (<secret-name>_previous) of rotated secrets whose grace
+period is over, and redeploy the services that mounted them.
+
+Without an app, all apps are checked: run it periodically (e.g. from a systemd
+timer, see docs/secrets.md). With a secret name, its grace period ends now.
+With --no-restart the running containers keep the previous values until
+"config commit" deploys the change, which then removes them.
+
+Examples:
+  portico secrets expire
+  portico secrets my-app expire
+  portico secrets my-app expire jwt_signing_key`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets), optional
+			appName, _ := getAppNameFromSecretsArgs(cmd)
+
+			secretName := ""
+			if len(args) > 0 {
+				secretName = strings.TrimSpace(args[0])
+				if appName == "" {
+					fmt.Println("Error: app-name is required to expire a given secret")
+					fmt.Println("Usage: portico secrets [app-name] expire [secret-name]")
+					os.Exit(1)
+				}
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				os.Exit(1)
+			}
+
+			appNames := []string{appName}
+			if appName == "" {
+				am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+				appNames, err = am.ListApps()
+				if err != nil {
+					fmt.Printf("Error listing apps: %v\n", err)
+					os.Exit(1)
+				}
+			}
+
+			total := 0
+			failed := false
+			for _, name := range appNames {
+				expired, err := expireSecretRotations(cmd, cfg, name, secretName, time.Now())
+				if err != nil {
+					fmt.Printf("Error: app %s: %v\n", name, err)
+					failed = true
+					continue
+				}
+				for _, secret := range expired {
+					fmt.Printf("Removed previous value of %s in %s\n", secret, name)
+				}
+				total += len(expired)
+			}
+
+			if secretName != "" && total == 0 {
+				fmt.Printf("Secret %s has no previous value in %s\n", secretName, appName)
+			} else if total == 0 && appName != "" {
+				fmt.Printf("No expired rotations in %s\n", appName)
+			}
+			// Errors exit non-zero: the daemon runs this as a job and checks the exit code
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
+
+// expireSecretRotations removes the previous values of the rotated secrets of an
+// app whose grace period is over at now (or of secretName, if given), and
+// redeploys the app without them. It returns the secrets expired
+func expireSecretRotations(cmd *cobra.Command, cfg *config.Config, appName, secretName string, now time.Time) ([]string, error) {
+	appDir := filepath.Join(cfg.AppsDir, appName)
+	dm := docker.NewManager(cfg.Registry.URL)
+
+	metadata, err := dm.GetPorticoMetadata(appDir)
+	if err != nil {
+		return nil, err
+	}
+	expired := make(map[string]bool)
+	var expiredNames []string
+	for _, r := range metadata.Rotations {
+		expires, err := time.Parse(time.RFC3339, r.Expires)
+		if r.Secret == secretName || (secretName == "" && (err != nil || !now.Before(expires))) {
+			expired[docker.PreviousSecretName(r.Secret)] = true
+			expiredNames = append(expiredNames, r.Secret)
+		}
+	}
+	if len(expiredNames) == 0 {
+		return nil, nil
+	}
+
+	am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+	a, err := am.LoadApp(appName)
+	if err != nil {
+		return nil, fmt.Errorf("error loading app: %w", err)
+	}
+
+	var affected []string
+	for i := range a.Services {
+		var kept []string
+		for _, s := range a.Services[i].Secrets {
+			if !expired[s] {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) != len(a.Services[i].Secrets) {
+			affected = append(affected, a.Services[i].Name)
+		}
+		a.Services[i].Secrets = kept
+	}
+
+	if err := am.SaveApp(a); err != nil {
+		return nil, fmt.Errorf("error saving app: %w", err)
+	}
+
+	var dockerServices []docker.Service
+	for _, s := range a.Services {
+		replicas := s.Replicas
+		if replicas == 0 {
+			replicas = 1 // Default to 1 if not specified
+		}
+		dockerServices = append(dockerServices, docker.Service{
+			Name:        s.Name,
+			Image:       s.Image,
+			Port:        s.Port,
+			ExtraPorts:  s.ExtraPorts,
+			Environment: s.Environment,
+			Volumes:     s.Volumes,
+			Secrets:     s.Secrets,
+			DependsOn:   s.DependsOn,
+			Replicas:    replicas,
+		})
+	}
+
+	if err := dm.GenerateDockerCompose(appDir, dockerServices, &docker.PorticoMetadata{Domain: a.Domain, Port: a.Port}); err != nil {
+		return nil, fmt.Errorf("error generating docker compose: %w", err)
+	}
+	if err := dm.UpdatePorticoMetadata(appDir, func(m *docker.PorticoMetadata) {
+		var rotations []docker.SecretRotation
+		for _, r := range m.Rotations {
+			if !expired[docker.PreviousSecretName(r.Secret)] {
+				rotations = append(rotations, r)
+			}
+		}
+		m.Rotations = rotations
+	}); err != nil {
+		return nil, fmt.Errorf("error recording rotation: %w", err)
+	}
+
+	event := journal.Event{
+		App: appName, Action: "secrets expire", Summary: "previous values of " + strings.Join(expiredNames, ", "),
+	}
+	// Stage instead of redeploying (--no-restart or "config stage")
+	staged := false
+	for _, service := range affected {
+		if stageConfigChange(cmd, cfg, appName, service, "secrets expire "+strings.Join(expiredNames, " "), false) {
+			staged = true
+		}
+	}
+	if staged {
+		// The running containers still mount the files: "config commit" removes them
+		if err := dm.UpdatePorticoMetadata(appDir, func(m *docker.PorticoMetadata) {
+			for name := range expired {
+				m.Pending.Remove = append(m.Pending.Remove, name)
+			}
+			sort.Strings(m.Pending.Remove)
+		}); err != nil {
+			return nil, fmt.Errorf("error staging the removal of the previous values: %w", err)
+		}
+		recordChange(event, true, nil)
+		return expiredNames, nil
+	}
+	if len(affected) > 0 {
+		// Services whose secrets changed are recreated
+		if err := dm.DeployApp(appDir, dockerServices); err != nil {
+			recordChange(event, false, err)
+			return nil, fmt.Errorf("error deploying app: %w", err)
+		}
+	}
+	recordChange(event, false, nil)
+
+	// No container mounts the files anymore
+	for name := range expired {
+		if err := docker.RemoveSecretFile(appDir, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return expiredNames, nil
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+)
+
+// NewSecretsGlobalCmd creates the secrets global command for secrets shared across apps
+func NewSecretsGlobalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "global",
+		Short: "Manage global secrets shared across apps",
+		Long: `Manage global secrets, stored once under /home/portico/secrets and shared by
+the apps that link them with 'link-global'.
+
+Linked global secrets are mounted at /run/secrets like the app's own secrets.
+After changing one, redeploy the apps that use it ('portico up <app>').
+
+Examples:
+  portico secrets global add stripe_key sk_live_abc123
+  portico secrets my-app link-global stripe_key
+  portico secrets global list`,
+	}
+
+	cmd.AddCommand(NewSecretsGlobalAddCmd())
+	cmd.AddCommand(NewSecretsGlobalEditCmd())
+	cmd.AddCommand(NewSecretsGlobalDeleteCmd())
+	cmd.AddCommand(NewSecretsGlobalListCmd())
+
+	return cmd
+}
+
+// validGlobalSecretName reports whether a global secret name is a plain file name
+func validGlobalSecretName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// globalSecretConsumers returns the apps linking each global secret, sorted
+func globalSecretConsumers(cfg *config.Config) (map[string][]string, error) {
+	am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+	appNames, err := am.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+
+	dm := docker.NewManager(cfg.Registry.URL)
+	consumers := make(map[string][]string)
+	for _, appName := range appNames {
+		metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+		if err != nil {
+			continue
+		}
+		for _, name := range metadata.Global {
+			consumers[name] = append(consumers[name], appName)
+		}
+	}
+	for name := range consumers {
+		sort.Strings(consumers[name])
+	}
+	return consumers, nil
+}
+
+// isLinkedGlobalSecret reports whether a secret of an app is a linked global secret
+func isLinkedGlobalSecret(cfg *config.Config, appName, secretName string) bool {
+	dm := docker.NewManager(cfg.Registry.URL)
+	metadata, err := dm.GetPorticoMetadata(filepath.Join(cfg.AppsDir, appName))
+	if err != nil {
+		return false
+	}
+	for _, name := range metadata.Global {
+		if name == secretName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewSecretsGlobalAddCmd adds a global secret
+func NewSecretsGlobalAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [secret-name] [value]",
+		Short: "Add a global secret",
+		Long:  "Add a global secret that apps can link with 'portico secrets <app> link-global <secret-name>'.\n\nExamples:\n  portico secrets global add stripe_key sk_live_abc123\n  portico secrets global add gcp_credentials --from-file ./credentials.json",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			secretName := strings.TrimSpace(args[0])
+			if !validGlobalSecretName(secretName) {
+				fmt.Printf("Error: invalid secret name %s\n", args[0])
+				return
+			}
+
+			value, err := readSecretValue(cmd, args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				fmt.Println("Usage: portico secrets global add [secret-name] [value | --from-file path | --stdin | --generate]")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			globalDir := secrets.GlobalDir(cfg.PorticoHome)
+			if _, err := os.Stat(filepath.Join(globalDir, secretName)); err == nil {
+				fmt.Printf("Global secret %s already exists. Use 'edit' to update it.\n", secretName)
+				return
+			}
+			if err := os.MkdirAll(globalDir, 0o755); err != nil {
+				fmt.Printf("Error creating global secrets directory: %v\n", err)
+				return
+			}
+			_ = util.FixFileOwnership(globalDir)
+
+			store := secrets.NewStore(cfg.SecretsKey)
+			if _, err := store.WriteVersion(globalDir, secretName, value, util.CurrentActor(cfg.PorticoHome), "add"); err != nil {
+				fmt.Printf("Error creating secret file: %v\n", err)
+				return
+			}
+
+			journal.Record(journal.Event{
+				Action: "secrets global add", Summary: secretName,
+			})
+			fmt.Printf("Added global secret %s\n", secretName)
+			fmt.Printf("Link it to an app with: portico secrets <app> link-global %s\n", secretName)
+		},
+	}
+
+	addSecretValueFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewSecretsGlobalDeleteCmd deletes a global secret no app links
+func NewSecretsGlobalDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "del [secret-name]",
+		Aliases: []string{"delete"},
+		Short:   "Delete a global secret",
+		Long:    "Delete a global secret. Apps that link it must unlink it first ('unlink-global').\n\nExample:\n  portico secrets global del stripe_key",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			secretName := strings.TrimSpace(args[0])
+			if !validGlobalSecretName(secretName) {
+				fmt.Printf("Error: invalid secret name %s\n", args[0])
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			globalDir := secrets.GlobalDir(cfg.PorticoHome)
+			if _, err := os.Stat(filepath.Join(globalDir, secretName)); err != nil {
+				fmt.Printf("Global secret %s not found\n", secretName)
+				return
+			}
+
+			consumers, err := globalSecretConsumers(cfg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if apps := consumers[secretName]; len(apps) > 0 {
+				fmt.Printf("Error: global secret %s is linked to %s\n", secretName, strings.Join(apps, ", "))
+				fmt.Printf("Unlink it first: portico secrets <app> unlink-global %s\n", secretName)
+				return
+			}
+
+			if err := os.Remove(filepath.Join(globalDir, secretName)); err != nil {
+				fmt.Printf("Error deleting secret file: %v\n", err)
+				return
+			}
+			// The history keeps the last value, so the secret can be recreated
+			store := secrets.NewStore(cfg.SecretsKey)
+			if _, err := store.RecordDelete(globalDir, secretName, util.CurrentActor(cfg.PorticoHome)); err != nil {
+				fmt.Printf("Warning: could not record deletion in secret history: %v\n", err)
+			}
+
+			journal.Record(journal.Event{
+				Action: "secrets global delete", Summary: secretName,
+			})
+			fmt.Printf("Deleted global secret %s\n", secretName)
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewSecretsGlobalEditCmd updates a global secret
+func NewSecretsGlobalEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit [secret-name] [value]",
+		Short: "Edit a global secret",
+		Long:  "Update a global secret. The apps that link it get the new value when they are redeployed.\n\nExample:\n  portico secrets global edit stripe_key sk_live_newkey456",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			secretName := strings.TrimSpace(args[0])
+			if !validGlobalSecretName(secretName) {
+				fmt.Printf("Error: invalid secret name %s\n", args[0])
+				return
+			}
+
+			value, err := readSecretValue(cmd, args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				fmt.Println("Usage: portico secrets global edit [secret-name] [value | --from-file path | --stdin | --generate]")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			globalDir := secrets.GlobalDir(cfg.PorticoHome)
+			if _, err := os.Stat(filepath.Join(globalDir, secretName)); err != nil {
+				fmt.Printf("Global secret %s not found. Use 'add' to create it.\n", secretName)
+				return
+			}
+
+			store := secrets.NewStore(cfg.SecretsKey)
+			if _, err := store.WriteVersion(globalDir, secretName, value, util.CurrentActor(cfg.PorticoHome), "edit"); err != nil {
+				fmt.Printf("Error updating secret file: %v\n", err)
+				return
+			}
+
+			journal.Record(journal.Event{
+				Action: "secrets global edit", Summary: secretName,
+			})
+			fmt.Printf("Updated global secret %s\n", secretName)
+
+			consumers, err := globalSecretConsumers(cfg)
+			if err != nil {
+				fmt.Printf("Warning: %v\n", err)
+				return
+			}
+			if len(consumers[secretName]) > 0 {
+				fmt.Println("\nRedeploy the apps that use it to apply the new value:")
+				for _, appName := range consumers[secretName] {
+					fmt.Printf("  portico up %s\n", appName)
+				}
+			}
+		},
+	}
+
+	addSecretValueFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/secrets"
+)
+
+// NewSecretsGlobalListCmd lists the global secrets and the apps linking them
+func NewSecretsGlobalListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List global secrets",
+		Long:  "List the global secrets and the apps that link them.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			files, err := secrets.ListFiles(secrets.GlobalDir(cfg.PorticoHome))
+			if err != nil {
+				fmt.Printf("Error listing global secrets: %v\n", err)
+				return
+			}
+
+			consumers, err := globalSecretConsumers(cfg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			fmt.Println("Global secrets:")
+			if len(files) == 0 {
+				fmt.Println("  (none)")
+			}
+			existing := make(map[string]bool)
+			for _, path := range files {
+				name := filepath.Base(path)
+				existing[name] = true
+				if apps := consumers[name]; len(apps) > 0 {
+					fmt.Printf("  %s (used by %s)\n", name, strings.Join(apps, ", "))
+				} else {
+					fmt.Printf("  %s (unused)\n", name)
+				}
+			}
+
+			// Links whose secret was removed by hand
+			var missing []string
+			for name := range consumers {
+				if !existing[name] {
+					missing = append(missing, name)
+				}
+			}
+			sort.Strings(missing)
+			for _, name := range missing {
+				fmt.Printf("  ✗ %s (missing, linked to %s)\n", name, strings.Join(consumers[name], ", "))
+			}
+		},
+	}
+}
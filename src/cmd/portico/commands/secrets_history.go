@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/secrets"
+)
+
+// NewSecretsHistoryCmd lists the versions of a secret
+func NewSecretsHistoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history [secret-name]",
+		Short: "List the versions of a secret",
+		Long:  "List the versions of a secret kept by add, edit, del and rollback, with their time and author.\nValues are not shown; use 'rollback' to restore one.\n\nExample:\n  portico secrets my-app history api_key",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets)
+			appName, err := getAppNameFromSecretsArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico secrets [app-name] history [secret-name]")
+				return
+			}
+
+			secretName := strings.TrimSpace(args[0])
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			envDir := filepath.Join(cfg.AppsDir, appName, "env")
+			versions, err := secrets.History(envDir, secretName)
+			if err != nil {
+				fmt.Printf("Error reading history: %v\n", err)
+				return
+			}
+			if len(versions) == 0 {
+				fmt.Printf("No history for secret %s in %s\n", secretName, appName)
+				return
+			}
+
+			fmt.Printf("History of secret %s in %s:\n", secretName, appName)
+			for i := len(versions) - 1; i >= 0; i-- {
+				v := versions[i]
+				line := fmt.Sprintf("  v%-4d %s  %-20s %s", v.Version, v.Time, v.Author, v.Action)
+				if i == len(versions)-1 && !v.Deleted {
+					line += " (current)"
+				}
+				fmt.Println(line)
+			}
+			if !secrets.NewStore(cfg.SecretsKey).Enabled() {
+				fmt.Println("\nNote: without a secrets key, previous values are stored in plaintext (portico secrets migrate encrypts them)")
+			}
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+)
+
+// NewSecretsLinkGlobalCmd mounts a global secret into a service of an app
+func NewSecretsLinkGlobalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link-global [secret-name]",
+		Short: "Use a global secret in a service",
+		Long:  "Mount a global secret (see 'portico secrets global') at /run/secrets in a service, like the app's own secrets.\n\nExamples:\n  portico secrets my-app link-global stripe_key\n    Links stripe_key (uses default service if only one exists)\n\n  portico secrets my-app worker link-global stripe_key\n    Links stripe_key to service 'worker'",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets)
+			appName, err := getAppNameFromSecretsArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico secrets [app-name] [service-name] link-global [secret-name]")
+				return
+			}
+
+			// Get service-name from args (optional)
+			serviceName, _ := getServiceNameFromSecretsArgs(cmd)
+
+			secretName := strings.TrimSpace(args[0])
+			if !validGlobalSecretName(secretName) {
+				fmt.Printf("Error: invalid secret name %s\n", args[0])
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			if _, err := os.Stat(filepath.Join(secrets.GlobalDir(cfg.PorticoHome), secretName)); err != nil {
+				fmt.Printf("Global secret %s not found. Create it with 'portico secrets global add %s'\n", secretName, secretName)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			// Auto-detect service if not specified
+			if serviceName == "" {
+				if len(a.Services) == 1 {
+					serviceName = a.Services[0].Name
+				} else {
+					var serviceNames []string
+					for _, s := range a.Services {
+						serviceNames = append(serviceNames, s.Name)
+					}
+					fmt.Printf("Error: app %s has %d services. Please specify service name\n", appName, len(a.Services))
+					fmt.Printf("Available services: %v\n", serviceNames)
+					fmt.Println("Usage: portico secrets [app-name] [service-name] link-global [secret-name]")
+					return
+				}
+			}
+
+			// Find service
+			serviceIndex := -1
+			for i := range a.Services {
+				if a.Services[i].Name == serviceName {
+					serviceIndex = i
+					break
+				}
+			}
+			if serviceIndex == -1 {
+				fmt.Printf("Service %s not found in app %s\n", serviceName, appName)
+				return
+			}
+			for _, s := range a.Services[serviceIndex].Secrets {
+				if s == secretName {
+					fmt.Printf("Service %s in %s already has a secret %s\n", serviceName, appName, secretName)
+					return
+				}
+			}
+
+			// A local secret with the same name would shadow the global one
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			linked := isLinkedGlobalSecret(cfg, appName, secretName)
+			if _, err := os.Stat(filepath.Join(appDir, "env", secretName)); err == nil && !linked {
+				fmt.Printf("Error: app %s has its own secret %s. Delete it or use another name\n", appName, secretName)
+				return
+			}
+
+			dm := docker.NewManager(cfg.Registry.URL)
+			if !linked {
+				if err := dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+					metadata.Global = append(metadata.Global, secretName)
+				}); err != nil {
+					fmt.Printf("Error linking global secret: %v\n", err)
+					return
+				}
+			}
+
+			a.Services[serviceIndex].Secrets = append(a.Services[serviceIndex].Secrets, secretName)
+
+			if err := am.SaveApp(a); err != nil {
+				fmt.Printf("Error saving app: %v\n", err)
+				return
+			}
+
+			// Regenerate docker-compose and redeploy
+			var dockerServices []docker.Service
+			for _, s := range a.Services {
+				replicas := s.Replicas
+				if replicas == 0 {
+					replicas = 1 // Default to 1 if not specified
+				}
+				dockerServices = append(dockerServices, docker.Service{
+					Name:        s.Name,
+					Image:       s.Image,
+					Port:        s.Port,
+					ExtraPorts:  s.ExtraPorts,
+					Environment: s.Environment,
+					Volumes:     s.Volumes,
+					Secrets:     s.Secrets,
+					DependsOn:   s.DependsOn,
+					Replicas:    replicas,
+				})
+			}
+
+			metadata := &docker.PorticoMetadata{
+				Domain: a.Domain,
+				Port:   a.Port,
+			}
+
+			if err := dm.GenerateDockerCompose(appDir, dockerServices, metadata); err != nil {
+				fmt.Printf("Error generating docker compose: %v\n", err)
+				return
+			}
+			event := journal.Event{
+				App: appName, Action: "secrets link-global", Summary: fmt.Sprintf("%s to service %s", secretName, serviceName),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("secrets link-global %s", secretName), true) {
+				recordChange(event, true, nil)
+				return
+			}
+			if err := dm.DeployApp(appDir, dockerServices); err != nil {
+				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
+				return
+			}
+			recordChange(event, false, nil)
+
+			// Restart the service to apply new secret
+			if err := dm.RestartService(appDir, serviceName); err != nil {
+				fmt.Printf("Warning: could not restart service: %v\n", err)
+			}
+
+			fmt.Printf("Linked global secret %s to service %s in %s\n", secretName, serviceName, appName)
+		},
+	}
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
@@ -65,7 +65,9 @@ func NewSecretsListCmd() *cobra.Command {
 						} else {
 							for _, secretName := range s.Secrets {
 								secretPath := filepath.Join(envDir, secretName)
-								if _, err := os.Stat(secretPath); err == nil {
+								if isLinkedGlobalSecret(cfg, appName, secretName) {
+									fmt.Printf("  ✓ %s (global)\n", secretName)
+								} else if _, err := os.Stat(secretPath); err == nil {
 									fmt.Printf("  ✓ %s (file exists)\n", secretName)
 								} else {
 									fmt.Printf("  ✗ %s (file missing)\n", secretName)
@@ -88,7 +90,9 @@ func NewSecretsListCmd() *cobra.Command {
 					} else {
 						for _, secretName := range s.Secrets {
 							secretPath := filepath.Join(envDir, secretName)
-							if _, err := os.Stat(secretPath); err == nil {
+							if isLinkedGlobalSecret(cfg, appName, secretName) {
+								fmt.Printf("  ✓ %s (global)\n", secretName)
+							} else if _, err := os.Stat(secretPath); err == nil {
 								fmt.Printf("  ✓ %s (file exists)\n", secretName)
 							} else {
 								fmt.Printf("  ✗ %s (file missing)\n", secretName)
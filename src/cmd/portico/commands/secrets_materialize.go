@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/secrets"
+)
+
+// NewSecretsMaterializeCmd decrypts the secrets of all apps and addon instances into tmpfs
+func NewSecretsMaterializeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "materialize",
+		Short: "Decrypt all secrets into tmpfs",
+		Long: `Decrypt the secrets of all apps and addon instances into tmpfs (/dev/shm/portico).
+
+Deploys do this automatically. tmpfs is cleared on reboot, so run this command
+at boot before Docker restarts the containers (see docs/secrets.md).
+
+Example:
+  portico secrets materialize`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			if !secrets.NewStore(cfg.SecretsKey).Enabled() {
+				fmt.Println("Secrets are not encrypted on this server, nothing to do")
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			appNames, err := am.ListApps()
+			if err != nil {
+				fmt.Printf("Error listing apps: %v\n", err)
+				return
+			}
+			dm := docker.NewManager(cfg.Registry.URL)
+			failed := false
+			for _, appName := range appNames {
+				appDir := filepath.Join(cfg.AppsDir, appName)
+				// Static sites and apps without secrets
+				if _, err := os.Stat(filepath.Join(appDir, "env")); err != nil {
+					if metadata, err := dm.GetPorticoMetadata(appDir); err != nil || len(metadata.Global) == 0 {
+						continue
+					}
+				}
+				if err := docker.MaterializeSecrets(appDir); err != nil {
+					fmt.Printf("Error: app %s: %v\n", appName, err)
+					failed = true
+				}
+			}
+
+			addonManager := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+			addonConfig, err := addonManager.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading addons config: %v\n", err)
+				return
+			}
+			for instanceName := range addonConfig.Instances {
+				if err := materializeAddonSecrets(cfg, instanceName); err != nil {
+					fmt.Printf("Error: addon %s: %v\n", instanceName, err)
+					failed = true
+				}
+			}
+
+			if failed {
+				return
+			}
+			fmt.Printf("Decrypted secrets into %s\n", secrets.RuntimeBase())
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+)
+
+// NewSecretsMigrateCmd encrypts existing plaintext secrets with the host master key
+func NewSecretsMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Encrypt existing plaintext secrets",
+		Long: `Encrypt the secrets of all apps and addon instances with the host master key.
+
+The master key is created if it doesn't exist (secrets_key in config.yml,
+default /etc/portico/secrets.key). Keep it out of the backups of /home/portico.
+
+Once secrets are encrypted, they are decrypted only into tmpfs (/dev/shm/portico)
+when an app or addon instance is deployed, and docker-compose.yml mounts that
+directory at /run/secrets instead of env/. Running containers keep the old
+mount until they are recreated.
+
+Example:
+  portico secrets migrate`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			store := secrets.NewStore(cfg.SecretsKey)
+			if !store.Enabled() {
+				if err := store.GenerateKey(); err != nil {
+					fmt.Printf("Error creating secrets key: %v\n", err)
+					return
+				}
+				fmt.Printf("Created secrets key %s\n", store.KeyFile)
+				fmt.Println("Keep this file out of backups of /home/portico, and back it up separately")
+			}
+
+			dirs, err := secretDirs(cfg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			count, err := store.Migrate(dirs)
+			if err != nil {
+				fmt.Printf("Error encrypting secrets: %v\n", err)
+				return
+			}
+			journal.Record(journal.Event{
+				Action: "secrets migrate", Summary: fmt.Sprintf("encrypted %d secret file(s)", count),
+			})
+			fmt.Printf("Encrypted %d secret file(s)\n", count)
+
+			// Point the /run/secrets mounts to tmpfs
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			appNames, err := am.ListApps()
+			if err != nil {
+				fmt.Printf("Error listing apps: %v\n", err)
+				return
+			}
+			var updatedApps []string
+			for _, appName := range appNames {
+				a, err := am.LoadApp(appName)
+				if err != nil || len(a.Services) == 0 {
+					continue
+				}
+				if err := am.SaveApp(a); err != nil {
+					fmt.Printf("Warning: could not update docker-compose.yml of %s: %v\n", appName, err)
+					continue
+				}
+				updatedApps = append(updatedApps, appName)
+			}
+
+			addonManager := addon.NewManager(cfg.AddonsDir, filepath.Join(cfg.AddonsDir, "instances"))
+			addonConfig, err := addonManager.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading addons config: %v\n", err)
+				return
+			}
+			var updatedInstances []string
+			for instanceName, instance := range addonConfig.Instances {
+				def, err := addonManager.LoadDefinition(instance.Type)
+				if err != nil {
+					fmt.Printf("Warning: could not load addon definition %s: %v\n", instance.Type, err)
+					continue
+				}
+				versionConfig, err := def.GetVersionConfig(instance.Version)
+				if err != nil {
+					fmt.Printf("Warning: could not update %s: %v\n", instanceName, err)
+					continue
+				}
+				instanceDir := filepath.Join(cfg.AddonsDir, "instances", instanceName)
+				if err := generateAddonCompose(instanceDir, addonSecretsSource(cfg, instanceName), instance, def, versionConfig); err != nil {
+					fmt.Printf("Warning: could not update docker-compose.yml of %s: %v\n", instanceName, err)
+					continue
+				}
+				updatedInstances = append(updatedInstances, instanceName)
+			}
+			sort.Strings(updatedInstances)
+
+			if len(updatedApps) == 0 && len(updatedInstances) == 0 {
+				return
+			}
+			fmt.Println("\nRecreate the containers to mount the decrypted secrets from tmpfs:")
+			for _, appName := range updatedApps {
+				fmt.Printf("  portico up %s\n", appName)
+			}
+			for _, instanceName := range updatedInstances {
+				fmt.Printf("  portico addons %s down && portico addons %s up\n", instanceName, instanceName)
+			}
+		},
+	}
+
+	return cmd
+}
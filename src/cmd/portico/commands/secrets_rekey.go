@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+)
+
+// NewSecretsRekeyCmd replaces the host master key and re-encrypts all secrets
+func NewSecretsRekeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Replace the secrets master key",
+		Long: `Generate a new master key and re-encrypt the secrets of all apps and addon
+instances with it. The old key stops working once the command completes.
+
+If the command is interrupted, run it again: the new key is kept next to the
+current one (<secrets_key>.new) until every secret has been re-encrypted.
+
+Running containers are not affected (they read the decrypted copies in tmpfs).
+
+Example:
+  portico secrets rekey`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			store := secrets.NewStore(cfg.SecretsKey)
+			if !store.Enabled() {
+				fmt.Println("Error: secrets are not encrypted on this server")
+				fmt.Println("Run 'portico secrets migrate' to create the master key and encrypt them")
+				return
+			}
+
+			dirs, err := secretDirs(cfg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			count, err := store.Rekey(dirs)
+			if err != nil {
+				fmt.Printf("Error re-encrypting secrets: %v\n", err)
+				fmt.Println("Run 'portico secrets rekey' again to resume")
+				return
+			}
+
+			journal.Record(journal.Event{
+				Action: "secrets rekey", Summary: fmt.Sprintf("re-encrypted %d secret file(s) with a new key", count),
+			})
+			fmt.Printf("Re-encrypted %d secret file(s) with a new key (%s)\n", count, store.KeyFile)
+			fmt.Println("Update any separate backup of the master key")
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewSecretsRollbackCmd restores a previous version of a secret
+func NewSecretsRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback [secret-name] [version]",
+		Short: "Restore a previous version of a secret",
+		Long:  "Restore a previous version of a secret (see 'history') and restart the services that use it.\nThe restored value is recorded as a new version.\n\nA deleted secret is added back to the given service (or the only one).\n\nExamples:\n  portico secrets my-app rollback api_key 3\n  portico secrets my-app api rollback api_key v3",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets)
+			appName, err := getAppNameFromSecretsArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico secrets [app-name] [service-name] rollback [secret-name] [version]")
+				return
+			}
+
+			// Get service-name from args (optional)
+			serviceName, _ := getServiceNameFromSecretsArgs(cmd)
+
+			secretName := strings.TrimSpace(args[0])
+			version, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(args[1]), "v"))
+			if err != nil || version < 1 {
+				fmt.Printf("Error: invalid version %s\n", args[1])
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			if isLinkedGlobalSecret(cfg, appName, secretName) {
+				fmt.Printf("Secret %s is a global secret linked to %s, it has no history in the app\n", secretName, appName)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			envDir := filepath.Join(appDir, "env")
+			store := secrets.NewStore(cfg.SecretsKey)
+
+			value, err := store.ReadVersion(envDir, secretName, version)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+
+			// Services that use the secret are restarted
+			var affected []string
+			for _, s := range a.Services {
+				for _, secret := range s.Secrets {
+					if secret == secretName {
+						affected = append(affected, s.Name)
+						break
+					}
+				}
+			}
+
+			// A deleted secret goes back to the given service
+			if len(affected) == 0 {
+				if serviceName == "" && len(a.Services) == 1 {
+					serviceName = a.Services[0].Name
+				}
+				if serviceName == "" {
+					fmt.Printf("Error: secret %s is not used by any service of %s. Please specify service name\n", secretName, appName)
+					fmt.Println("Usage: portico secrets [app-name] [service-name] rollback [secret-name] [version]")
+					return
+				}
+				found := false
+				for i := range a.Services {
+					if a.Services[i].Name == serviceName {
+						found = true
+						a.Services[i].Secrets = append(a.Services[i].Secrets, secretName)
+						break
+					}
+				}
+				if !found {
+					fmt.Printf("Service %s not found in app %s\n", serviceName, appName)
+					return
+				}
+				affected = append(affected, serviceName)
+			}
+
+			if err := os.MkdirAll(envDir, 0o755); err != nil {
+				fmt.Printf("Error creating env directory: %v\n", err)
+				return
+			}
+			newVersion, err := store.WriteVersion(envDir, secretName, value, util.CurrentActor(cfg.PorticoHome), fmt.Sprintf("rollback to v%d", version))
+			if err != nil {
+				fmt.Printf("Error writing secret file: %v\n", err)
+				return
+			}
+
+			if err := am.SaveApp(a); err != nil {
+				fmt.Printf("Error saving app: %v\n", err)
+				return
+			}
+
+			// Regenerate docker-compose and redeploy
+			dm := docker.NewManager(cfg.Registry.URL)
+
+			var dockerServices []docker.Service
+			for _, s := range a.Services {
+				replicas := s.Replicas
+				if replicas == 0 {
+					replicas = 1 // Default to 1 if not specified
+				}
+				dockerServices = append(dockerServices, docker.Service{
+					Name:        s.Name,
+					Image:       s.Image,
+					Port:        s.Port,
+					ExtraPorts:  s.ExtraPorts,
+					Environment: s.Environment,
+					Volumes:     s.Volumes,
+					Secrets:     s.Secrets,
+					DependsOn:   s.DependsOn,
+					Replicas:    replicas,
+				})
+			}
+
+			metadata := &docker.PorticoMetadata{
+				Domain: a.Domain,
+				Port:   a.Port,
+			}
+
+			if err := dm.GenerateDockerCompose(appDir, dockerServices, metadata); err != nil {
+				fmt.Printf("Error generating docker compose: %v\n", err)
+				return
+			}
+			event := journal.Event{
+				App: appName, Action: "secrets rollback", Summary: fmt.Sprintf("%s to v%d", secretName, version), Before: fmt.Sprintf("v%d", newVersion-1), After: fmt.Sprintf("v%d", newVersion),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			staged := false
+			for _, service := range affected {
+				if stageConfigChange(cmd, cfg, appName, service, fmt.Sprintf("secrets rollback %s v%d", secretName, version), true) {
+					staged = true
+				}
+			}
+			if staged {
+				recordChange(event, true, nil)
+				return
+			}
+			if err := dm.DeployApp(appDir, dockerServices); err != nil {
+				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
+				return
+			}
+			recordChange(event, false, nil)
+
+			// Restart the services to apply the restored secret
+			for _, service := range affected {
+				if err := dm.RestartService(appDir, service); err != nil {
+					fmt.Printf("Warning: could not restart service %s: %v\n", service, err)
+				}
+			}
+
+			fmt.Printf("Rolled back secret %s in %s to v%d (now v%d), restarted: %s\n", secretName, appName, version, newVersion, strings.Join(affected, ", "))
+		},
+	}
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// NewSecretsRotateCmd replaces a secret, keeping its previous value mounted for a grace period
+func NewSecretsRotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate [secret-name] [value]",
+		Short: "Rotate a secret, keeping the previous value for a grace period",
+		Long: `Replace the value of a secret and keep the previous one mounted as
+/run/secrets/<secret-name>_previous for a grace period, so tokens signed with the
+old key (JWT, sessions, ...) stay valid while they expire. Only the services that
+use the secret are restarted.
+
+The grace period is --grace, or secrets_rotation_grace in config.yml (default 24h).
+'portico secrets expire' removes the previous values whose grace period is over
+(run it periodically, see docs/secrets.md).
+
+Examples:
+  portico secrets my-app rotate jwt_signing_key --generate --length 64
+  portico secrets my-app rotate api_key sk-newkey123 --grace 1h`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets)
+			appName, err := getAppNameFromSecretsArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico secrets [app-name] rotate [secret-name] [value | --from-file path | --stdin | --generate]")
+				return
+			}
+
+			secretName := strings.TrimSpace(args[0])
+			if secretName == "" {
+				fmt.Println("Error: secret-name is required")
+				return
+			}
+			previousName := docker.PreviousSecretName(secretName)
+			if strings.HasSuffix(secretName, "_previous") {
+				fmt.Printf("Error: %s holds the previous value of a rotated secret, rotate %s instead\n", secretName, strings.TrimSuffix(secretName, "_previous"))
+				return
+			}
+
+			value, err := readSecretValue(cmd, args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				fmt.Println("Usage: portico secrets [app-name] rotate [secret-name] [value | --from-file path | --stdin | --generate]")
+				return
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			grace, _ := cmd.Flags().GetString("grace")
+			if grace == "" {
+				grace = cfg.RotationGrace
+			}
+			graceDuration, err := time.ParseDuration(grace)
+			if err != nil || graceDuration <= 0 {
+				fmt.Printf("Error: invalid grace period %q (e.g. 30m, 24h)\n", grace)
+				return
+			}
+
+			if isLinkedGlobalSecret(cfg, appName, secretName) {
+				fmt.Printf("Secret %s is a global secret linked to %s. Use 'portico secrets global edit'\n", secretName, appName)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			// Services that use the secret also get its previous value
+			var affected []string
+			for i := range a.Services {
+				uses := false
+				for _, s := range a.Services[i].Secrets {
+					if s == secretName {
+						uses = true
+						break
+					}
+				}
+				if !uses {
+					continue
+				}
+				affected = append(affected, a.Services[i].Name)
+				hasPrevious := false
+				for _, s := range a.Services[i].Secrets {
+					if s == previousName {
+						hasPrevious = true
+						break
+					}
+				}
+				if !hasPrevious {
+					a.Services[i].Secrets = append(a.Services[i].Secrets, previousName)
+				}
+			}
+			if len(affected) == 0 {
+				fmt.Printf("Error: secret %s is not used by any service of %s\n", secretName, appName)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			envDir := filepath.Join(appDir, "env")
+			store := secrets.NewStore(cfg.SecretsKey)
+
+			current, err := store.ReadFile(filepath.Join(envDir, secretName))
+			if err != nil {
+				fmt.Printf("Error reading secret %s: %v\n", secretName, err)
+				return
+			}
+			if string(current) == string(value) {
+				fmt.Printf("Error: the new value of %s is the same as the current one\n", secretName)
+				return
+			}
+
+			// The previous value is a copy for the grace period, its history is the secret's
+			if err := store.WriteFile(filepath.Join(envDir, previousName), current); err != nil {
+				fmt.Printf("Error writing secret file: %v\n", err)
+				return
+			}
+			newVersion, err := store.WriteVersion(envDir, secretName, value, util.CurrentActor(cfg.PorticoHome), "rotate")
+			if err != nil {
+				fmt.Printf("Error writing secret file: %v\n", err)
+				return
+			}
+
+			if err := am.SaveApp(a); err != nil {
+				fmt.Printf("Error saving app: %v\n", err)
+				return
+			}
+
+			// Regenerate docker-compose and redeploy
+			dm := docker.NewManager(cfg.Registry.URL)
+
+			var dockerServices []docker.Service
+			for _, s := range a.Services {
+				replicas := s.Replicas
+				if replicas == 0 {
+					replicas = 1 // Default to 1 if not specified
+				}
+				dockerServices = append(dockerServices, docker.Service{
+					Name:        s.Name,
+					Image:       s.Image,
+					Port:        s.Port,
+					ExtraPorts:  s.ExtraPorts,
+					Environment: s.Environment,
+					Volumes:     s.Volumes,
+					Secrets:     s.Secrets,
+					DependsOn:   s.DependsOn,
+					Replicas:    replicas,
+				})
+			}
+
+			metadata := &docker.PorticoMetadata{
+				Domain: a.Domain,
+				Port:   a.Port,
+			}
+
+			if err := dm.GenerateDockerCompose(appDir, dockerServices, metadata); err != nil {
+				fmt.Printf("Error generating docker compose: %v\n", err)
+				return
+			}
+
+			// Record when the previous value expires (a new rotation restarts the period)
+			expires := time.Now().UTC().Add(graceDuration)
+			if err := dm.UpdatePorticoMetadata(appDir, func(m *docker.PorticoMetadata) {
+				var rotations []docker.SecretRotation
+				for _, r := range m.Rotations {
+					if r.Secret != secretName {
+						rotations = append(rotations, r)
+					}
+				}
+				m.Rotations = append(rotations, docker.SecretRotation{
+					Secret:  secretName,
+					Expires: expires.Format(time.RFC3339),
+				})
+			}); err != nil {
+				fmt.Printf("Error recording rotation: %v\n", err)
+				return
+			}
+
+			event := journal.Event{
+				App: appName, Action: "secrets rotate", Summary: fmt.Sprintf("%s (services: %s)", secretName, strings.Join(affected, ", ")), Before: fmt.Sprintf("v%d", newVersion-1), After: fmt.Sprintf("v%d", newVersion),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			staged := false
+			for _, service := range affected {
+				if stageConfigChange(cmd, cfg, appName, service, fmt.Sprintf("secrets rotate %s", secretName), true) {
+					staged = true
+				}
+			}
+			if staged {
+				recordChange(event, true, nil)
+				return
+			}
+			if err := dm.DeployApp(appDir, dockerServices); err != nil {
+				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
+				return
+			}
+			recordChange(event, false, nil)
+
+			// Restart the services to apply the new value
+			for _, service := range affected {
+				if err := dm.RestartService(appDir, service); err != nil {
+					fmt.Printf("Warning: could not restart service %s: %v\n", service, err)
+				}
+			}
+
+			fmt.Printf("Rotated secret %s in %s (now v%d), restarted: %s\n", secretName, appName, newVersion, strings.Join(affected, ", "))
+			fmt.Printf("Previous value available as /run/secrets/%s until %s\n", previousName, expires.Local().Format(time.RFC1123))
+		},
+	}
+
+	addSecretValueFlags(cmd)
+
+	cmd.Flags().String("grace", "", "How long the previous value stays available (default: secrets_rotation_grace in config.yml, 24h)")
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
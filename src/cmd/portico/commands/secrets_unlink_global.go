@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+)
+
+// NewSecretsUnlinkGlobalCmd removes a global secret from a service of an app
+func NewSecretsUnlinkGlobalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unlink-global [secret-name]",
+		Short: "Stop using a global secret in a service",
+		Long:  "Remove a global secret from a service. The global secret itself is kept.\n\nExamples:\n  portico secrets my-app unlink-global stripe_key\n  portico secrets my-app worker unlink-global stripe_key",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command (secrets)
+			appName, err := getAppNameFromSecretsArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico secrets [app-name] [service-name] unlink-global [secret-name]")
+				return
+			}
+
+			// Get service-name from args (optional)
+			serviceName, _ := getServiceNameFromSecretsArgs(cmd)
+
+			secretName := strings.TrimSpace(args[0])
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			if !isLinkedGlobalSecret(cfg, appName, secretName) {
+				fmt.Printf("Global secret %s is not linked to %s\n", secretName, appName)
+				return
+			}
+
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			a, err := am.LoadApp(appName)
+			if err != nil {
+				fmt.Printf("Error loading app: %v\n", err)
+				return
+			}
+
+			// Auto-detect service if not specified
+			if serviceName == "" {
+				if len(a.Services) == 1 {
+					serviceName = a.Services[0].Name
+				} else {
+					var serviceNames []string
+					for _, s := range a.Services {
+						serviceNames = append(serviceNames, s.Name)
+					}
+					fmt.Printf("Error: app %s has %d services. Please specify service name\n", appName, len(a.Services))
+					fmt.Printf("Available services: %v\n", serviceNames)
+					fmt.Println("Usage: portico secrets [app-name] [service-name] unlink-global [secret-name]")
+					return
+				}
+			}
+
+			// Find service and remove secret
+			found := false
+			removed := false
+			stillUsed := false
+			for i := range a.Services {
+				if a.Services[i].Name != serviceName {
+					for _, s := range a.Services[i].Secrets {
+						if s == secretName {
+							stillUsed = true
+						}
+					}
+					continue
+				}
+				found = true
+				filtered := make([]string, 0, len(a.Services[i].Secrets))
+				for _, s := range a.Services[i].Secrets {
+					if s == secretName {
+						removed = true
+						continue
+					}
+					filtered = append(filtered, s)
+				}
+				a.Services[i].Secrets = filtered
+			}
+			if !found {
+				fmt.Printf("Service %s not found in app %s\n", serviceName, appName)
+				return
+			}
+			if !removed {
+				fmt.Printf("Global secret %s is not linked to service %s in %s\n", secretName, serviceName, appName)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			dm := docker.NewManager(cfg.Registry.URL)
+
+			// Drop the link once no service of the app uses the secret
+			if !stillUsed {
+				if err := dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+					var global []string
+					for _, name := range metadata.Global {
+						if name != secretName {
+							global = append(global, name)
+						}
+					}
+					metadata.Global = global
+				}); err != nil {
+					fmt.Printf("Error unlinking global secret: %v\n", err)
+					return
+				}
+				// Copy made at deploy time when secrets are stored in plaintext
+				if err := os.Remove(filepath.Join(appDir, "env", secretName)); err != nil && !os.IsNotExist(err) {
+					fmt.Printf("Warning: could not delete secret file: %v\n", err)
+				}
+			}
+
+			if err := am.SaveApp(a); err != nil {
+				fmt.Printf("Error saving app: %v\n", err)
+				return
+			}
+
+			// Regenerate docker-compose and redeploy
+			var dockerServices []docker.Service
+			for _, s := range a.Services {
+				replicas := s.Replicas
+				if replicas == 0 {
+					replicas = 1 // Default to 1 if not specified
+				}
+				dockerServices = append(dockerServices, docker.Service{
+					Name:        s.Name,
+					Image:       s.Image,
+					Port:        s.Port,
+					ExtraPorts:  s.ExtraPorts,
+					Environment: s.Environment,
+					Volumes:     s.Volumes,
+					Secrets:     s.Secrets,
+					DependsOn:   s.DependsOn,
+					Replicas:    replicas,
+				})
+			}
+
+			metadata := &docker.PorticoMetadata{
+				Domain: a.Domain,
+				Port:   a.Port,
+			}
+
+			if err := dm.GenerateDockerCompose(appDir, dockerServices, metadata); err != nil {
+				fmt.Printf("Error generating docker compose: %v\n", err)
+				return
+			}
+			event := journal.Event{
+				App: appName, Action: "secrets unlink-global", Summary: fmt.Sprintf("%s from service %s", secretName, serviceName),
+			}
+			// Stage instead of redeploying (--no-restart or "config stage")
+			if stageConfigChange(cmd, cfg, appName, serviceName, fmt.Sprintf("secrets unlink-global %s", secretName), false) {
+				recordChange(event, true, nil)
+				return
+			}
+			if err := dm.DeployApp(appDir, dockerServices); err != nil {
+				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
+				return
+			}
+			recordChange(event, false, nil)
+
+			// Restart the service to apply removed secret
+			if err := dm.RestartService(appDir, serviceName); err != nil {
+				fmt.Printf("Warning: could not restart service: %v\n", err)
+			}
+
+			fmt.Printf("Unlinked global secret %s from service %s in %s\n", secretName, serviceName, appName)
+		},
+	}
+
+	cmd.Flags().Bool("no-restart", false, "Update docker-compose.yml without redeploying (deploy later with 'config commit')")
+
+	return cmd
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -22,6 +23,7 @@ func NewServiceScaleCmd() *cobra.Command {
 		
 This uses Docker Compose's --scale feature to run multiple instances of the same service.
 The load will be distributed across all instances by Caddy (for the main service) or Docker's internal load balancing.
+Use 'portico set [app-name] lb-policy' and 'portico set [app-name] healthcheck' to control how Caddy balances them.
 
 Examples:
   # Scale 'web' service to 3 instances
@@ -71,8 +73,12 @@ Examples:
 
 			// Find and update the service
 			found := false
+			previousReplicas := 1
 			for i := range appConfig.Services {
 				if appConfig.Services[i].Name == serviceName {
+					if appConfig.Services[i].Replicas > 0 {
+						previousReplicas = appConfig.Services[i].Replicas
+					}
 					appConfig.Services[i].Replicas = replicas
 					found = true
 					break
@@ -123,11 +129,23 @@ Examples:
 				return
 			}
 
+			event := journal.Event{
+				App: appName, Action: "service scale", Summary: serviceName, Before: strconv.Itoa(previousReplicas), After: strconv.Itoa(replicas),
+			}
 			// Deploy with scale
 			if err := dockerManager.DeployApp(appDir, dockerServices); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
+				recordChange(event, false, err)
 				return
 			}
+			recordChange(event, false, nil)
+
+			// Regenerate app Caddyfile so Caddy balances across every replica
+			if appConfig.Port > 0 {
+				if err := appManager.CreateDefaultCaddyfile(appName); err != nil {
+					fmt.Printf("Warning: could not update Caddyfile: %v\n", err)
+				}
+			}
 
 			// Update Caddyfile (in case it's the main service)
 			proxyManager := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
@@ -18,6 +18,7 @@ import (
 func NewServiceUpdateImageCmd() *cobra.Command {
 	var port int
 	var noHTTPPort bool
+	var noWatch bool
 
 	cmd := &cobra.Command{
 		Use:   "image [image-name]",
@@ -145,6 +146,9 @@ Examples:
 				}
 			}
 
+			// The running release, to roll back to if the new one crash-loops
+			previous := currentRelease(cfg, filepath.Join(cfg.AppsDir, appName))
+
 			// Save app configuration
 			if err := appManager.SaveApp(appConfig); err != nil {
 				fmt.Printf("Error saving app: %v\n", err)
@@ -192,7 +196,7 @@ Examples:
 			}
 
 			// Deploy the application
-			if err := dockerManager.DeployApp(appDir, dockerServices); err != nil {
+			if err := deployWithWatch(cfg, appDir, dockerServices, previous, !noWatch); err != nil {
 				fmt.Printf("Error deploying app: %v\n", err)
 				return
 			}
@@ -226,6 +230,7 @@ Examples:
 
 	cmd.Flags().IntVar(&port, "port", 0, "Internal port for the service (default: 3000 for web services, 0 for workers)")
 	cmd.Flags().BoolVar(&noHTTPPort, "no-http-port", false, "Create a background worker without HTTP port")
+	cmd.Flags().BoolVar(&noWatch, "no-watch", false, "Don't watch the new release for crash loops (no automatic rollback)")
 
 	return cmd
 }
@@ -7,22 +7,29 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// setProperties lists the properties handled by set subcommands
+var setProperties = map[string]bool{
+	"http-port":    true,
+	"http-service": true,
+	"http":         true,
+	"external-ip":  true,
+	"lb-policy":    true,
+	"healthcheck":  true,
+	"limits":       true,
+}
+
 // NewSetCmd is the root command for app configuration: set [app-name] ...
 func NewSetCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "set [app-name] [property] [value]",
-		Short: "Set application configuration",
-		Long:  "Set application configuration properties.",
-		Args:  cobra.ArbitraryArgs,
+		Use:                "set [app-name] [property] [value]",
+		Short:              "Set application configuration",
+		Long:               "Set application configuration properties.",
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true, // Flags belong to the property subcommands
 		Run: func(parentCmd *cobra.Command, args []string) {
 			// Parse os.Args directly to find subcommand
 			allArgs := os.Args[1:] // Skip program name
-			knownProperties := map[string]bool{
-				"http-port":    true,
-				"http-service": true,
-				"http":         true,
-				"external-ip":  true,
-			}
+			knownProperties := setProperties
 
 			var propertyName string
 			var propertyIndex int
@@ -72,6 +79,13 @@ func NewSetCmd() *cobra.Command {
 					// Get non-flag arguments
 					nonFlagArgs := subCmd.Flags().Args()
 
+					// Run is called directly, so check the subcommand's Args here
+					if err := subCmd.ValidateArgs(nonFlagArgs); err != nil {
+						fmt.Printf("Error: %v\n", err)
+						_ = subCmd.Help()
+						return
+					}
+
 					// Call the subcommand's Run function directly
 					if subCmd.Run != nil {
 						subCmd.Run(subCmd, nonFlagArgs)
@@ -108,7 +122,7 @@ func getAppNameFromSetArgs(cmd *cobra.Command) (string, error) {
 					continue
 				}
 				// Skip known properties
-				if args[j] == "http-port" || args[j] == "http-service" || args[j] == "http" || args[j] == "external-ip" {
+				if setProperties[args[j]] {
 					continue
 				}
 				// This should be the app-name
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewSetExternalIPCmd sets the external IP address for sslip.io domain generation
@@ -34,11 +35,13 @@ Examples:
 
 			// Handle "auto" to enable auto-detection
 			if ipArg == "auto" {
+				previous := cfg.ExternalIP
 				cfg.ExternalIP = ""
 				if err := cfg.SaveConfig(); err != nil {
 					fmt.Printf("Error saving config: %v\n", err)
 					return
 				}
+				journal.Record(journal.Event{Action: "set external-ip", Before: previous, After: "auto"})
 				fmt.Println("External IP set to auto-detection mode")
 				return
 			}
@@ -50,11 +53,13 @@ Examples:
 				return
 			}
 
+			previous := cfg.ExternalIP
 			cfg.ExternalIP = ipArg
 			if err := cfg.SaveConfig(); err != nil {
 				fmt.Printf("Error saving config: %v\n", err)
 				return
 			}
+			journal.Record(journal.Event{Action: "set external-ip", Before: previous, After: ipArg})
 
 			fmt.Printf("External IP set to %s\n", ipArg)
 			fmt.Println("This IP will be used for generating sslip.io domains for new apps")
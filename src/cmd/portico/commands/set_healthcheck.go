@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/proxy"
+)
+
+// NewSetHealthcheckCmd configures active health checks for the HTTP service replicas
+func NewSetHealthcheckCmd() *cobra.Command {
+	var interval string
+	var timeout string
+	var status int
+
+	cmd := &cobra.Command{
+		Use:   "healthcheck [path|off]",
+		Short: "Configure active health checks (Caddy)",
+		Long: `Configure active health checks for the HTTP service.
+
+Caddy requests the given path on every replica at the configured interval.
+Replicas that fail the check stop receiving traffic until they recover.
+
+Examples:
+  portico set my-app healthcheck /health
+  portico set my-app healthcheck /healthz --interval 5s --timeout 2s --status 200
+  portico set my-app healthcheck off`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command
+			appName, err := getAppNameFromSetArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico set <app-name> healthcheck [path|off] [--interval 10s] [--timeout 5s] [--status 200]")
+				return
+			}
+
+			var healthCheck *docker.HealthCheckConfig
+			if args[0] != "off" {
+				path := args[0]
+				if !strings.HasPrefix(path, "/") {
+					fmt.Println("Error: health check path must start with /")
+					return
+				}
+				for _, d := range []string{interval, timeout} {
+					if d == "" {
+						continue
+					}
+					if _, err := time.ParseDuration(d); err != nil {
+						fmt.Printf("Error: invalid duration %s\n", d)
+						return
+					}
+				}
+				if status != 0 && (status < 100 || status > 599) {
+					fmt.Println("Error: invalid status code")
+					return
+				}
+				healthCheck = &docker.HealthCheckConfig{
+					Path:     path,
+					Interval: interval,
+					Timeout:  timeout,
+					Status:   status,
+				}
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			dm := docker.NewManager(cfg.Registry.URL)
+			previous := "off"
+			err = dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+				if metadata.Proxy == nil {
+					metadata.Proxy = &docker.ProxyConfig{}
+				}
+				if metadata.Proxy.HealthCheck != nil {
+					previous = metadata.Proxy.HealthCheck.Path
+				}
+				metadata.Proxy.HealthCheck = healthCheck
+				if metadata.Proxy.LBPolicy == "" && metadata.Proxy.HealthCheck == nil {
+					metadata.Proxy = nil
+				}
+			})
+			if err != nil {
+				fmt.Printf("Error updating app metadata: %v\n", err)
+				return
+			}
+
+			// Update Caddyfile
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			if err := am.CreateDefaultCaddyfile(appName); err != nil {
+				fmt.Printf("Warning: could not update Caddyfile: %v\n", err)
+			}
+
+			pm := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
+			if err := pm.UpdateCaddyfile(cfg.AppsDir); err != nil {
+				fmt.Printf("Error updating proxy Caddyfile: %v\n", err)
+				return
+			}
+
+			current := "off"
+			if healthCheck != nil {
+				current = healthCheck.Path
+			}
+			journal.Record(journal.Event{App: appName, Action: "set healthcheck", Before: previous, After: current})
+
+			if healthCheck == nil {
+				fmt.Printf("Health checks disabled for app %s\n", appName)
+				return
+			}
+			fmt.Printf("Health checks enabled for app %s (path: %s)\n", appName, healthCheck.Path)
+		},
+	}
+
+	cmd.Flags().StringVar(&interval, "interval", "10s", "How often to check each replica")
+	cmd.Flags().StringVar(&timeout, "timeout", "5s", "How long to wait for a response")
+	cmd.Flags().IntVar(&status, "status", 0, "Expected status code (default: any 2xx)")
+
+	return cmd
+}
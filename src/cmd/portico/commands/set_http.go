@@ -10,6 +10,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -68,6 +69,7 @@ func NewSetHttpCmd() *cobra.Command {
 
 			if action == "off" {
 				// Disable HTTP port
+				previousPort := a.Port
 				a.Port = 0
 
 				// Save app configuration
@@ -123,6 +125,9 @@ func NewSetHttpCmd() *cobra.Command {
 					return
 				}
 
+				journal.Record(journal.Event{
+					App: appName, Action: "set http", Summary: "off", Before: fmt.Sprintf("port %d", previousPort),
+				})
 				fmt.Printf("HTTP/Caddy proxy disabled for %s (app is now a background worker)\n", appName)
 				return
 			}
@@ -246,6 +251,9 @@ func NewSetHttpCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "set http", Summary: "on", After: fmt.Sprintf("service %s, port %d", targetService.Name, a.Port),
+			})
 			fmt.Printf("HTTP/Caddy proxy enabled for %s using service '%s' (port: %d)\n", appName, targetService.Name, a.Port)
 		},
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -67,6 +68,7 @@ func NewSetHttpPortCmd() *cobra.Command {
 			}
 
 			// Update HTTP port
+			previousPort := a.Port
 			a.Port = port
 
 			if err := am.SaveApp(a); err != nil {
@@ -116,6 +118,9 @@ func NewSetHttpPortCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "set http-port", Before: strconv.Itoa(previousPort), After: strconv.Itoa(port),
+			})
 			fmt.Printf("HTTP port set to %d for app %s\n", port, appName)
 		},
 	}
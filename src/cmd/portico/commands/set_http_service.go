@@ -7,6 +7,7 @@ import (
 
 	"github.com/maxvegac/portico/src/internal/app"
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 	"github.com/maxvegac/portico/src/internal/proxy"
 )
 
@@ -69,6 +70,7 @@ func NewSetHttpServiceCmd() *cobra.Command {
 			}
 
 			// Set this service as HTTP service
+			previousPort := a.Port
 			a.Port = httpPort
 
 			// Save app configuration (this regenerates docker-compose.yml with updated services)
@@ -89,6 +91,9 @@ func NewSetHttpServiceCmd() *cobra.Command {
 				return
 			}
 
+			journal.Record(journal.Event{
+				App: appName, Action: "set http-service", Summary: serviceName, Before: fmt.Sprintf("port %d", previousPort), After: fmt.Sprintf("port %d", a.Port),
+			})
 			fmt.Printf("HTTP service set to '%s' (internal port: %d) for app %s\n", serviceName, a.Port, appName)
 		},
 	}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/proxy"
+)
+
+// lbPolicies maps accepted policy names to Caddy lb_policy values
+var lbPolicies = map[string]string{
+	"round_robin": "round_robin",
+	"least_conn":  "least_conn",
+	"ip_hash":     "ip_hash",
+	"cookie":      "cookie",
+	"sticky":      "cookie", // Sticky sessions via cookie
+}
+
+// NewSetLBPolicyCmd sets the load balancing policy Caddy uses across service replicas
+func NewSetLBPolicyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lb-policy [policy]",
+		Short: "Set load balancing policy across replicas",
+		Long: `Set how Caddy distributes requests across the replicas of the HTTP service.
+
+Policies:
+  round_robin   - Each replica in turn (default when scaled)
+  least_conn    - Replica with the fewest active requests
+  ip_hash       - Same client IP always goes to the same replica
+  cookie        - Sticky sessions using a cookie (alias: sticky)
+  default       - Remove the setting and use Caddy's default
+
+Examples:
+  portico set my-app lb-policy least_conn
+  portico set my-app lb-policy sticky`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command
+			appName, err := getAppNameFromSetArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico set <app-name> lb-policy <policy>")
+				return
+			}
+
+			policy := ""
+			if args[0] != "default" {
+				var ok bool
+				policy, ok = lbPolicies[args[0]]
+				if !ok {
+					fmt.Printf("Error: unknown load balancing policy %s\n", args[0])
+					fmt.Println("Available policies: round_robin, least_conn, ip_hash, cookie (sticky), default")
+					return
+				}
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			dm := docker.NewManager(cfg.Registry.URL)
+			previous := "default"
+			err = dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+				if metadata.Proxy == nil {
+					metadata.Proxy = &docker.ProxyConfig{}
+				}
+				if metadata.Proxy.LBPolicy != "" {
+					previous = metadata.Proxy.LBPolicy
+				}
+				metadata.Proxy.LBPolicy = policy
+				if metadata.Proxy.LBPolicy == "" && metadata.Proxy.HealthCheck == nil {
+					metadata.Proxy = nil
+				}
+			})
+			if err != nil {
+				fmt.Printf("Error updating app metadata: %v\n", err)
+				return
+			}
+
+			// Update Caddyfile
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			if err := am.CreateDefaultCaddyfile(appName); err != nil {
+				fmt.Printf("Warning: could not update Caddyfile: %v\n", err)
+			}
+
+			pm := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
+			if err := pm.UpdateCaddyfile(cfg.AppsDir); err != nil {
+				fmt.Printf("Error updating proxy Caddyfile: %v\n", err)
+				return
+			}
+
+			current := policy
+			if current == "" {
+				current = "default"
+			}
+			journal.Record(journal.Event{App: appName, Action: "set lb-policy", Before: previous, After: current})
+
+			if policy == "" {
+				fmt.Printf("Load balancing policy reset to default for app %s\n", appName)
+				return
+			}
+			fmt.Printf("Load balancing policy set to %s for app %s\n", policy, appName)
+		},
+	}
+}
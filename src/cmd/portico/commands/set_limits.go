@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/proxy"
+)
+
+// bodySizePattern matches Caddy size values (e.g. 512KB, 10MB, 1GiB)
+var bodySizePattern = regexp.MustCompile(`^(?i)[0-9]+(\.[0-9]+)?(b|kb|mb|gb|kib|mib|gib)?$`)
+
+// NewSetLimitsCmd configures request limits (body size, timeout, rate limit) for an app or route
+func NewSetLimitsCmd() *cobra.Command {
+	var path string
+	var maxBody string
+	var timeout string
+	var rate string
+
+	cmd := &cobra.Command{
+		Use:   "limits [off]",
+		Short: "Set request limits (body size, timeout, rate limit)",
+		Long: `Set request limits enforced by Caddy for the whole app or for a route.
+
+Limits:
+  --max-body   Maximum request body size (e.g. 10MB)
+  --timeout    Time allowed for the backend to respond (e.g. 30s)
+  --rate       Requests allowed per client IP and window (e.g. 100/1m)
+
+Use --path to limit a single route (Caddy path pattern, e.g. /api/*).
+Pass "off" as a value to remove a single limit, or "limits off" to remove
+all limits of the app (or of the route given with --path).
+Run without flags to show the current limits.
+
+Rate limiting requires the Portico Caddy build (reverse-proxy/Dockerfile):
+--rate is refused while the running proxy lacks the rate_limit module.
+
+Examples:
+  portico set my-app limits --max-body 10MB --timeout 30s --rate 300/1m
+  portico set my-app limits --path /api/* --rate 60/1m
+  portico set my-app limits --path /upload/* --max-body 200MB --timeout 5m
+  portico set my-app limits --rate off
+  portico set my-app limits off --path /api/*`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Get app-name from parent command
+			appName, err := getAppNameFromSetArgs(cmd)
+			if err != nil || appName == "" {
+				fmt.Println("Error: app-name is required")
+				fmt.Println("Usage: portico set <app-name> limits [off] [--path /api/*] [--max-body 10MB] [--timeout 30s] [--rate 100/1m]")
+				return
+			}
+
+			removeRule := len(args) == 1 && args[0] == "off"
+			if len(args) == 1 && !removeRule {
+				fmt.Printf("Error: unknown argument %s\n", args[0])
+				return
+			}
+
+			if path != "" {
+				if err := app.ValidateLimitPath(path); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+
+			// Validate limits
+			if maxBody != "" && maxBody != "off" && !bodySizePattern.MatchString(maxBody) {
+				fmt.Printf("Error: invalid body size %s (use e.g. 512KB, 10MB)\n", maxBody)
+				return
+			}
+			if timeout != "" && timeout != "off" {
+				if _, err := time.ParseDuration(timeout); err != nil {
+					fmt.Printf("Error: invalid timeout %s\n", timeout)
+					return
+				}
+			}
+			var rateLimit *docker.RateLimit
+			if rate != "" && rate != "off" {
+				rateLimit, err = parseRateLimit(rate)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			appDir := filepath.Join(cfg.AppsDir, appName)
+			dm := docker.NewManager(cfg.Registry.URL)
+			pm := proxy.NewCaddyManager(cfg.ProxyDir, cfg.TemplatesDir)
+
+			// Stock Caddy rejects rate_limit, which would break the whole Caddyfile
+			if rateLimit != nil {
+				ok, err := pm.HasModule("http.handlers.rate_limit")
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				if !ok {
+					fmt.Println("Error: the reverse proxy has no rate_limit module; rebuild it with the Portico Caddy build first:")
+					fmt.Printf("  cd %s && docker compose up -d --build\n", cfg.ProxyDir)
+					return
+				}
+			}
+
+			// Without changes, show current limits
+			if !removeRule && maxBody == "" && timeout == "" && rate == "" {
+				metadata, err := dm.GetPorticoMetadata(appDir)
+				if err != nil {
+					fmt.Printf("Error loading app metadata: %v\n", err)
+					return
+				}
+				if len(metadata.Limits) == 0 {
+					fmt.Printf("No limits configured for app %s\n", appName)
+					return
+				}
+				fmt.Printf("Limits for app %s:\n", appName)
+				for _, rule := range metadata.Limits {
+					fmt.Printf("  %s\n", formatLimitRule(rule))
+				}
+				return
+			}
+
+			var updated docker.LimitRule
+			previous := ""
+			err = dm.UpdatePorticoMetadata(appDir, func(metadata *docker.PorticoMetadata) {
+				// Find the rule for this path
+				index := -1
+				for i, rule := range metadata.Limits {
+					if rule.Path == path {
+						index = i
+						break
+					}
+				}
+				rule := docker.LimitRule{Path: path}
+				if index >= 0 {
+					rule = metadata.Limits[index]
+					previous = formatLimitRule(rule)
+				}
+
+				if !removeRule {
+					switch maxBody {
+					case "":
+					case "off":
+						rule.MaxBodySize = ""
+					default:
+						rule.MaxBodySize = strings.ToUpper(maxBody)
+					}
+					switch timeout {
+					case "":
+					case "off":
+						rule.Timeout = ""
+					default:
+						rule.Timeout = timeout
+					}
+					switch rate {
+					case "":
+					case "off":
+						rule.RateLimit = nil
+					default:
+						rule.RateLimit = rateLimit
+					}
+				}
+				updated = rule
+
+				switch {
+				case removeRule || rule.IsEmpty():
+					if index >= 0 {
+						metadata.Limits = append(metadata.Limits[:index], metadata.Limits[index+1:]...)
+					}
+				case index >= 0:
+					metadata.Limits[index] = rule
+				default:
+					metadata.Limits = append(metadata.Limits, rule)
+				}
+			})
+			if err != nil {
+				fmt.Printf("Error updating app metadata: %v\n", err)
+				return
+			}
+
+			// Update Caddyfile
+			am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+			if err := am.CreateDefaultCaddyfile(appName); err != nil {
+				fmt.Printf("Warning: could not update Caddyfile: %v\n", err)
+			}
+
+			if err := pm.UpdateCaddyfile(cfg.AppsDir); err != nil {
+				fmt.Printf("Error updating proxy Caddyfile: %v\n", err)
+				return
+			}
+
+			current := ""
+			if !removeRule && !updated.IsEmpty() {
+				current = formatLimitRule(updated)
+			}
+			journal.Record(journal.Event{App: appName, Action: "set limits", Summary: limitScope(path), Before: previous, After: current})
+
+			if removeRule || updated.IsEmpty() {
+				fmt.Printf("Limits removed for app %s (%s)\n", appName, limitScope(path))
+				return
+			}
+			fmt.Printf("Limits updated for app %s: %s\n", appName, formatLimitRule(updated))
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Route the limits apply to (default: whole app)")
+	cmd.Flags().StringVar(&maxBody, "max-body", "", "Maximum request body size (e.g. 10MB, off)")
+	cmd.Flags().StringVar(&timeout, "timeout", "", "Backend response timeout (e.g. 30s, off)")
+	cmd.Flags().StringVar(&rate, "rate", "", "Requests per client IP and window (e.g. 100/1m, off)")
+
+	return cmd
+}
+
+// parseRateLimit parses a rate like 100/1m (also accepts 100/m, 10/s)
+func parseRateLimit(value string) (*docker.RateLimit, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid rate %s (use requests/window, e.g. 100/1m)", value)
+	}
+
+	events, err := strconv.Atoi(parts[0])
+	if err != nil || events <= 0 {
+		return nil, fmt.Errorf("invalid number of requests in rate %s", value)
+	}
+
+	window := parts[1]
+	if window == "s" || window == "m" || window == "h" {
+		window = "1" + window
+	}
+	if d, err := time.ParseDuration(window); err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid window in rate %s", value)
+	}
+
+	return &docker.RateLimit{Events: events, Window: window}, nil
+}
+
+// limitScope describes what a limit rule applies to
+func limitScope(path string) string {
+	if path == "" {
+		return "whole app"
+	}
+	return path
+}
+
+// formatLimitRule returns a one-line description of a limit rule
+func formatLimitRule(rule docker.LimitRule) string {
+	var parts []string
+	if rule.MaxBodySize != "" {
+		parts = append(parts, "max body "+rule.MaxBodySize)
+	}
+	if rule.Timeout != "" {
+		parts = append(parts, "timeout "+rule.Timeout)
+	}
+	if rule.RateLimit != nil {
+		parts = append(parts, fmt.Sprintf("rate %d/%s per IP", rule.RateLimit.Events, rule.RateLimit.Window))
+	}
+	return fmt.Sprintf("%s: %s", limitScope(rule.Path), strings.Join(parts, ", "))
+}
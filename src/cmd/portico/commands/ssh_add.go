@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewSSHAddCmd adds an SSH public key
@@ -156,6 +157,9 @@ Examples:
 				return
 			}
 
+			journal.Record(journal.Event{
+				Action: "ssh add", Summary: keyName,
+			})
 			fmt.Printf("✅ SSH key added successfully (name: %s)\n", keyName)
 		},
 	}
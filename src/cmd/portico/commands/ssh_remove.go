@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // NewSSHRemoveCmd removes an SSH public key
@@ -101,6 +102,11 @@ Examples:
 				}
 			}
 
+			var removed []string
+			for _, idx := range keysToRemove {
+				removed = append(removed, sshKeyLabel(lines[idx]))
+			}
+
 			// Remove keys (in reverse order to maintain indices)
 			for i := len(keysToRemove) - 1; i >= 0; i-- {
 				idx := keysToRemove[i]
@@ -113,9 +119,30 @@ Examples:
 				return
 			}
 
+			journal.Record(journal.Event{
+				Action: "ssh remove", Summary: strings.Join(removed, ", "),
+			})
 			fmt.Printf("✅ Removed %d SSH key(s)\n", len(keysToRemove))
 		},
 	}
 
 	return cmd
 }
+
+// sshKeyLabel returns the name of an authorized_keys line (its comment), or
+// the key type and the start of the key if it has none
+func sshKeyLabel(line string) string {
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		if strings.HasPrefix(field, "ssh-") || strings.HasPrefix(field, "ecdsa-") || strings.HasPrefix(field, "sk-") {
+			if i+2 < len(fields) {
+				return strings.Join(fields[i+2:], " ")
+			}
+			if i+1 < len(fields) && len(fields[i+1]) > 16 {
+				return field + " " + fields[i+1][:16] + "..."
+			}
+			return field
+		}
+	}
+	return "unknown key"
+}
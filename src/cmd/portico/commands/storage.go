@@ -7,6 +7,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// storageCommands lists the subcommands of storage
+var storageCommands = map[string]bool{
+	"add":    true,
+	"delete": true,
+	"del":    true,
+	"list":   true,
+}
+
 // NewStorageCmd is the root command for volume/storage management: storage [app-name] ...
 func NewStorageCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -14,15 +22,11 @@ func NewStorageCmd() *cobra.Command {
 		Short: "Manage storage volumes",
 		Long:  "Manage storage volumes and mounts for application services.",
 		Args:  cobra.ArbitraryArgs,
+		// Flags belong to the subcommands
+		DisableFlagParsing: true,
 		Run: func(parentCmd *cobra.Command, args []string) {
 			// Parse os.Args directly to find subcommand
 			allArgs := os.Args[1:] // Skip program name
-			knownCommands := map[string]bool{
-				"add":    true,
-				"delete": true,
-				"del":    true,
-				"list":   true,
-			}
 
 			var subcommandName string
 			var subcommandIndex int
@@ -43,7 +47,7 @@ func NewStorageCmd() *cobra.Command {
 
 			// Find subcommand after "storage"
 			for i := storageIndex + 1; i < len(allArgs); i++ {
-				if knownCommands[allArgs[i]] {
+				if storageCommands[allArgs[i]] {
 					subcommandName = allArgs[i]
 					subcommandIndex = i
 					break
@@ -103,14 +107,14 @@ func getAppNameFromStorageArgs(cmd *cobra.Command) (string, error) {
 		if arg == "storage" {
 			// Next non-flag argument should be app-name
 			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if storageCommands[args[j]] {
+					break
+				}
 				// Skip if it's a flag
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
-				if args[j] == "add" || args[j] == "delete" || args[j] == "list" {
-					continue
-				}
 				// This should be the app-name
 				return args[j], nil
 			}
@@ -130,14 +134,14 @@ func getServiceNameFromStorageArgs(cmd *cobra.Command) (string, error) {
 			// Find app-name first
 			appNameFound := false
 			for j := i + 1; j < len(args); j++ {
+				// App-name and service-name come before the subcommand
+				if storageCommands[args[j]] {
+					break
+				}
 				// Skip if it's a flag
 				if args[j][0] == '-' {
 					continue
 				}
-				// Skip known subcommands
-				if args[j] == "add" || args[j] == "delete" || args[j] == "del" || args[j] == "list" {
-					continue
-				}
 				if !appNameFound {
 					appNameFound = true
 					continue
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/accesslog"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// TrafficReport is the traffic of an app, as printed by 'portico traffic --json'
+type TrafficReport struct {
+	App        string            `json:"app"`
+	Since      string            `json:"since"` // RFC3339
+	Requests   int               `json:"requests"`
+	Classes    map[string]int    `json:"classes"`     // By status class
+	LatencyP50 float64           `json:"latency_p50"` // Seconds
+	LatencyP95 float64           `json:"latency_p95"`
+	LatencyP99 float64           `json:"latency_p99"`
+	BytesSent  uint64            `json:"bytes_sent"`
+	TopPaths   []accesslog.Count `json:"top_paths"`
+	TopClients []accesslog.Count `json:"top_clients"`
+}
+
+// NewTrafficCmd creates the traffic command
+func NewTrafficCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "traffic [app-name]",
+		Short: "Summarize the HTTP traffic of an application",
+		Long: `Summarize the requests of an application from its Caddy access log: requests per
+status class, p50/p95/p99 latency, bytes served, and the top paths and client IPs.
+
+The access logs (logs/apps/<app>.log) are rolled at 100MiB and the rolled files
+kept for 30 days; they are read too, so --since may go back that far.
+
+Examples:
+  portico traffic my-app
+  portico traffic my-app --since 1h
+  portico traffic my-app --since 7d --top 20 --json`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			appName := args[0]
+			sinceFlag, _ := cmd.Flags().GetString("since")
+			topN, _ := cmd.Flags().GetInt("top")
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			window, err := parseSince(sinceFlag)
+			if err != nil {
+				fmt.Printf("Error: invalid --since %q (e.g. 30m, 1h, 7d)\n", sinceFlag)
+				return
+			}
+			since := time.Now().Add(-window)
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			summary := accesslog.NewSummary()
+			if err := accesslog.Read(accessLogsDir(cfg), appName, since, summary.Add); err != nil {
+				fmt.Printf("Error reading access log: %v\n", err)
+				return
+			}
+
+			report := TrafficReport{
+				App:        appName,
+				Since:      since.UTC().Format(time.RFC3339),
+				Requests:   summary.Requests,
+				Classes:    summary.Classes,
+				LatencyP50: summary.Percentile(50),
+				LatencyP95: summary.Percentile(95),
+				LatencyP99: summary.Percentile(99),
+				BytesSent:  summary.BytesSent,
+				TopPaths:   summary.TopPaths(topN),
+				TopClients: summary.TopClients(topN),
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					fmt.Printf("Error encoding JSON: %v\n", err)
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			if report.Requests == 0 {
+				fmt.Printf("No requests for %s in the last %s\n", appName, sinceFlag)
+				return
+			}
+
+			fmt.Printf("Traffic of %s in the last %s\n\n", appName, sinceFlag)
+			fmt.Printf("Requests:  %d\n", report.Requests)
+			var classes []string
+			for _, class := range []string{"2xx", "3xx", "4xx", "5xx", "other"} {
+				if n := report.Classes[class]; n > 0 {
+					classes = append(classes, fmt.Sprintf("%s %d (%.1f%%)", class, n, 100*float64(n)/float64(report.Requests)))
+				}
+			}
+			fmt.Printf("Status:    %s\n", strings.Join(classes, ", "))
+			fmt.Printf("Latency:   p50 %s, p95 %s, p99 %s\n",
+				formatLatency(report.LatencyP50), formatLatency(report.LatencyP95), formatLatency(report.LatencyP99))
+			fmt.Printf("Served:    %s\n", util.FormatBytes(report.BytesSent))
+
+			printTrafficTop("PATH", report.TopPaths)
+			printTrafficTop("CLIENT IP", report.TopClients)
+		},
+	}
+
+	cmd.Flags().String("since", "24h", "Time window to summarize (e.g. 30m, 1h, 7d)")
+	cmd.Flags().Int("top", 10, "Number of top paths and client IPs to show")
+	cmd.Flags().Bool("json", false, "Print the summary as JSON")
+
+	return cmd
+}
+
+// parseSince parses a time window: a Go duration, or a number of days (7d)
+func parseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid days")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration")
+	}
+	return d, nil
+}
+
+// formatLatency formats a duration in seconds (e.g. 12ms, 1.25s)
+func formatLatency(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
+
+// printTrafficTop prints a top list of the traffic summary
+func printTrafficTop(title string, counts []accesslog.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%-50s %s\n", title, "REQUESTS")
+	for _, c := range counts {
+		fmt.Printf("%-50s %d\n", c.Value, c.Requests)
+	}
+}
@@ -0,0 +1,823 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/tui"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// tuiRefreshInterval is how often the status shown is refreshed
+const tuiRefreshInterval = 5 * time.Second
+
+// tuiLogLines is how many log lines the log view keeps
+const tuiLogLines = 2000
+
+// tuiView is a screen of the terminal UI
+type tuiView int
+
+const (
+	tuiViewList   tuiView = iota // Apps and addon instances
+	tuiViewDetail                // Services and containers of an app or addon instance
+	tuiViewText                  // Output of a command (env, secrets, actions)
+	tuiViewLogs                  // Log tail
+)
+
+// tuiPrompt asks for a value on the bottom line
+type tuiPrompt struct {
+	label    string
+	input    string
+	onSubmit func(value string)
+}
+
+// tuiLogs is a running log tail
+type tuiLogs struct {
+	title  string
+	cmd    *exec.Cmd
+	mu     sync.Mutex
+	lines  []string
+	follow bool
+	scroll int // First line shown when not following
+}
+
+// tuiModel is the state of the terminal UI. It is only used by the UI loop;
+// background work sends functions that update it on the updates channel
+type tuiModel struct {
+	cfg  *config.Config
+	term *tui.Terminal
+
+	view     tuiView
+	entries  []*OverviewEntry
+	selected int
+	updated  time.Time
+
+	detailKind     string // app, addon
+	detailName     string
+	detail         *AppStatus
+	detailSelected int // Service
+
+	textTitle string
+	text      []string
+	textBack  tuiView
+	scroll    int
+
+	logs *tuiLogs
+
+	prompt     *tuiPrompt
+	message    string
+	busy       bool // A job is running
+	refreshing bool
+
+	work    chan func()          // Background jobs, run one at a time
+	updates chan func(*tuiModel) // Results of the jobs
+	logLine chan struct{}        // New log lines
+	quit    bool
+}
+
+// NewTuiCmd creates the terminal UI command
+func NewTuiCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tui",
+		Short: "Full-screen terminal interface for apps and addon instances",
+		Long: `Full-screen terminal interface listing apps and addon instances with live status.
+
+From the list or an app, you can tail logs, restart, scale and redeploy services,
+and browse environment variables and secrets (values masked as in 'env list').
+Actions run the same commands as the CLI (e.g. redeploy runs 'portico up <app>').
+
+Keys:
+  ↑/↓ or j/k  select          enter  open app / addon instance
+  l  logs     r  restart      s  scale service     d  redeploy app
+  e  env      x  secrets      esc  back            q  quit`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Printf("Error loading config: %v\n", err)
+				return
+			}
+
+			term, err := tui.Open()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			defer term.Close()
+
+			m := &tuiModel{
+				cfg:     cfg,
+				term:    term,
+				work:    make(chan func(), 16),
+				updates: make(chan func(*tuiModel), 16),
+				logLine: make(chan struct{}, 1),
+				message: "Loading...",
+			}
+			m.run()
+		},
+	}
+}
+
+// run is the UI loop: it handles keys and updates and redraws the screen
+func (m *tuiModel) run() {
+	// Background jobs run one at a time: loading apps reads config.yml, which
+	// is not safe concurrently
+	go func() {
+		for job := range m.work {
+			job()
+		}
+	}()
+	defer close(m.work)
+	defer m.stopLogs()
+
+	keys := m.term.Keys()
+	ticker := time.NewTicker(tuiRefreshInterval)
+	defer ticker.Stop()
+
+	m.refresh()
+	m.draw()
+	for !m.quit {
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return
+			}
+			m.handleKey(key)
+		case update := <-m.updates:
+			update(m)
+		case <-m.logLine:
+		case <-ticker.C:
+			m.refresh()
+		case <-m.term.Resized():
+		}
+		m.draw()
+	}
+}
+
+// submit queues a background job; its result updates the model in the UI loop
+func (m *tuiModel) submit(job func() func(*tuiModel)) {
+	m.work <- func() {
+		m.updates <- job()
+	}
+}
+
+// refresh reloads the data of the current view in the background
+func (m *tuiModel) refresh() {
+	if m.refreshing || m.busy {
+		return
+	}
+	m.refreshing = true
+	cfg := m.cfg
+	switch m.view {
+	case tuiViewList:
+		m.submit(func() func(*tuiModel) {
+			entries, err := getOverview(cfg)
+			return func(m *tuiModel) {
+				m.refreshing = false
+				if err != nil {
+					m.message = err.Error()
+					return
+				}
+				m.entries = entries
+				m.updated = time.Now()
+				if m.message == "Loading..." {
+					m.message = ""
+				}
+				if m.selected >= len(m.entries) {
+					m.selected = len(m.entries) - 1
+				}
+				if m.selected < 0 {
+					m.selected = 0
+				}
+			}
+		})
+	case tuiViewDetail:
+		kind, name := m.detailKind, m.detailName
+		m.submit(func() func(*tuiModel) {
+			status, err := loadTuiDetail(cfg, kind, name)
+			return func(m *tuiModel) {
+				m.refreshing = false
+				if err != nil {
+					m.message = err.Error()
+					return
+				}
+				if m.detailKind == kind && m.detailName == name {
+					m.detail = status
+					m.updated = time.Now()
+					if m.detailSelected >= len(status.Services) {
+						m.detailSelected = len(status.Services) - 1
+					}
+					if m.detailSelected < 0 {
+						m.detailSelected = 0
+					}
+				}
+			}
+		})
+	default:
+		m.refreshing = false
+	}
+}
+
+// loadTuiDetail returns the status of an app, or of an addon instance as an
+// app with one service per compose service
+func loadTuiDetail(cfg *config.Config, kind, name string) (*AppStatus, error) {
+	if kind == "app" {
+		am := app.NewManager(cfg.AppsDir, cfg.TemplatesDir)
+		a, err := am.LoadApp(name)
+		if err != nil {
+			return nil, err
+		}
+		return getAppStatus(cfg, a, true)
+	}
+
+	instanceDir := filepath.Join(cfg.AddonsDir, "instances", name)
+	dm := docker.NewManager(cfg.Registry.URL)
+	compose, err := dm.LoadComposeFile(instanceDir)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := dm.GetContainerStatus(instanceDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := dm.AddContainerStats(containers); err != nil {
+		return nil, err
+	}
+	status := &AppStatus{App: name, Services: []ServiceStatus{}}
+	for svcName, svc := range compose.Services {
+		s := ServiceStatus{Name: svcName, Replicas: 1, Containers: []docker.ContainerStatus{}}
+		if svcMap, ok := svc.(map[string]interface{}); ok {
+			s.Image, _ = svcMap["image"].(string)
+		}
+		for _, c := range containers {
+			if c.Service == svcName {
+				s.Containers = append(s.Containers, c)
+				if c.Running() {
+					s.Running++
+				}
+			}
+		}
+		status.Services = append(status.Services, s)
+	}
+	sort.Slice(status.Services, func(i, j int) bool {
+		return status.Services[i].Name < status.Services[j].Name
+	})
+	return status, nil
+}
+
+// handleKey handles a key press in the current view
+func (m *tuiModel) handleKey(key tui.Key) {
+	if key.Code == tui.KeyCtrlC {
+		m.quit = true
+		return
+	}
+	if m.prompt != nil {
+		m.handlePromptKey(key)
+		return
+	}
+
+	switch m.view {
+	case tuiViewList:
+		m.handleListKey(key)
+	case tuiViewDetail:
+		m.handleDetailKey(key)
+	case tuiViewText:
+		m.handleTextKey(key)
+	case tuiViewLogs:
+		m.handleLogsKey(key)
+	}
+}
+
+// handlePromptKey edits the value of the prompt
+func (m *tuiModel) handlePromptKey(key tui.Key) {
+	switch key.Code {
+	case tui.KeyEsc:
+		m.prompt = nil
+		m.message = "Cancelled"
+	case tui.KeyEnter:
+		prompt := m.prompt
+		m.prompt = nil
+		prompt.onSubmit(strings.TrimSpace(prompt.input))
+	case tui.KeyBackspace:
+		if runes := []rune(m.prompt.input); len(runes) > 0 {
+			m.prompt.input = string(runes[:len(runes)-1])
+		}
+	case tui.KeyRune:
+		m.prompt.input += string(key.Rune)
+	}
+}
+
+// handleListKey handles keys in the list of apps and addon instances
+func (m *tuiModel) handleListKey(key tui.Key) {
+	var e *OverviewEntry
+	if m.selected < len(m.entries) {
+		e = m.entries[m.selected]
+	}
+
+	switch {
+	case key.Code == tui.KeyRune && key.Rune == 'q', key.Code == tui.KeyEsc:
+		m.quit = true
+	case key.Code == tui.KeyUp || key.Code == tui.KeyRune && key.Rune == 'k':
+		if m.selected > 0 {
+			m.selected--
+		}
+	case key.Code == tui.KeyDown || key.Code == tui.KeyRune && key.Rune == 'j':
+		if m.selected < len(m.entries)-1 {
+			m.selected++
+		}
+	case key.Code == tui.KeyHome:
+		m.selected = 0
+	case key.Code == tui.KeyEnd:
+		m.selected = len(m.entries) - 1
+	case e == nil:
+		return
+	case key.Code == tui.KeyEnter:
+		if e.Kind == "static" {
+			m.message = fmt.Sprintf("%s is a static site served by Caddy, it has no containers", e.Name)
+			return
+		}
+		m.openDetail(e.Kind, e.Name)
+	case key.Code == tui.KeyRune:
+		kind := e.Kind
+		if kind == "static" {
+			kind = "app"
+		}
+		m.handleAction(key.Rune, kind, e.Name, "")
+	}
+}
+
+// openDetail shows the services and containers of an app or addon instance
+func (m *tuiModel) openDetail(kind, name string) {
+	m.view = tuiViewDetail
+	m.detailKind = kind
+	m.detailName = name
+	m.detail = nil
+	m.detailSelected = 0
+	m.message = "Loading..."
+	m.refreshing = false
+	m.refresh()
+}
+
+// handleDetailKey handles keys in the detail of an app or addon instance
+func (m *tuiModel) handleDetailKey(key tui.Key) {
+	services := 0
+	if m.detail != nil {
+		services = len(m.detail.Services)
+	}
+
+	switch {
+	case key.Code == tui.KeyEsc || key.Code == tui.KeyRune && key.Rune == 'q':
+		m.view = tuiViewList
+		m.message = ""
+		m.refreshing = false
+		m.refresh()
+	case key.Code == tui.KeyUp || key.Code == tui.KeyRune && key.Rune == 'k':
+		if m.detailSelected > 0 {
+			m.detailSelected--
+		}
+	case key.Code == tui.KeyDown || key.Code == tui.KeyRune && key.Rune == 'j':
+		if m.detailSelected < services-1 {
+			m.detailSelected++
+		}
+	case key.Code == tui.KeyRune:
+		service := ""
+		if m.detailSelected < services {
+			service = m.detail.Services[m.detailSelected].Name
+		}
+		m.handleAction(key.Rune, m.detailKind, m.detailName, service)
+	}
+}
+
+// handleAction runs the action of a key on an app or addon instance; service
+// is the selected service (empty in the list: the whole app)
+func (m *tuiModel) handleAction(r rune, kind, name, service string) {
+	switch r {
+	case 'l':
+		m.openLogs(kind, name, service)
+	case 'r':
+		target := name
+		if service != "" {
+			target = name + "/" + service
+		}
+		m.confirm(fmt.Sprintf("Restart %s?", target), func() {
+			m.runJob(fmt.Sprintf("Restarting %s...", target), func() (string, error) {
+				return fmt.Sprintf("Restarted %s", target), tuiRestart(m.cfg, kind, name, service)
+			})
+		})
+	case 's':
+		if kind != "app" {
+			m.message = "Addon instances can't be scaled"
+			return
+		}
+		if service == "" {
+			m.message = "Open the app (enter) and select the service to scale"
+			return
+		}
+		m.prompt = &tuiPrompt{
+			label: fmt.Sprintf("Scale %s/%s to (replicas): ", name, service),
+			onSubmit: func(value string) {
+				if n, err := strconv.Atoi(value); err != nil || n < 1 {
+					m.message = fmt.Sprintf("Invalid number of replicas: %s", value)
+					return
+				}
+				m.runCommand("service", name, service, "scale", value)
+			},
+		}
+	case 'd':
+		if kind != "app" {
+			m.message = "Redeploy is available for apps"
+			return
+		}
+		m.confirm(fmt.Sprintf("Redeploy %s?", name), func() {
+			m.runCommand("up", name)
+		})
+	case 'e':
+		if kind != "app" {
+			m.message = "Addon instances have no environment variables to browse"
+			return
+		}
+		m.runCommand("env", name, "list")
+	case 'x':
+		if kind != "app" {
+			m.message = "Addon instance secrets are managed by Portico"
+			return
+		}
+		m.runCommand("secrets", name, "list")
+	case 'q':
+		m.quit = true
+	}
+}
+
+// confirm asks for y/n before running an action
+func (m *tuiModel) confirm(question string, action func()) {
+	m.prompt = &tuiPrompt{
+		label: question + " [y/N] ",
+		onSubmit: func(value string) {
+			if strings.EqualFold(value, "y") || strings.EqualFold(value, "yes") {
+				action()
+				return
+			}
+			m.message = "Cancelled"
+		},
+	}
+}
+
+// runJob runs an action in the background and shows its result as the message
+func (m *tuiModel) runJob(message string, job func() (string, error)) {
+	if m.busy {
+		m.message = "Another action is running"
+		return
+	}
+	m.busy = true
+	m.message = message
+	m.submit(func() func(*tuiModel) {
+		result, err := job()
+		return func(m *tuiModel) {
+			m.busy = false
+			if err != nil {
+				m.message = "Error: " + strings.ReplaceAll(err.Error(), "\n", " ")
+			} else {
+				m.message = result
+			}
+			m.refreshing = false
+			m.refresh()
+		}
+	})
+}
+
+// runCommand runs a portico command and shows its output
+func (m *tuiModel) runCommand(args ...string) {
+	if m.busy {
+		m.message = "Another action is running"
+		return
+	}
+	title := "$ portico " + strings.Join(args, " ")
+	m.busy = true
+	m.message = "Running " + title[2:] + "..."
+	m.submit(func() func(*tuiModel) {
+		output, err := runPortico(args...)
+		return func(m *tuiModel) {
+			m.busy = false
+			m.message = ""
+			if err != nil {
+				m.message = "Error: " + err.Error()
+			}
+			if m.view != tuiViewText {
+				m.textBack = m.view
+			}
+			m.view = tuiViewText
+			m.textTitle = title
+			m.text = nil
+			for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+				m.text = append(m.text, tui.Plain(line))
+			}
+			m.scroll = 0
+		}
+	})
+}
+
+// runPortico runs this portico binary with args and returns its output, so
+// actions do exactly what the CLI commands do
+func runPortico(args ...string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = nil
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// tuiRestart restarts an app, a service of an app, or an addon instance
+func tuiRestart(cfg *config.Config, kind, name, service string) error {
+	dm := docker.NewManager(cfg.Registry.URL)
+	if kind == "app" {
+		appDir := filepath.Join(cfg.AppsDir, name)
+		if service != "" {
+			return dm.RestartService(appDir, service)
+		}
+		return dm.RestartApp(appDir)
+	}
+
+	// Addon instances: same as 'addons <instance> up', with restart
+	if err := materializeAddonSecrets(cfg, name); err != nil {
+		return err
+	}
+	instanceDir := filepath.Join(cfg.AddonsDir, "instances", name)
+	args := []string{"compose", "-f", filepath.Join(instanceDir, "docker-compose.yml"), "-p", name, "restart"}
+	if service != "" {
+		args = append(args, service)
+	}
+	cmd := exec.Command("docker", args...)
+	cmd.Dir = instanceDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// handleTextKey scrolls the output of a command
+func (m *tuiModel) handleTextKey(key tui.Key) {
+	_, height := m.term.Size()
+	page := height - 4
+	switch {
+	case key.Code == tui.KeyEsc || key.Code == tui.KeyRune && key.Rune == 'q':
+		m.view = m.textBack
+		m.refreshing = false
+		m.refresh()
+	case key.Code == tui.KeyUp || key.Code == tui.KeyRune && key.Rune == 'k':
+		m.scroll--
+	case key.Code == tui.KeyDown || key.Code == tui.KeyRune && key.Rune == 'j':
+		m.scroll++
+	case key.Code == tui.KeyPgUp:
+		m.scroll -= page
+	case key.Code == tui.KeyPgDown:
+		m.scroll += page
+	case key.Code == tui.KeyHome:
+		m.scroll = 0
+	case key.Code == tui.KeyEnd:
+		m.scroll = len(m.text)
+	}
+	m.scroll = clampScroll(m.scroll, len(m.text), page)
+}
+
+// openLogs starts tailing the logs of an app, a service or an addon instance
+func (m *tuiModel) openLogs(kind, name, service string) {
+	m.stopLogs()
+
+	dir := filepath.Join(m.cfg.AppsDir, name)
+	if kind == "addon" {
+		dir = filepath.Join(m.cfg.AddonsDir, "instances", name)
+	}
+	args := []string{"compose", "-f", filepath.Join(dir, "docker-compose.yml"), "-p", name, "logs", "-f", "--tail", "200", "--no-color"}
+	title := name
+	if service != "" {
+		args = append(args, service)
+		title = name + "/" + service
+	}
+
+	logs := &tuiLogs{title: title, follow: true}
+	cmd := exec.Command("docker", args...)
+	cmd.Dir = dir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		m.message = "Error: " + err.Error()
+		return
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		m.message = "Error starting docker compose logs: " + err.Error()
+		return
+	}
+	logs.cmd = cmd
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			logs.mu.Lock()
+			logs.lines = append(logs.lines, tui.Plain(scanner.Text()))
+			if len(logs.lines) > tuiLogLines {
+				logs.lines = logs.lines[len(logs.lines)-tuiLogLines:]
+			}
+			logs.mu.Unlock()
+			select {
+			case m.logLine <- struct{}{}:
+			default:
+			}
+		}
+		_ = cmd.Wait()
+	}()
+
+	if m.view != tuiViewLogs {
+		m.textBack = m.view
+	}
+	m.logs = logs
+	m.view = tuiViewLogs
+	m.message = ""
+}
+
+// stopLogs stops the log tail, if any
+func (m *tuiModel) stopLogs() {
+	if m.logs != nil && m.logs.cmd != nil && m.logs.cmd.Process != nil {
+		_ = m.logs.cmd.Process.Kill()
+	}
+	m.logs = nil
+}
+
+// handleLogsKey scrolls the logs; scrolling up stops following them
+func (m *tuiModel) handleLogsKey(key tui.Key) {
+	_, height := m.term.Size()
+	page := height - 4
+	m.logs.mu.Lock()
+	total := len(m.logs.lines)
+	m.logs.mu.Unlock()
+	if m.logs.follow {
+		m.logs.scroll = clampScroll(total, total, page)
+	}
+
+	switch {
+	case key.Code == tui.KeyEsc || key.Code == tui.KeyRune && key.Rune == 'q':
+		m.stopLogs()
+		m.view = m.textBack
+		m.refreshing = false
+		m.refresh()
+		return
+	case key.Code == tui.KeyUp || key.Code == tui.KeyRune && key.Rune == 'k':
+		m.logs.follow = false
+		m.logs.scroll--
+	case key.Code == tui.KeyDown || key.Code == tui.KeyRune && key.Rune == 'j':
+		m.logs.scroll++
+	case key.Code == tui.KeyPgUp:
+		m.logs.follow = false
+		m.logs.scroll -= page
+	case key.Code == tui.KeyPgDown:
+		m.logs.scroll += page
+	case key.Code == tui.KeyHome:
+		m.logs.follow = false
+		m.logs.scroll = 0
+	case key.Code == tui.KeyEnd || key.Code == tui.KeyRune && key.Rune == 'f':
+		m.logs.follow = true
+	}
+	m.logs.scroll = clampScroll(m.logs.scroll, total, page)
+	if m.logs.scroll >= clampScroll(total, total, page) {
+		m.logs.follow = true
+	}
+}
+
+// clampScroll keeps the first line shown within the lines
+func clampScroll(scroll, lines, page int) int {
+	if scroll > lines-page {
+		scroll = lines - page
+	}
+	if scroll < 0 {
+		scroll = 0
+	}
+	return scroll
+}
+
+// draw renders the current view
+func (m *tuiModel) draw() {
+	width, height := m.term.Size()
+	page := height - 4 // Title, blank line, message and help
+	var title, header, help string
+	var body []string
+	selected := -1
+
+	switch m.view {
+	case tuiViewList:
+		title = "Portico — apps and addon instances"
+		help = "↑↓ select  enter open  l logs  r restart  d redeploy  e env  x secrets  q quit"
+		header = overviewHeader()
+		for _, e := range m.entries {
+			body = append(body, formatOverviewRow(e))
+		}
+		if len(m.entries) > 0 {
+			selected = m.selected
+		}
+	case tuiViewDetail:
+		title = fmt.Sprintf("Portico — %s %s", m.detailKind, m.detailName)
+		help = "↑↓ select service  l logs  r restart  s scale  d redeploy  e env  x secrets  esc back"
+		if m.detailKind == "addon" {
+			help = "↑↓ select service  l logs  r restart  esc back"
+		}
+		body, selected = m.detailLines()
+	case tuiViewText:
+		title = m.textTitle
+		help = "↑↓ PgUp PgDn scroll  esc back"
+		body = m.text[clampScroll(m.scroll, len(m.text), page):]
+	case tuiViewLogs:
+		title = "Logs — " + m.logs.title
+		help = "↑↓ PgUp PgDn scroll  f follow  esc back"
+		m.logs.mu.Lock()
+		total := len(m.logs.lines)
+		if m.logs.follow {
+			m.logs.scroll = total
+			title += " (following)"
+		}
+		m.logs.scroll = clampScroll(m.logs.scroll, total, page)
+		body = append(body, m.logs.lines[m.logs.scroll:]...)
+		m.logs.mu.Unlock()
+	}
+
+	if !m.updated.IsZero() && (m.view == tuiViewList || m.view == tuiViewDetail) {
+		title += fmt.Sprintf("   updated %s", m.updated.Format("15:04:05"))
+	}
+
+	lines := []string{tui.Reverse(tui.Fit(title, width)), ""}
+	if header != "" {
+		lines = append(lines, tui.Bold(tui.Fit(header, width)))
+		page--
+	}
+
+	// Keep the selected line visible
+	offset := 0
+	if page > 0 && selected >= page {
+		offset = selected - page + 1
+	}
+	for i := offset; i < len(body) && i < offset+page; i++ {
+		line := tui.Fit(body[i], width)
+		if i == selected {
+			line = tui.Reverse(line)
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+
+	status := m.message
+	if m.prompt != nil {
+		status = m.prompt.label + m.prompt.input + "█"
+	}
+	lines = append(lines, tui.Fit(status, width), tui.Reverse(tui.Fit(help, width)))
+	m.term.Draw(lines)
+}
+
+// detailLines returns the lines of the detail view and the index of the
+// selected service line
+func (m *tuiModel) detailLines() ([]string, int) {
+	if m.detail == nil {
+		return nil, -1
+	}
+	var lines []string
+	selected := -1
+	for i, s := range m.detail.Services {
+		if i == m.detailSelected {
+			selected = len(lines)
+		}
+		lines = append(lines, fmt.Sprintf("%s (%d/%d running)  %s", s.Name, s.Running, s.Replicas, s.Image))
+		if len(s.Containers) == 0 {
+			lines = append(lines, "    ○ not running")
+		}
+		for _, c := range s.Containers {
+			icon, text := containerStateText(c)
+			line := fmt.Sprintf("    %s #%d %-28s %-24s restarts %-3d", icon, c.Replica, c.Name, text, c.RestartCount)
+			if c.Running() {
+				line += fmt.Sprintf("  CPU %5.1f%%", c.CPUPercent)
+				if c.MemoryLimit > 0 {
+					line += fmt.Sprintf("  mem %s / %s", util.FormatBytes(c.MemoryUsage), util.FormatBytes(c.MemoryLimit))
+				}
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(m.detail.Services) == 0 {
+		lines = append(lines, "No services")
+	}
+	return lines, selected
+}
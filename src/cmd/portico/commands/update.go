@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/maxvegac/portico/src/internal/journal"
 )
 
 // Constants for repeated strings
@@ -406,6 +408,9 @@ func runUpdateCommand(cmd *cobra.Command) {
 		return
 	}
 
+	journal.Record(journal.Event{
+		Action: "update", Before: currentVersion, After: latestRelease.TagName,
+	})
 	fmt.Printf("✅ Successfully updated to %s!\n", latestRelease.TagName)
 	fmt.Println("Please restart your terminal or run 'portico version' to verify the update.")
 }
@@ -54,6 +54,9 @@ func main() {
 	setCmd.AddCommand(commands.NewSetHttpServiceCmd())
 	setCmd.AddCommand(commands.NewSetHttpCmd())
 	setCmd.AddCommand(commands.NewSetExternalIPCmd())
+	setCmd.AddCommand(commands.NewSetLBPolicyCmd())
+	setCmd.AddCommand(commands.NewSetHealthcheckCmd())
+	setCmd.AddCommand(commands.NewSetLimitsCmd())
 
 	// Env commands (environment variables)
 	envCmd := commands.NewEnvCmd()
@@ -61,6 +64,8 @@ func main() {
 	envCmd.AddCommand(commands.NewEnvDeleteCmd())
 	envCmd.AddCommand(commands.NewEnvEditCmd())
 	envCmd.AddCommand(commands.NewEnvListCmd())
+	envCmd.AddCommand(commands.NewEnvImportCmd())
+	envCmd.AddCommand(commands.NewEnvExportCmd())
 
 	// Secrets commands (secret files)
 	secretsCmd := commands.NewSecretsCmd()
@@ -68,12 +73,36 @@ func main() {
 	secretsCmd.AddCommand(commands.NewSecretsDeleteCmd())
 	secretsCmd.AddCommand(commands.NewSecretsEditCmd())
 	secretsCmd.AddCommand(commands.NewSecretsListCmd())
+	secretsCmd.AddCommand(commands.NewSecretsMigrateCmd())
+	secretsCmd.AddCommand(commands.NewSecretsRekeyCmd())
+	secretsCmd.AddCommand(commands.NewSecretsMaterializeCmd())
+	secretsCmd.AddCommand(commands.NewSecretsHistoryCmd())
+	secretsCmd.AddCommand(commands.NewSecretsRollbackCmd())
+	secretsCmd.AddCommand(commands.NewSecretsGlobalCmd())
+	secretsCmd.AddCommand(commands.NewSecretsLinkGlobalCmd())
+	secretsCmd.AddCommand(commands.NewSecretsUnlinkGlobalCmd())
+	secretsCmd.AddCommand(commands.NewSecretsAuditCmd())
+	secretsCmd.AddCommand(commands.NewSecretsRotateCmd())
+	secretsCmd.AddCommand(commands.NewSecretsExpireCmd())
 
 	// Domains command
 	domainsCmd := commands.NewDomainsCmd()
 	domainsCmd.AddCommand(commands.NewDomainsAddCmd())
 	domainsCmd.AddCommand(commands.NewDomainsRemoveCmd())
 
+	// Headers command (response headers, CORS, security profiles)
+	headersCmd := commands.NewHeadersCmd()
+	headersCmd.AddCommand(commands.NewHeadersSetCmd())
+	headersCmd.AddCommand(commands.NewHeadersUnsetCmd())
+	headersCmd.AddCommand(commands.NewHeadersProfileCmd())
+	headersCmd.AddCommand(commands.NewHeadersListCmd())
+
+	// Config commands (staged config changes)
+	configCmd := commands.NewConfigCmd()
+	configCmd.AddCommand(commands.NewConfigStageCmd())
+	configCmd.AddCommand(commands.NewConfigPendingCmd())
+	configCmd.AddCommand(commands.NewConfigCommitCmd())
+
 	// Ports commands (port mappings)
 	portsCmd := commands.NewPortsCmd()
 	portsCmd.AddCommand(commands.NewPortsAddCmd())
@@ -86,6 +115,12 @@ func main() {
 	storageCmd.AddCommand(commands.NewStorageDeleteCmd())
 	storageCmd.AddCommand(commands.NewStorageListCmd())
 
+	// Resources commands (CPU and memory limits)
+	resourcesCmd := commands.NewResourcesCmd()
+	resourcesCmd.AddCommand(commands.NewResourcesSetCmd())
+	resourcesCmd.AddCommand(commands.NewResourcesUnsetCmd())
+	resourcesCmd.AddCommand(commands.NewResourcesListCmd())
+
 	// Add flags to update command
 	updateCmd.Flags().Bool("dev", false, "Check for development releases instead of stable releases")
 	checkUpdateCmd.Flags().Bool("dev", false, "Check for development releases instead of stable releases")
@@ -111,12 +146,23 @@ func main() {
 	rootCmd.AddCommand(execCmd)
 	rootCmd.AddCommand(shellCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(commands.NewAppsDeploysCmd())
+	rootCmd.AddCommand(commands.NewPsCmd())
+	rootCmd.AddCommand(commands.NewTuiCmd())
+	rootCmd.AddCommand(commands.NewMetricsCmd())
+	rootCmd.AddCommand(commands.NewNotifyCmd())
+	rootCmd.AddCommand(commands.NewTrafficCmd())
+	rootCmd.AddCommand(commands.NewEventsCmd())
+	rootCmd.AddCommand(commands.NewDaemonCmd())
 	rootCmd.AddCommand(setCmd)
 	rootCmd.AddCommand(envCmd)
 	rootCmd.AddCommand(secretsCmd)
 	rootCmd.AddCommand(domainsCmd)
+	rootCmd.AddCommand(headersCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(portsCmd)
 	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(resourcesCmd)
 
 	// Addons commands
 	addonsCmd := commands.NewAddonsCmd()
@@ -0,0 +1,163 @@
+// Package accesslog reads the Caddy access logs of the apps
+// (logs/apps/<app>.log, JSON), including the files rolled by Caddy
+package accesslog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is a request of a Caddy access log
+type Entry struct {
+	Time     time.Time
+	Method   string
+	Host     string
+	Path     string // Without the query string
+	ClientIP string
+	Status   int
+	Duration float64 // Seconds
+	Size     uint64  // Response body bytes
+}
+
+// line holds the fields of a Caddy access log line
+type line struct {
+	TS       float64 `json:"ts"` // Unix seconds
+	Msg      string  `json:"msg"`
+	Duration float64 `json:"duration"`
+	Size     uint64  `json:"size"`
+	Status   int     `json:"status"`
+	Request  struct {
+		RemoteIP string `json:"remote_ip"`
+		ClientIP string `json:"client_ip"`
+		Method   string `json:"method"`
+		Host     string `json:"host"`
+		URI      string `json:"uri"`
+	} `json:"request"`
+}
+
+// Parse returns the request of an access log line. Returns false for lines
+// that are not requests (or not JSON)
+func Parse(data []byte) (Entry, bool) {
+	var l line
+	if err := json.Unmarshal(data, &l); err != nil {
+		return Entry{}, false
+	}
+	if l.Msg != "handled request" || l.Status == 0 {
+		return Entry{}, false
+	}
+
+	entry := Entry{
+		Method:   l.Request.Method,
+		Host:     l.Request.Host,
+		ClientIP: l.Request.ClientIP,
+		Status:   l.Status,
+		Duration: l.Duration,
+		Size:     l.Size,
+	}
+	if entry.ClientIP == "" {
+		entry.ClientIP = l.Request.RemoteIP
+	}
+	entry.Path, _, _ = strings.Cut(l.Request.URI, "?")
+	if l.TS > 0 {
+		sec := int64(l.TS)
+		entry.Time = time.Unix(sec, int64((l.TS-float64(sec))*1e9))
+	}
+	return entry, true
+}
+
+// rolledName matches the files Caddy rolls an access log to:
+// <app>-2006-01-02T15-04-05.000.log, gzipped by default
+var rolledName = regexp.MustCompile(`^-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}(\.\d+)?\.log(\.gz)?$`)
+
+// Files returns the access log files of an app in dir, oldest first: the
+// rolled files, then the current log. Returns nil if the app has no log
+func Files(dir, app string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var rolled []string
+	current := ""
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, app) {
+			continue
+		}
+		if name == app+".log" {
+			current = filepath.Join(dir, name)
+		} else if rolledName.MatchString(strings.TrimPrefix(name, app)) {
+			rolled = append(rolled, filepath.Join(dir, name))
+		}
+	}
+	// The timestamp in the name sorts them by age
+	sort.Strings(rolled)
+	if current != "" {
+		rolled = append(rolled, current)
+	}
+	return rolled, nil
+}
+
+// Read calls fn for each request of the access logs of an app since a time
+// (zero: all), oldest first. Rolled files last written before since are skipped
+func Read(dir, app string, since time.Time, fn func(Entry)) error {
+	files, err := Files(dir, app)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if !since.IsZero() {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(since) {
+				continue
+			}
+		}
+		if err := readFile(path, since, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile calls fn for each request of an access log file since a time
+func readFile(path string, since time.Time, fn func(Entry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Rolled while reading
+		}
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry, ok := Parse(scanner.Bytes())
+		if !ok || (!since.IsZero() && entry.Time.Before(since)) {
+			continue
+		}
+		fn(entry)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,109 @@
+package accesslog
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary sums up the requests of an app
+type Summary struct {
+	Requests  int
+	Classes   map[string]int // By status class: 2xx, 3xx, 4xx, 5xx
+	BytesSent uint64
+	First     time.Time // First and last request
+	Last      time.Time
+
+	durations []float64
+	paths     map[string]int
+	clients   map[string]int
+}
+
+// Count is a value and how many requests have it
+type Count struct {
+	Value    string `json:"value"`
+	Requests int    `json:"requests"`
+}
+
+// NewSummary returns an empty summary
+func NewSummary() *Summary {
+	return &Summary{
+		Classes: make(map[string]int),
+		paths:   make(map[string]int),
+		clients: make(map[string]int),
+	}
+}
+
+// Add adds a request to the summary
+func (s *Summary) Add(e Entry) {
+	s.Requests++
+	s.Classes[StatusClass(e.Status)]++
+	s.BytesSent += e.Size
+	s.durations = append(s.durations, e.Duration)
+	if e.Path != "" {
+		s.paths[e.Path]++
+	}
+	if e.ClientIP != "" {
+		s.clients[e.ClientIP]++
+	}
+	if !e.Time.IsZero() {
+		if s.First.IsZero() || e.Time.Before(s.First) {
+			s.First = e.Time
+		}
+		if e.Time.After(s.Last) {
+			s.Last = e.Time
+		}
+	}
+}
+
+// Percentile returns the duration (seconds) under which p percent of the
+// requests took, nearest-rank
+func (s *Summary) Percentile(p float64) float64 {
+	if len(s.durations) == 0 {
+		return 0
+	}
+	if !sort.Float64sAreSorted(s.durations) {
+		sort.Float64s(s.durations)
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(s.durations))))
+	if rank < 1 {
+		rank = 1
+	}
+	return s.durations[rank-1]
+}
+
+// TopPaths returns the n most requested paths
+func (s *Summary) TopPaths(n int) []Count {
+	return top(s.paths, n)
+}
+
+// TopClients returns the n client IPs with the most requests
+func (s *Summary) TopClients(n int) []Count {
+	return top(s.clients, n)
+}
+
+// StatusClass returns the class of a status code, e.g. 4xx
+func StatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "other"
+	}
+	return string(rune('0'+status/100)) + "xx"
+}
+
+// top returns the n values with the most requests, then by value
+func top(counts map[string]int, n int) []Count {
+	list := make([]Count, 0, len(counts))
+	for value, requests := range counts {
+		list = append(list, Count{Value: value, Requests: requests})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Requests != list[j].Requests {
+			return list[i].Requests > list[j].Requests
+		}
+		return list[i].Value < list[j].Value
+	})
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
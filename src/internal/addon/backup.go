@@ -0,0 +1,142 @@
+package addon
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupCommands dump every database of an instance to stdout, by addon type.
+// Credentials are read from the secrets mounted in the container
+var backupCommands = map[string]struct {
+	script string
+	ext    string
+}{
+	"postgresql": {`exec pg_dumpall -U "$(cat /run/secrets/db_user)"`, ".sql.gz"},
+	"mysql":      {`MYSQL_PWD="$(cat /run/secrets/root_password)" exec mysqldump -u root --all-databases --single-transaction --routines --events`, ".sql.gz"},
+	"mariadb":    {`MYSQL_PWD="$(cat /run/secrets/root_password)" exec "$(command -v mariadb-dump || command -v mysqldump)" -u root --all-databases --single-transaction --routines --events`, ".sql.gz"},
+	"mongodb":    {`exec mongodump --archive --authenticationDatabase admin -u "$(cat /run/secrets/db_user)" -p "$(cat /run/secrets/db_password)"`, ".archive.gz"},
+}
+
+// CanBackup reports whether instances of an addon type can be backed up
+func CanBackup(addonType string) bool {
+	_, ok := backupCommands[addonType]
+	return ok
+}
+
+// BackupDir returns the directory of the backups of an instance
+func BackupDir(porticoHome, name string) string {
+	return filepath.Join(porticoHome, "backups", name)
+}
+
+// Backup dumps the databases of a running instance into a gzipped file in
+// dir, named after the instance and the time, and returns its path
+func (am *Manager) Backup(name string, instance Instance, dir string) (string, error) {
+	backup, ok := backupCommands[instance.Type]
+	if !ok {
+		return "", fmt.Errorf("addon type %s can't be backed up (postgresql, mysql, mariadb, mongodb)", instance.Type)
+	}
+	instanceDir := filepath.Join(am.InstancesDir, name)
+	composeFile := filepath.Join(instanceDir, "docker-compose.yml")
+	if _, err := os.Stat(composeFile); err != nil {
+		return "", fmt.Errorf("docker-compose.yml not found for instance %s", name)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	path := filepath.Join(dir, name+"-"+time.Now().UTC().Format("20060102-150405")+backup.ext)
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("error creating backup file: %w", err)
+	}
+	defer os.Remove(tmp) // Left behind only on failure
+
+	gz := gzip.NewWriter(file)
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", "compose", "-f", composeFile, "exec", "-T", instance.Type, "sh", "-c", backup.script)
+	cmd.Dir = instanceDir
+	cmd.Stdout = gz
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	closeErr := gz.Close()
+	if err := file.Close(); closeErr == nil {
+		closeErr = err
+	}
+	if runErr != nil {
+		return "", fmt.Errorf("error dumping %s: %w\n%s", name, runErr, strings.TrimSpace(stderr.String()))
+	}
+	if closeErr != nil {
+		return "", fmt.Errorf("error writing backup file: %w", closeErr)
+	}
+	if empty, err := gzipEmpty(tmp); err != nil || empty {
+		return "", fmt.Errorf("error dumping %s: the dump is empty", name)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("error saving backup file: %w", err)
+	}
+	return path, nil
+}
+
+// gzipEmpty reports whether a gzip file has no content
+func gzipEmpty(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return false, err
+	}
+	n, err := io.CopyN(io.Discard, gz, 1)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return n == 0, nil
+}
+
+// ListBackups returns the backup files in dir, oldest first
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".gz") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files) // Names end with the time
+	return files, nil
+}
+
+// PruneBackups removes the oldest backups in dir, keeping the newest keep.
+// Returns the files removed
+func PruneBackups(dir string, keep int) ([]string, error) {
+	files, err := ListBackups(dir)
+	if err != nil || keep <= 0 || len(files) <= keep {
+		return nil, err
+	}
+	var removed []string
+	for _, file := range files[:len(files)-keep] {
+		if err := os.Remove(file); err != nil {
+			return removed, err
+		}
+		removed = append(removed, file)
+	}
+	return removed, nil
+}
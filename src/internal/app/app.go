@@ -2,6 +2,7 @@ package app
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"os"
@@ -102,6 +103,7 @@ func (am *Manager) SaveApp(app *App) error {
 			Volumes:     svc.Volumes,
 			Secrets:     svc.Secrets,
 			DependsOn:   svc.DependsOn,
+			Replicas:    svc.Replicas,
 		})
 	}
 
@@ -110,6 +112,7 @@ func (am *Manager) SaveApp(app *App) error {
 		Domain:      app.Domain,
 		Port:        app.Port,
 		HttpEnabled: app.Port > 0,
+		Environment: app.Environment,
 	}
 
 	return dm.GenerateDockerCompose(appDir, dockerServices, metadata)
@@ -143,6 +146,7 @@ func (am *Manager) LoadAppFromCompose(name string) (*App, error) {
 	domain := ""
 	port := 0
 	httpEnabled := false
+	appEnvironment := docker.AppEnvironment(compose.XPortico)
 	if compose.XPortico != nil {
 		domain = compose.XPortico.Domain
 		httpEnabled = compose.XPortico.HttpEnabled
@@ -154,10 +158,33 @@ func (am *Manager) LoadAppFromCompose(name string) (*App, error) {
 	// Convert services from docker-compose.yml format to App.Service format
 	var services []Service
 	for svcName, svcData := range compose.Services {
+		// Replicas of a service rendered per replica are part of the service
+		if _, ok := docker.IndexedReplicaOf(compose.XPortico, svcName); ok {
+			continue
+		}
 		svc, err := convertServiceFromCompose(svcName, svcData)
 		if err != nil {
 			return nil, fmt.Errorf("error converting service %s: %w", svcName, err)
 		}
+		if replicas := docker.IndexedReplicas(compose.XPortico, svcName); replicas > 0 {
+			svc.Replicas = replicas
+		}
+		// Values with ${...} references are shown and saved as configured
+		for k, v := range docker.ServiceReferences(compose.XPortico, svcName) {
+			if _, ok := svc.Environment[k]; ok {
+				svc.Environment[k] = v
+			}
+		}
+		// Built-in variables and variables inherited from the app-level environment
+		// are not service variables. Overrides are recorded in x-portico; a value
+		// that differs from the app-level one is an override too (manual edits)
+		for k, v := range svc.Environment {
+			if docker.IsBuiltinVariable(k) {
+				delete(svc.Environment, k)
+			} else if appValue, ok := appEnvironment[k]; ok && appValue == v && !docker.IsServiceOverride(compose.XPortico, svcName, k) {
+				delete(svc.Environment, k)
+			}
+		}
 		services = append(services, *svc)
 	}
 
@@ -189,8 +216,8 @@ func (am *Manager) LoadAppFromCompose(name string) (*App, error) {
 	return &App{
 		Name:        name,
 		Domain:      domain,
-		Port:        port,                    // HTTP port (0 if HTTP disabled)
-		Environment: make(map[string]string), // App-level environment not stored in compose
+		Port:        port,           // HTTP port (0 if HTTP disabled)
+		Environment: appEnvironment, // App-level environment (x-portico)
 		Services:    services,
 	}, nil
 }
@@ -216,7 +243,7 @@ func convertServiceFromCompose(name string, svcData interface{}) (*Service, erro
 		svc.Image = img
 	}
 
-	// Extract ports - primary port and extra ports
+	// Extract ports - published mappings are the extra ports (the HTTP port is not published)
 	if ports, ok := svcMap["ports"].([]interface{}); ok {
 		primaryPort := 0
 		for _, p := range ports {
@@ -224,16 +251,11 @@ func convertServiceFromCompose(name string, svcData interface{}) (*Service, erro
 			if !ok {
 				continue
 			}
-			// Parse port mapping "host:container" or just "port"
-			parts := strings.Split(portStr, ":")
-			if len(parts) == 2 {
-				containerPort, err := strconv.Atoi(parts[1])
-				if err == nil {
-					if primaryPort == 0 {
-						primaryPort = containerPort
-					} else {
-						svc.ExtraPorts = append(svc.ExtraPorts, portStr)
-					}
+			// Parse port mapping "[ip:]host:container[/protocol]" or just "port"
+			parts := strings.Split(strings.SplitN(portStr, "/", 2)[0], ":")
+			if len(parts) >= 2 {
+				if _, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+					svc.ExtraPorts = append(svc.ExtraPorts, portStr)
 				}
 			} else if len(parts) == 1 {
 				port, err := strconv.Atoi(parts[0])
@@ -252,10 +274,10 @@ func convertServiceFromCompose(name string, svcData interface{}) (*Service, erro
 			if !ok {
 				continue
 			}
-			// Parse "KEY=VALUE" format
+			// Parse "KEY=VALUE" format ($ is escaped as $$ in the compose file)
 			parts := strings.SplitN(envStr, "=", 2)
 			if len(parts) == 2 {
-				svc.Environment[parts[0]] = parts[1]
+				svc.Environment[parts[0]] = strings.ReplaceAll(parts[1], "$$", "$")
 			}
 		}
 	}
@@ -264,7 +286,7 @@ func convertServiceFromCompose(name string, svcData interface{}) (*Service, erro
 	if volumes, ok := svcMap["volumes"].([]interface{}); ok {
 		for _, v := range volumes {
 			volStr, ok := v.(string)
-			if ok && !strings.Contains(volStr, "/run/secrets") { // Exclude secrets mount
+			if ok && !strings.Contains(volStr, "/run/secrets") && !docker.IsPorticoManagedVolume(volStr) { // Exclude secrets and logs mounts
 				svc.Volumes = append(svc.Volumes, volStr)
 			}
 		}
@@ -288,9 +310,24 @@ func convertServiceFromCompose(name string, svcData interface{}) (*Service, erro
 		}
 	}
 
+	// Extract replicas (deploy.replicas)
+	svc.Replicas = replicasFromCompose(svcMap)
+
 	return svc, nil
 }
 
+// replicasFromCompose returns deploy.replicas for a compose service (0 if not set)
+func replicasFromCompose(svcMap map[string]interface{}) int {
+	deploy, ok := svcMap["deploy"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	if replicas, ok := deploy["replicas"].(int); ok {
+		return replicas
+	}
+	return 0
+}
+
 // ListApps returns a list of all applications
 func (am *Manager) ListApps() ([]string, error) {
 	entries, err := os.ReadDir(am.AppsDir)
@@ -395,8 +432,11 @@ func (am *Manager) CreateDefaultCaddyfileWithPrompt(name string, prompt bool) er
 	// Get domain and port from metadata
 	domain := compose.XPortico.Domain
 
+	// Static sites are served by Caddy directly (no services)
+	static := compose.XPortico.Static
+
 	// Get services from docker-compose.yml first (needed for domain generation)
-	if len(compose.Services) == 0 {
+	if len(compose.Services) == 0 && static == nil {
 		return fmt.Errorf("no services found in app %s", name)
 	}
 
@@ -408,6 +448,9 @@ func (am *Manager) CreateDefaultCaddyfileWithPrompt(name string, prompt bool) er
 	} else {
 		// Use first service available
 		for svcName := range compose.Services {
+			if _, ok := docker.IndexedReplicaOf(compose.XPortico, svcName); ok {
+				continue
+			}
 			httpServiceName = svcName
 			break
 		}
@@ -456,7 +499,7 @@ func (am *Manager) CreateDefaultCaddyfileWithPrompt(name string, prompt bool) er
 	// Note: We don't auto-migrate .localhost domains here to preserve user-defined domains
 
 	httpPort := compose.XPortico.Port
-	if httpPort == 0 {
+	if httpPort == 0 && static == nil {
 		return fmt.Errorf("HTTP port not configured for app %s", name)
 	}
 
@@ -477,10 +520,34 @@ func (am *Manager) CreateDefaultCaddyfileWithPrompt(name string, prompt bool) er
 	// Use the HTTP service name we found earlier
 	serviceName := httpServiceName
 
-	if serviceName == "" {
+	if serviceName == "" && static == nil {
 		return fmt.Errorf("no service found in app %s", name)
 	}
 
+	// Ensure AppName is never empty - always use directory name as fallback
+	if projectName == "" {
+		projectName = name
+	}
+
+	// Build upstreams for the HTTP service
+	// A single instance uses appname-servicename; replicas are listed explicitly
+	// (appname-servicename-N, Docker Compose container naming) so Caddy can
+	// balance and health check each one instead of relying on DNS round robin
+	upstreams := []string{fmt.Sprintf("%s-%s:%d", projectName, serviceName, httpPort)}
+	replicas := 0
+	if svcMap, ok := compose.Services[serviceName].(map[string]interface{}); ok {
+		replicas = replicasFromCompose(svcMap)
+	}
+	if indexed := docker.IndexedReplicas(compose.XPortico, serviceName); indexed > 0 {
+		replicas = indexed // Each replica has its container name as network alias
+	}
+	if replicas > 1 {
+		upstreams = nil
+		for i := 1; i <= replicas; i++ {
+			upstreams = append(upstreams, fmt.Sprintf("%s-%s-%d:%d", projectName, serviceName, i, httpPort))
+		}
+	}
+
 	// Load template from filesystem first, then embedded files
 	templateData, err := embed.LoadTemplate(am.TemplatesDir, "caddy-app.tmpl")
 	if err != nil {
@@ -492,37 +559,215 @@ func (am *Manager) CreateDefaultCaddyfileWithPrompt(name string, prompt bool) er
 		return fmt.Errorf("error parsing caddy-app template: %w", err)
 	}
 
-	// Create output file
-	file, err := os.Create(caddyfilePath)
-	if err != nil {
-		return fmt.Errorf("error creating Caddyfile: %w", err)
-	}
-	defer func() { _ = file.Close() }()
-
 	// Execute template
 	// Use project name from docker-compose.yml for DNS resolution
-	// Ensure AppName is never empty - always use directory name as fallback
-	if projectName == "" {
-		projectName = name
-	}
-	templateVars := struct {
-		AppName     string
-		Domain      string
-		ServiceName string
-		Port        int
-	}{
+	templateVars := caddyTemplateData{
 		AppName:     projectName, // Use project name from docker-compose.yml
 		Domain:      domain,
 		ServiceName: serviceName,
 		Port:        httpPort,
+		Proxy:       caddyProxyData{Upstreams: upstreams},
+	}
+	if proxyConfig := compose.XPortico.Proxy; proxyConfig != nil {
+		templateVars.Proxy.LBPolicy = proxyConfig.LBPolicy
+		templateVars.Proxy.HealthCheck = proxyConfig.HealthCheck
+	}
+	applyLimits(&templateVars, projectName, compose.XPortico.Limits)
+	applyHeaders(&templateVars, compose.XPortico.Headers)
+	if static != nil {
+		templateVars.Static = &caddyStaticData{
+			Root: filepath.Join(am.StaticSiteDir(name), "current"),
+			SPA:  static.SPA,
+		}
+	}
+
+	return writeCaddyfile(t, caddyfilePath, templateVars)
+}
+
+// caddyTemplateData represents data for the caddy-app template
+type caddyTemplateData struct {
+	AppName           string
+	Domain            string
+	ServiceName       string
+	Port              int
+	Proxy             caddyProxyData
+	Routes            []caddyRouteData
+	RateZones         []caddyRateZone
+	MaxBodySize       string   // Site-wide body limit
+	BodyLimitExcludes []string // Route paths with their own body limit
+	HasBodyLimits     bool
+	HeadersProfile    string
+	Headers           []caddyHeaderData
+	CORS              *caddyCORSData
+	Static            *caddyStaticData // Set for static site apps (file_server instead of reverse_proxy)
+	GeneratedHash     string
+}
+
+// caddyStaticData represents a static site in the caddy-app template
+type caddyStaticData struct {
+	Root string // Symlink to the current release
+	SPA  bool
+}
+
+// caddyHeaderData represents a response header line in the caddy-app template
+type caddyHeaderData struct {
+	Name   string
+	Value  string // Quoted for the Caddyfile
+	Remove bool
+}
+
+// caddyCORSData represents the CORS configuration in the caddy-app template
+type caddyCORSData struct {
+	Origins     []string
+	AllowOrigin string // * for any origin, otherwise the request origin
+}
+
+// caddyProxyData represents a reverse_proxy block in the caddy-app template
+type caddyProxyData struct {
+	Matcher     string
+	Upstreams   []string
+	LBPolicy    string
+	HealthCheck *docker.HealthCheckConfig
+	Timeout     string
+}
+
+// caddyRouteData represents a route (path matcher) with its own limits
+type caddyRouteData struct {
+	Matcher     string
+	Path        string
+	MaxBodySize string
+	Proxy       *caddyProxyData // Set when the route has its own timeout
+}
+
+// caddyRateZone represents a rate_limit zone keyed on client IP
+type caddyRateZone struct {
+	Name   string
+	Path   string
+	Events int
+	Window string
+}
+
+// limitPathPattern matches the route paths that can be written unquoted to
+// the Caddyfile (path matchers, with * wildcards)
+var limitPathPattern = regexp.MustCompile(`^/[A-Za-z0-9._~/*%-]*$`)
+
+// ValidateLimitPath checks that a limit route path can be written to the Caddyfile
+func ValidateLimitPath(path string) error {
+	if !limitPathPattern.MatchString(path) {
+		return fmt.Errorf("invalid path %s (use a path like /api/*: letters, digits, . _ ~ / * %% -)", path)
+	}
+	return nil
+}
+
+// applyLimits fills the limits part of the template data from x-portico limits
+func applyLimits(data *caddyTemplateData, projectName string, limits []docker.LimitRule) {
+	for i, rule := range limits {
+		if rule.Path != "" && ValidateLimitPath(rule.Path) != nil {
+			// Paths are written unquoted: skip any that would break the Caddyfile
+			continue
+		}
+		if rule.Path == "" {
+			// Site-wide limits
+			data.MaxBodySize = rule.MaxBodySize
+			data.Proxy.Timeout = rule.Timeout
+			if rule.RateLimit != nil {
+				data.RateZones = append(data.RateZones, caddyRateZone{
+					Name:   projectName + "_site",
+					Events: rule.RateLimit.Events,
+					Window: rule.RateLimit.Window,
+				})
+			}
+			continue
+		}
+
+		route := caddyRouteData{
+			Matcher:     fmt.Sprintf("limit_%d", i),
+			Path:        rule.Path,
+			MaxBodySize: rule.MaxBodySize,
+		}
+		if rule.MaxBodySize != "" {
+			data.BodyLimitExcludes = append(data.BodyLimitExcludes, rule.Path)
+		}
+		if rule.Timeout != "" {
+			// Same upstreams and balancing as the site, with the route timeout
+			routeProxy := data.Proxy
+			routeProxy.Matcher = route.Matcher
+			routeProxy.Timeout = rule.Timeout
+			route.Proxy = &routeProxy
+		}
+		if rule.RateLimit != nil {
+			data.RateZones = append(data.RateZones, caddyRateZone{
+				Name:   fmt.Sprintf("%s_%s", projectName, route.Matcher),
+				Path:   rule.Path,
+				Events: rule.RateLimit.Events,
+				Window: rule.RateLimit.Window,
+			})
+		}
+		data.Routes = append(data.Routes, route)
 	}
-	if err := t.Execute(file, templateVars); err != nil {
+
+	data.HasBodyLimits = data.MaxBodySize != "" || len(data.BodyLimitExcludes) > 0
+	if data.MaxBodySize == "" {
+		data.BodyLimitExcludes = nil
+	}
+}
+
+// applyHeaders fills the response headers part of the template data
+func applyHeaders(data *caddyTemplateData, headers *docker.HeadersConfig) {
+	data.HeadersProfile = DefaultHeaderProfile
+	if headers != nil && headers.Profile != "" {
+		data.HeadersProfile = headers.Profile
+	}
+
+	for _, line := range EffectiveHeaders(headers) {
+		data.Headers = append(data.Headers, caddyHeaderData{
+			Name:   line.Name,
+			Value:  caddyQuote(line.Value),
+			Remove: line.Remove,
+		})
+	}
+
+	if headers == nil || len(headers.CORSOrigins) == 0 {
+		return
+	}
+	data.CORS = &caddyCORSData{
+		AllowOrigin: caddyQuote("{http.request.header.Origin}"),
+	}
+	for _, origin := range headers.CORSOrigins {
+		// Origins are written unquoted: skip any that would break the Caddyfile
+		if ValidateOrigin(origin) != nil {
+			continue
+		}
+		data.CORS.Origins = append(data.CORS.Origins, origin)
+		if origin == "*" {
+			data.CORS.Origins = []string{"*"}
+			data.CORS.AllowOrigin = "*"
+			break
+		}
+	}
+	if len(data.CORS.Origins) == 0 {
+		data.CORS = nil
+	}
+}
+
+// caddyHashPrefix precedes the content hash in generated Caddyfiles
+const caddyHashPrefix = "# Portico Generated - Hash: "
+
+// writeCaddyfile renders the caddy-app template and writes it with its content hash,
+// matching what DetectCaddyfileChanges expects (hash of the content without the hash)
+func writeCaddyfile(t *template.Template, caddyfilePath string, data caddyTemplateData) error {
+	data.GeneratedHash = ""
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
 		return fmt.Errorf("error executing caddy-app template: %w", err)
 	}
 
-	// Close file explicitly before fixing ownership (defer will handle if this fails)
-	if err := file.Close(); err != nil {
-		return fmt.Errorf("error closing Caddyfile: %w", err)
+	contentWithoutHash := strings.Replace(buf.String(), caddyHashPrefix, "", 1)
+	hash := sha256.Sum256([]byte(contentWithoutHash))
+	content := strings.Replace(buf.String(), caddyHashPrefix, fmt.Sprintf("%s%x", caddyHashPrefix, hash), 1)
+
+	if err := os.WriteFile(caddyfilePath, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("error writing Caddyfile: %w", err)
 	}
 
 	// Fix file ownership if running as root
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/maxvegac/portico/src/internal/docker"
+)
+
+// HeaderLine is a response header Caddy sets (or removes) for an app
+type HeaderLine struct {
+	Name   string
+	Value  string
+	Remove bool   // Remove the header from the response (Caddy -Name)
+	Source string // Profile name or "custom"
+}
+
+// DefaultHeaderProfile is used when an app has no headers configuration
+const DefaultHeaderProfile = "default"
+
+// HeaderProfiles lists the named security header sets
+var HeaderProfiles = map[string][]HeaderLine{
+	// Headers Portico has always set
+	"default": {
+		{Name: "X-Content-Type-Options", Value: "nosniff"},
+		{Name: "X-Frame-Options", Value: "DENY"},
+		{Name: "X-XSS-Protection", Value: "1; mode=block"},
+	},
+	// HSTS, CSP and no framing, for apps served only over HTTPS
+	"strict": {
+		{Name: "Strict-Transport-Security", Value: "max-age=31536000; includeSubDomains"},
+		{Name: "Content-Security-Policy", Value: "default-src 'self'; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"},
+		{Name: "X-Content-Type-Options", Value: "nosniff"},
+		{Name: "X-Frame-Options", Value: "DENY"},
+		{Name: "Referrer-Policy", Value: "strict-origin-when-cross-origin"},
+		{Name: "Permissions-Policy", Value: "camera=(), microphone=(), geolocation=()"},
+		{Name: "Cross-Origin-Opener-Policy", Value: "same-origin"},
+		{Name: "Server", Remove: true},
+	},
+	// Allows embedding in other sites (widgets, iframes)
+	"relaxed": {
+		{Name: "X-Content-Type-Options", Value: "nosniff"},
+		{Name: "Referrer-Policy", Value: "strict-origin-when-cross-origin"},
+	},
+	// APIs called from browsers on other origins (see CORSOrigins)
+	"api-cors": {
+		{Name: "X-Content-Type-Options", Value: "nosniff"},
+		{Name: "Referrer-Policy", Value: "no-referrer"},
+	},
+}
+
+// HeaderProfileNames returns the available profile names in a stable order
+func HeaderProfileNames() []string {
+	names := make([]string, 0, len(HeaderProfiles))
+	for name := range HeaderProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EffectiveHeaders returns the headers for an app: the profile headers
+// with custom headers applied on top (custom values override, empty removes)
+func EffectiveHeaders(headers *docker.HeadersConfig) []HeaderLine {
+	profile := DefaultHeaderProfile
+	var custom map[string]string
+	if headers != nil {
+		if headers.Profile != "" {
+			profile = headers.Profile
+		}
+		custom = headers.Custom
+	}
+
+	var lines []HeaderLine
+	for _, line := range HeaderProfiles[profile] {
+		if _, overridden := custom[http.CanonicalHeaderKey(line.Name)]; overridden {
+			continue
+		}
+		line.Source = profile
+		lines = append(lines, line)
+	}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := custom[name]
+		lines = append(lines, HeaderLine{Name: name, Value: value, Remove: value == "", Source: "custom"})
+	}
+
+	return lines
+}
+
+// ValidateHeaderValue checks that a header value can be written to the
+// Caddyfile: control characters (newlines included) would add lines to it
+func ValidateHeaderValue(value string) error {
+	for _, r := range value {
+		if r != '\t' && (r < 0x20 || r == 0x7f) {
+			return fmt.Errorf("header values can't contain control characters (%q)", r)
+		}
+	}
+	if strings.HasSuffix(value, `\`) {
+		return fmt.Errorf("header values can't end with a backslash")
+	}
+	return nil
+}
+
+// ValidateOrigin checks that a CORS origin can be written unquoted to the Caddyfile
+func ValidateOrigin(origin string) error {
+	for _, r := range origin {
+		if r <= 0x20 || r == 0x7f || strings.ContainsRune("\"'{}#;\\", r) {
+			return fmt.Errorf("invalid character %q in origin %s", r, origin)
+		}
+	}
+	return nil
+}
+
+// caddyQuote quotes a header value for the Caddyfile when needed. Control
+// characters are dropped, so a value can never span several Caddyfile lines
+func caddyQuote(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r != '\t' && (r < 0x20 || r == 0x7f) {
+			return -1
+		}
+		return r
+	}, value)
+	value = strings.TrimRight(value, `\`)
+	if value != "" && !strings.ContainsAny(value, " \t\"'{}#;\\") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// staticReleasesToKeep is how many published releases are kept for a static site
+const staticReleasesToKeep = 5
+
+// StaticSiteDir returns the directory holding the releases of a static site app
+// (/home/portico/sites/<app>, mounted into the Caddy container)
+func (am *Manager) StaticSiteDir(name string) string {
+	return filepath.Join(filepath.Dir(am.AppsDir), "sites", name)
+}
+
+// PublishStaticRelease copies a build output directory into a new release of
+// the static site and switches the current symlink to it atomically
+func (am *Manager) PublishStaticRelease(name, outputDir string) (string, error) {
+	siteDir := am.StaticSiteDir(name)
+	releasesDir := filepath.Join(siteDir, "releases")
+	if err := os.MkdirAll(releasesDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating releases directory: %w", err)
+	}
+
+	// Nanoseconds keep two publishes in the same second apart; Mkdir fails
+	// rather than reusing a release directory that already exists
+	now := time.Now().UTC()
+	release := fmt.Sprintf("%s%09d", now.Format("20060102150405"), now.Nanosecond())
+	releaseDir := filepath.Join(releasesDir, release)
+	if err := os.Mkdir(releaseDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating release directory: %w", err)
+	}
+	if err := copyDir(outputDir, releaseDir); err != nil {
+		_ = os.RemoveAll(releaseDir) // New directory, never the one being served
+		return "", fmt.Errorf("error copying build output: %w", err)
+	}
+
+	if err := am.SwitchStaticRelease(name, release); err != nil {
+		return "", err
+	}
+
+	// Remove old releases, never the one being served
+	releases, err := am.ListStaticReleases(name)
+	if err == nil && len(releases) > staticReleasesToKeep {
+		current := am.CurrentStaticRelease(name)
+		for _, old := range releases[:len(releases)-staticReleasesToKeep] {
+			if old != current {
+				_ = os.RemoveAll(filepath.Join(releasesDir, old))
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// SwitchStaticRelease points the current symlink of a static site to a release.
+// The new link is created next to the old one and renamed over it, so Caddy
+// always sees either the old or the new release
+func (am *Manager) SwitchStaticRelease(name, release string) error {
+	siteDir := am.StaticSiteDir(name)
+	if _, err := os.Stat(filepath.Join(siteDir, "releases", release)); err != nil {
+		return fmt.Errorf("release %s not found for app %s", release, name)
+	}
+
+	tmpLink := filepath.Join(siteDir, fmt.Sprintf(".current-%d", os.Getpid()))
+	_ = os.Remove(tmpLink)
+	// Relative target so the link resolves the same way inside the Caddy container
+	if err := os.Symlink(filepath.Join("releases", release), tmpLink); err != nil {
+		return fmt.Errorf("error creating release symlink: %w", err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(siteDir, "current")); err != nil {
+		_ = os.Remove(tmpLink)
+		return fmt.Errorf("error switching release: %w", err)
+	}
+
+	return nil
+}
+
+// CurrentStaticRelease returns the release the current symlink of a static
+// site points to ("" if there is none)
+func (am *Manager) CurrentStaticRelease(name string) string {
+	target, err := os.Readlink(filepath.Join(am.StaticSiteDir(name), "current"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// ListStaticReleases returns the releases of a static site, oldest first
+func (am *Manager) ListStaticReleases(name string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(am.StaticSiteDir(name), "releases"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var releases []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			releases = append(releases, entry.Name())
+		}
+	}
+	sort.Strings(releases)
+	return releases, nil
+}
+
+// copyDir copies a directory tree (regular files and directories only)
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+
+		switch {
+		case info.IsDir():
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		case info.Mode().IsRegular():
+			return copyFile(path, target)
+		default:
+			// Skip symlinks and special files (may point outside the site)
+			return nil
+		}
+	})
+}
+
+// copyFile copies a regular file readable by Caddy
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// Fix file ownership if running as root
+	_ = util.FixFileOwnership(dst)
+	return nil
+}
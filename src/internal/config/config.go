@@ -12,13 +12,57 @@ import (
 
 // Config represents the Portico configuration
 type Config struct {
-	PorticoHome  string         `yaml:"portico_home"`
-	AppsDir      string         `yaml:"apps_dir"`
-	ProxyDir     string         `yaml:"proxy_dir"`
-	TemplatesDir string         `yaml:"templates_dir"`
-	AddonsDir    string         `yaml:"addons_dir"`
-	Registry     RegistryConfig `yaml:"registry"`
-	ExternalIP   string         `yaml:"external_ip,omitempty"` // External IP for sslip.io domain generation
+	PorticoHome   string         `yaml:"portico_home"`
+	AppsDir       string         `yaml:"apps_dir"`
+	ProxyDir      string         `yaml:"proxy_dir"`
+	TemplatesDir  string         `yaml:"templates_dir"`
+	AddonsDir     string         `yaml:"addons_dir"`
+	Registry      RegistryConfig `yaml:"registry"`
+	ExternalIP    string         `yaml:"external_ip,omitempty"`            // External IP for sslip.io domain generation
+	SecretsKey    string         `yaml:"secrets_key,omitempty"`            // Master key used to encrypt secrets at rest
+	RotationGrace string         `yaml:"secrets_rotation_grace,omitempty"` // How long rotated secrets keep their previous value
+	DeployWatch   string         `yaml:"deploy_watch,omitempty"`           // How long apps are watched after a deploy (0: off)
+	WatchRestarts int            `yaml:"deploy_watch_restarts,omitempty"`  // Restarts within the watch that trigger a rollback
+	SMTP          SMTPConfig     `yaml:"smtp,omitempty"`                   // Server used by email notifications
+	Notifications []Notification `yaml:"notifications,omitempty"`          // Notification channels
+	Daemon        DaemonConfig   `yaml:"daemon,omitempty"`                 // portico daemon
+}
+
+// DaemonConfig represents the settings of the portico daemon
+type DaemonConfig struct {
+	Socket        string      `yaml:"socket,omitempty" mapstructure:"socket"`                 // Unix socket of the state (default: <portico_home>/portico.sock)
+	RestartPolicy string      `yaml:"restart_policy,omitempty" mapstructure:"restart_policy"` // on-failure (default), always, no
+	MaxRestarts   int         `yaml:"max_restarts,omitempty" mapstructure:"max_restarts"`     // Restarts of a container within the window before giving up
+	RestartWindow string      `yaml:"restart_window,omitempty" mapstructure:"restart_window"`
+	Jobs          []DaemonJob `yaml:"jobs,omitempty" mapstructure:"jobs"` // Scheduled jobs
+}
+
+// DaemonJob represents a portico command the daemon runs periodically
+type DaemonJob struct {
+	Name    string `yaml:"name" mapstructure:"name"`
+	Every   string `yaml:"every" mapstructure:"every"`     // Interval, e.g. 15m, 24h
+	Command string `yaml:"command" mapstructure:"command"` // portico arguments, e.g. secrets expire
+}
+
+// SMTPConfig represents the SMTP server used to send email notifications
+type SMTPConfig struct {
+	Host     string `yaml:"host" mapstructure:"host"`
+	Port     int    `yaml:"port" mapstructure:"port"` // 587 (STARTTLS) by default; 465 uses implicit TLS
+	Username string `yaml:"username,omitempty" mapstructure:"username"`
+	Password string `yaml:"password,omitempty" mapstructure:"password"`
+	From     string `yaml:"from" mapstructure:"from"`
+}
+
+// Notification represents a notification channel
+type Notification struct {
+	Name     string            `yaml:"name" mapstructure:"name"`
+	Type     string            `yaml:"type" mapstructure:"type"`                   // webhook, slack, email
+	URL      string            `yaml:"url,omitempty" mapstructure:"url"`           // webhook and slack
+	To       []string          `yaml:"to,omitempty" mapstructure:"to"`             // email
+	Events   []string          `yaml:"events,omitempty" mapstructure:"events"`     // Events sent (default: all)
+	Apps     []string          `yaml:"apps,omitempty" mapstructure:"apps"`         // Apps notified about (default: all)
+	Template string            `yaml:"template,omitempty" mapstructure:"template"` // Go template of the message
+	Headers  map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`   // webhook: extra HTTP headers
 }
 
 // RegistryConfig represents Docker registry configuration
@@ -76,6 +120,13 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("proxy_dir", "/home/portico/reverse-proxy")
 	viper.SetDefault("templates_dir", "/home/portico/templates")
 	viper.SetDefault("addons_dir", "/home/portico/addons")
+	viper.SetDefault("secrets_key", "/etc/portico/secrets.key")
+	viper.SetDefault("secrets_rotation_grace", "24h")
+	viper.SetDefault("deploy_watch", "60s")
+	viper.SetDefault("deploy_watch_restarts", 3)
+	viper.SetDefault("daemon.restart_policy", "on-failure")
+	viper.SetDefault("daemon.max_restarts", 5)
+	viper.SetDefault("daemon.restart_window", "10m")
 	viper.SetDefault("registry.type", "internal")
 	viper.SetDefault("registry.url", "localhost:5000")
 
@@ -102,18 +153,44 @@ func LoadConfig() (*Config, error) {
 
 	// Create config manually from viper values
 	config := &Config{
-		PorticoHome:  viper.GetString("portico_home"),
-		AppsDir:      viper.GetString("apps_dir"),
-		ProxyDir:     viper.GetString("proxy_dir"),
-		TemplatesDir: viper.GetString("templates_dir"),
-		AddonsDir:    viper.GetString("addons_dir"),
-		ExternalIP:   viper.GetString("external_ip"),
+		PorticoHome:   viper.GetString("portico_home"),
+		AppsDir:       viper.GetString("apps_dir"),
+		ProxyDir:      viper.GetString("proxy_dir"),
+		TemplatesDir:  viper.GetString("templates_dir"),
+		AddonsDir:     viper.GetString("addons_dir"),
+		ExternalIP:    viper.GetString("external_ip"),
+		SecretsKey:    viper.GetString("secrets_key"),
+		RotationGrace: viper.GetString("secrets_rotation_grace"),
+		DeployWatch:   viper.GetString("deploy_watch"),
+		WatchRestarts: viper.GetInt("deploy_watch_restarts"),
 		Registry: RegistryConfig{
 			Type:     viper.GetString("registry.type"),
 			URL:      viper.GetString("registry.url"),
 			Username: viper.GetString("registry.username"),
 			Password: viper.GetString("registry.password"),
 		},
+		SMTP: SMTPConfig{
+			Host:     viper.GetString("smtp.host"),
+			Port:     viper.GetInt("smtp.port"),
+			Username: viper.GetString("smtp.username"),
+			Password: viper.GetString("smtp.password"),
+			From:     viper.GetString("smtp.from"),
+		},
+	}
+	if err := viper.UnmarshalKey("notifications", &config.Notifications); err != nil {
+		return nil, fmt.Errorf("error reading notifications: %w", err)
+	}
+	config.Daemon = DaemonConfig{
+		Socket:        viper.GetString("daemon.socket"),
+		RestartPolicy: viper.GetString("daemon.restart_policy"),
+		MaxRestarts:   viper.GetInt("daemon.max_restarts"),
+		RestartWindow: viper.GetString("daemon.restart_window"),
+	}
+	if err := viper.UnmarshalKey("daemon.jobs", &config.Daemon.Jobs); err != nil {
+		return nil, fmt.Errorf("error reading daemon jobs: %w", err)
+	}
+	if config.Daemon.Socket == "" {
+		config.Daemon.Socket = filepath.Join(config.PorticoHome, "portico.sock")
 	}
 
 	return config, nil
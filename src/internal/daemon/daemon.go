@@ -0,0 +1,202 @@
+// Package daemon watches the containers of Portico apps and addon instances
+// through docker events: it keeps their state, notifies containers that die
+// or fail their health check, restarts them according to the restart policy,
+// runs scheduled jobs and serves its state on a Unix socket
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/notify"
+)
+
+// Actor is the actor of the journal events and notifications of the daemon
+const Actor = "portico-daemon"
+
+// Restart policies
+const (
+	PolicyOnFailure = "on-failure" // Restart containers that exit with an error or are OOM killed
+	PolicyAlways    = "always"     // Restart containers that stop on their own, whatever their exit code
+	PolicyNo        = "no"         // Only notify
+)
+
+// DefaultJobs are the jobs of a daemon without daemon.jobs in config.yml
+var DefaultJobs = []config.DaemonJob{
+	{Name: "secrets-expire", Every: "15m", Command: "secrets expire"},
+	{Name: "addons-backup", Every: "24h", Command: "addons backup --all"},
+}
+
+// Daemon is the state and the settings of a running daemon
+type Daemon struct {
+	cfg         *config.Config
+	dm          *docker.Manager
+	maxRestarts int
+	window      time.Duration
+
+	mu     sync.Mutex
+	state  *State
+	killed map[string]time.Time // Containers stopped on purpose (kill event before die)
+}
+
+// New returns a daemon for the config. Config must not be loaded again while
+// the daemon runs: notifications and the journal use this one
+func New(cfg *config.Config) (*Daemon, error) {
+	policy := cfg.Daemon.RestartPolicy
+	switch policy {
+	case "":
+		policy = PolicyOnFailure
+	case PolicyOnFailure, PolicyAlways, PolicyNo:
+	default:
+		return nil, fmt.Errorf("invalid daemon.restart_policy %q (on-failure, always, no)", policy)
+	}
+
+	window, err := time.ParseDuration(cfg.Daemon.RestartWindow)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid daemon.restart_window %q", cfg.Daemon.RestartWindow)
+	}
+	maxRestarts := cfg.Daemon.MaxRestarts
+	if maxRestarts <= 0 {
+		maxRestarts = 5
+	}
+
+	d := &Daemon{
+		cfg:         cfg,
+		dm:          docker.NewManager(cfg.Registry.URL),
+		maxRestarts: maxRestarts,
+		window:      window,
+		state:       &State{Started: time.Now(), RestartPolicy: policy},
+		killed:      make(map[string]time.Time),
+	}
+	jobs := cfg.Daemon.Jobs
+	if len(jobs) == 0 {
+		jobs = DefaultJobs
+	}
+	for _, job := range jobs {
+		every, err := time.ParseDuration(job.Every)
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("invalid interval %q of job %s", job.Every, job.Name)
+		}
+		if job.Command == "" {
+			return nil, fmt.Errorf("job %s has no command", job.Name)
+		}
+		d.state.Jobs = append(d.state.Jobs, &JobState{
+			Name:    job.Name,
+			Every:   job.Every,
+			Command: job.Command,
+			NextRun: time.Now().Add(every),
+		})
+	}
+	return d, nil
+}
+
+// Run runs the daemon until ctx is done: it loads the current state of the
+// containers, serves it on the socket, runs the jobs and follows docker events
+func (d *Daemon) Run(ctx context.Context) error {
+	d.loadState()
+
+	listener, err := listen(d.cfg.Daemon.Socket)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	go d.serve(listener)
+	d.logf("Listening on %s", d.cfg.Daemon.Socket)
+
+	for _, job := range d.state.Jobs {
+		go d.runJob(ctx, job)
+	}
+
+	d.followEvents(ctx)
+	return nil
+}
+
+// Snapshot returns a copy of the current state
+func (d *Daemon) Snapshot() *State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state.copy()
+}
+
+// loadState reads the containers of all apps and addon instances
+func (d *Daemon) loadState() {
+	am := app.NewManager(d.cfg.AppsDir, d.cfg.TemplatesDir)
+	apps, err := am.ListApps()
+	if err != nil {
+		d.logf("Warning: could not list apps: %v", err)
+	}
+	addonManager := addon.NewManager(d.cfg.AddonsDir, filepath.Join(d.cfg.AddonsDir, "instances"))
+	addonConfig, err := addonManager.LoadConfig()
+	if err != nil {
+		d.logf("Warning: could not load addons config: %v", err)
+		addonConfig = &addon.Config{}
+	}
+
+	load := func(name, kind, dir string) {
+		containers, err := d.dm.GetContainerStatus(dir)
+		if err != nil {
+			d.logf("Warning: could not read containers of %s: %v", name, err)
+			return
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		a := d.state.app(name, kind)
+		for _, c := range containers {
+			state := a.container(c.ID, c.Name, c.Service)
+			state.State = c.State
+			state.Health = c.Health
+			state.ExitCode = c.ExitCode
+		}
+	}
+	for _, name := range apps {
+		load(name, "app", filepath.Join(d.cfg.AppsDir, name))
+	}
+	for name := range addonConfig.Instances {
+		load(name, "addon", filepath.Join(d.cfg.AddonsDir, "instances", name))
+	}
+}
+
+// owner returns the app or addon instance a compose project belongs to, and
+// its kind. Returns empty strings for containers Portico doesn't manage
+func (d *Daemon) owner(project string) (string, string) {
+	if project == "" || project != filepath.Base(project) {
+		return "", ""
+	}
+	if _, err := os.Stat(filepath.Join(d.cfg.AppsDir, project, "docker-compose.yml")); err == nil {
+		return project, "app"
+	}
+	if _, err := os.Stat(filepath.Join(d.cfg.AddonsDir, "instances", project, "docker-compose.yml")); err == nil {
+		return project, "addon"
+	}
+	return "", ""
+}
+
+// notify sends an event to the notification channels of the config
+func (d *Daemon) notify(event notify.Event) {
+	event.Actor = Actor
+	for name, err := range notify.Send(d.cfg, event) {
+		d.logf("Warning: notification %s failed: %v", name, err)
+	}
+}
+
+// record appends an event to the journal
+func (d *Daemon) record(event journal.Event) {
+	event.Actor = Actor
+	if err := journal.Append(d.cfg.PorticoHome, event); err != nil {
+		d.logf("Warning: could not record event: %v", err)
+	}
+}
+
+// logf prints a line of the daemon log
+func (d *Daemon) logf(format string, args ...interface{}) {
+	fmt.Printf("%s %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
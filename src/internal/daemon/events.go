@@ -0,0 +1,300 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/docker"
+	"github.com/maxvegac/portico/src/internal/journal"
+	"github.com/maxvegac/portico/src/internal/notify"
+)
+
+// reconnectDelay is how long to wait before reading docker events again
+const reconnectDelay = 5 * time.Second
+
+// killWindow is how long after a kill event a die is an intentional stop
+const killWindow = 30 * time.Second
+
+// maxBackoff is the longest wait before restarting a container
+const maxBackoff = 60 * time.Second
+
+// dockerEvent is a line of docker events --format '{{json .}}'
+type dockerEvent struct {
+	Type     string `json:"Type"`
+	Action   string `json:"Action"`
+	ID       string `json:"id"`
+	TimeNano int64  `json:"timeNano"`
+	Actor    struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+}
+
+// followEvents reads docker events until ctx is done, reconnecting when the
+// stream ends (e.g. docker restarted) without missing the events in between
+func (d *Daemon) followEvents(ctx context.Context) {
+	since := time.Now()
+	for {
+		last, err := d.readEvents(ctx, since)
+		if !last.IsZero() {
+			since = last
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			d.logf("Warning: docker events stopped: %v", err)
+		} else {
+			d.logf("Warning: docker events stopped")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+		// Containers may have changed while events were not read
+		d.loadState()
+	}
+}
+
+// readEvents runs docker events from since and handles its events until it
+// ends. Returns the time of the last event read
+func (d *Daemon) readEvents(ctx context.Context, since time.Time) (time.Time, error) {
+	var last time.Time
+	cmd := exec.CommandContext(ctx, "docker", "events",
+		"--format", "{{json .}}",
+		"--filter", "type=container",
+		"--since", strconv.FormatInt(since.Unix(), 10))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return last, err
+	}
+	if err := cmd.Start(); err != nil {
+		return last, err
+	}
+
+	d.mu.Lock()
+	d.state.EventsConnected = true
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.state.EventsConnected = false
+		d.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event dockerEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if event.TimeNano > 0 {
+			last = time.Unix(0, event.TimeNano)
+		}
+		d.handle(event)
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Wait()
+		return last, err
+	}
+	return last, cmd.Wait()
+}
+
+// handle updates the state with a docker event, restarting or notifying
+// containers of Portico apps and addon instances that stopped
+func (d *Daemon) handle(event dockerEvent) {
+	attrs := event.Actor.Attributes
+	name, kind := d.owner(attrs["com.docker.compose.project"])
+	if name == "" {
+		return
+	}
+	id := event.Actor.ID
+	if id == "" {
+		id = event.ID
+	}
+	if id == "" {
+		return
+	}
+	action, detail, _ := strings.Cut(event.Action, ": ")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.state.LastEvent = time.Now()
+	a := d.state.app(name, kind)
+	service, _ := docker.ContainerService(attrs) // Event attributes include the labels
+	c := a.container(id, attrs["name"], service)
+
+	switch action {
+	case "create":
+		c.State = "created"
+		c.Since = time.Now()
+	case "start":
+		c.State = "running"
+		c.Health = ""
+		c.Since = time.Now()
+		if c.GaveUp {
+			// Started by hand or by a deploy after the daemon gave up
+			c.GaveUp = false
+			c.restarts = nil
+			c.Restarts = 0
+		}
+	case "kill", "stop":
+		d.killed[id] = time.Now()
+	case "oom":
+		c.OOM = true
+	case "die":
+		c.State = "exited"
+		c.Since = time.Now()
+		c.ExitCode, _ = strconv.Atoi(attrs["exitCode"])
+		oom := c.OOM
+		c.OOM = false
+		if killed, ok := d.killed[id]; ok && time.Since(killed) < killWindow && !oom {
+			delete(d.killed, id)
+			return
+		}
+		delete(d.killed, id)
+		d.crashed(name, kind, c, oom)
+	case "health_status":
+		previous := c.Health
+		c.Health = detail
+		if detail == "unhealthy" && previous != "unhealthy" {
+			event := notify.NewEvent(notify.EventUnhealthy, name,
+				fmt.Sprintf("%s: container %s is unhealthy", name, c.Name), "")
+			event.Details["container"] = c.Name
+			event.Details["service"] = c.Service
+			go d.notify(event)
+			d.logf("%s: container %s is unhealthy", name, c.Name)
+		}
+	case "destroy":
+		a.remove(id)
+		delete(d.killed, id)
+	}
+}
+
+// crashed handles a container that stopped on its own: it is restarted
+// according to the restart policy, or given up after too many restarts.
+// Containers of an app whose deploy is being watched are left to the watch,
+// which rolls the deploy back. Called with the lock held
+func (d *Daemon) crashed(app, kind string, c *ContainerState, oom bool) {
+	reason := fmt.Sprintf("exit code %d", c.ExitCode)
+	if oom {
+		reason = "out of memory"
+	}
+
+	restart := false
+	switch d.state.RestartPolicy {
+	case PolicyAlways:
+		restart = true
+	case PolicyOnFailure:
+		restart = c.ExitCode != 0 || oom
+	}
+	watched := restart && kind == "app" && docker.DeployWatchRunning(filepath.Join(d.cfg.AppsDir, app))
+	if watched {
+		restart = false
+	}
+
+	// Forget restarts out of the window
+	now := time.Now()
+	var recent []time.Time
+	for _, t := range c.restarts {
+		if now.Sub(t) < d.window {
+			recent = append(recent, t)
+		}
+	}
+	c.restarts = recent
+	c.Restarts = len(recent)
+
+	if restart && len(recent) >= d.maxRestarts {
+		c.GaveUp = true
+		title := fmt.Sprintf("%s: container %s is crash-looping", app, c.Name)
+		message := fmt.Sprintf("It stopped (%s) after %d restarts in %s; it is not restarted anymore", reason, len(recent), d.window)
+		event := notify.NewEvent(notify.EventCrashLoop, app, title, message)
+		event.Details["container"] = c.Name
+		event.Details["service"] = c.Service
+		go d.notify(event)
+		go d.record(journal.Event{
+			App:     app,
+			Action:  "daemon give-up",
+			Summary: fmt.Sprintf("container %s stopped (%s) after %d restarts in %s", c.Name, reason, len(recent), d.window),
+		})
+		d.logf("%s: container %s is crash-looping (%s), giving up", app, c.Name, reason)
+		return
+	}
+
+	message := ""
+	if watched {
+		message = "Not restarted: the deploy of the app is being watched and rolls back on crashes"
+	}
+	if restart {
+		backoff := time.Duration(1<<uint(len(recent))) * time.Second
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		c.State = "restarting"
+		c.restarts = append(c.restarts, now)
+		c.Restarts = len(c.restarts)
+		message = fmt.Sprintf("Restarting in %s (restart %d of %d)", backoff, c.Restarts, d.maxRestarts)
+		id, name := c.ID, c.Name
+		time.AfterFunc(backoff, func() { d.restart(app, id, name, reason) })
+	}
+
+	title := fmt.Sprintf("%s: container %s died (%s)", app, c.Name, reason)
+	event := notify.NewEvent(notify.EventContainerDied, app, title, message)
+	event.Details["container"] = c.Name
+	event.Details["service"] = c.Service
+	event.Details["exit_code"] = strconv.Itoa(c.ExitCode)
+	go d.notify(event)
+	d.logf("%s", strings.TrimSpace(title+". "+message))
+}
+
+// restart starts a container that crashed, unless it was started or removed
+// in the meantime
+func (d *Daemon) restart(app, id, name, reason string) {
+	watched := docker.DeployWatchRunning(filepath.Join(d.cfg.AppsDir, app))
+	d.mu.Lock()
+	state := ""
+	for _, a := range d.state.Apps {
+		for _, c := range a.Containers {
+			if c.ID == id {
+				state = c.State
+				if watched && c.State == "restarting" {
+					c.State = "exited" // Left to the deploy watch started meanwhile
+				}
+			}
+		}
+	}
+	d.mu.Unlock()
+	if state != "restarting" {
+		return
+	}
+	if watched {
+		d.logf("%s: not restarting container %s, the deploy of the app is being watched", app, name)
+		return
+	}
+
+	event := journal.Event{
+		App:     app,
+		Action:  "daemon restart",
+		Summary: fmt.Sprintf("container %s (%s)", name, reason),
+	}
+	output, err := exec.Command("docker", "start", id).CombinedOutput()
+	if err != nil {
+		event.Error = strings.TrimSpace(string(output))
+		if event.Error == "" {
+			event.Error = err.Error()
+		}
+		d.logf("Warning: could not restart %s: %s", name, event.Error)
+	} else {
+		d.logf("%s: restarted container %s", app, name)
+	}
+	d.record(event)
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// runJob runs a scheduled job every interval until ctx is done. Jobs are
+// Portico commands, run by the same binary as the daemon
+func (d *Daemon) runJob(ctx context.Context, job *JobState) {
+	every, _ := time.ParseDuration(job.Every)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		err := runCommand(ctx, job.Command)
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.mu.Lock()
+		job.Runs++
+		job.LastRun = start
+		job.LastDuration = time.Since(start).Seconds()
+		job.LastError = ""
+		if err != nil {
+			job.LastError = err.Error()
+		}
+		job.NextRun = start.Add(every)
+		d.mu.Unlock()
+
+		if err != nil {
+			d.logf("Warning: job %s failed: %v", job.Name, err)
+		} else {
+			d.logf("Job %s done in %.1fs", job.Name, time.Since(start).Seconds())
+		}
+	}
+}
+
+// runCommand runs a Portico command, e.g. "secrets expire". Its output goes
+// to the daemon log. A job fails when the command exits non-zero; the error
+// has the last "Error" line the command printed
+func runCommand(ctx context.Context, command string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable, strings.Fields(command)...)
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		lines := strings.Split(output.String(), "\n")
+		for i := len(lines) - 1; i >= 0; i-- {
+			if strings.HasPrefix(lines[i], "Error") {
+				return fmt.Errorf("%w: %s", err, strings.TrimSpace(lines[i]))
+			}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// listen listens on the Unix socket, replacing a socket left by a daemon
+// that did not stop cleanly
+func listen(socket string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", socket)
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0o755); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(socket)
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %w", socket, err)
+	}
+	// Only the owner and the group (the portico user) may read the state
+	_ = os.Chmod(socket, 0o660)
+	_ = util.FixFileOwnership(socket)
+	return listener, nil
+}
+
+// serve serves the state on the socket: GET /state returns it as JSON
+func (d *Daemon) serve(listener net.Listener) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(d.Snapshot())
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	_ = server.Serve(listener)
+}
+
+// Status returns the state of the daemon listening on a socket
+func Status(socket string) (*State, error) {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	resp, err := client.Get("http://portico/state")
+	if err != nil {
+		return nil, fmt.Errorf("the daemon is not running (%s): %w", socket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the daemon returned %s", resp.Status)
+	}
+
+	var state State
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("error decoding the daemon state: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"sort"
+	"time"
+)
+
+// State is what the daemon knows about the server, as served on its socket
+type State struct {
+	Started         time.Time   `json:"started"`
+	RestartPolicy   string      `json:"restart_policy"`
+	EventsConnected bool        `json:"events_connected"` // docker events is being read
+	LastEvent       time.Time   `json:"last_event,omitempty"`
+	Apps            []*AppState `json:"apps"`
+	Jobs            []*JobState `json:"jobs"`
+}
+
+// AppState is the state of the containers of an app or addon instance
+type AppState struct {
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind"` // app, addon
+	Containers []*ContainerState `json:"containers"`
+}
+
+// ContainerState is the state of a container, updated from docker events
+type ContainerState struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Service  string    `json:"service"`
+	State    string    `json:"state"` // created, running, exited, restarting (restart scheduled by the daemon)
+	Health   string    `json:"health,omitempty"`
+	ExitCode int       `json:"exit_code"`
+	OOM      bool      `json:"oom,omitempty"`     // The last exit was an out of memory kill
+	Restarts int       `json:"restarts"`          // Restarts by the daemon within the restart window
+	GaveUp   bool      `json:"gave_up,omitempty"` // The daemon stopped restarting it (crash loop)
+	Since    time.Time `json:"since"`             // Last state change
+	restarts []time.Time
+}
+
+// JobState is the state of a scheduled job
+type JobState struct {
+	Name         string    `json:"name"`
+	Every        string    `json:"every"`
+	Command      string    `json:"command"`
+	Runs         int       `json:"runs"`
+	LastRun      time.Time `json:"last_run,omitempty"`
+	LastDuration float64   `json:"last_duration,omitempty"` // Seconds
+	LastError    string    `json:"last_error,omitempty"`
+	NextRun      time.Time `json:"next_run"`
+}
+
+// app returns the state of an app, adding it if needed
+func (s *State) app(name, kind string) *AppState {
+	for _, a := range s.Apps {
+		if a.Name == name {
+			return a
+		}
+	}
+	a := &AppState{Name: name, Kind: kind}
+	s.Apps = append(s.Apps, a)
+	sort.Slice(s.Apps, func(i, j int) bool {
+		if s.Apps[i].Kind != s.Apps[j].Kind {
+			return s.Apps[i].Kind == "app"
+		}
+		return s.Apps[i].Name < s.Apps[j].Name
+	})
+	return a
+}
+
+// container returns the state of a container of an app, adding it if needed
+func (a *AppState) container(id, name, service string) *ContainerState {
+	for _, c := range a.Containers {
+		if c.ID == id {
+			return c
+		}
+	}
+	c := &ContainerState{ID: id, Name: name, Service: service, Since: time.Now()}
+	a.Containers = append(a.Containers, c)
+	sort.Slice(a.Containers, func(i, j int) bool { return a.Containers[i].Name < a.Containers[j].Name })
+	return c
+}
+
+// remove removes a container from an app
+func (a *AppState) remove(id string) {
+	for i, c := range a.Containers {
+		if c.ID == id {
+			a.Containers = append(a.Containers[:i], a.Containers[i+1:]...)
+			return
+		}
+	}
+}
+
+// copy returns a deep copy of the state, to serve it while events change it
+func (s *State) copy() *State {
+	out := *s
+	out.Apps = make([]*AppState, len(s.Apps))
+	for i, a := range s.Apps {
+		appCopy := *a
+		appCopy.Containers = make([]*ContainerState, len(a.Containers))
+		for j, c := range a.Containers {
+			containerCopy := *c
+			containerCopy.restarts = nil
+			appCopy.Containers[j] = &containerCopy
+		}
+		out.Apps[i] = &appCopy
+	}
+	out.Jobs = make([]*JobState, len(s.Jobs))
+	for i, job := range s.Jobs {
+		jobCopy := *job
+		out.Jobs[i] = &jobCopy
+	}
+	return &out
+}
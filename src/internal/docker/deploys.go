@@ -0,0 +1,127 @@
+package docker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/notify"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// DeployLogFile is the file of an app directory recording its deploys, one
+// JSON object per line
+const DeployLogFile = "deploys.log"
+
+// DeployRecord is a deploy (docker compose up) of an app, or a rollback to
+// the previous release
+type DeployRecord struct {
+	Time     string            `json:"time"`     // RFC3339, when the deploy started
+	Duration float64           `json:"duration"` // Seconds
+	Success  bool              `json:"success"`
+	Error    string            `json:"error,omitempty"`  // First line of the error
+	Images   map[string]string `json:"images,omitempty"` // Image of each service
+	Action   string            `json:"action,omitempty"` // Empty for deploys, rollback
+	Reason   string            `json:"reason,omitempty"` // Why the app was rolled back
+}
+
+// IsRollback reports whether the record is a rollback
+func (r DeployRecord) IsRollback() bool {
+	return r.Action == "rollback"
+}
+
+// recordDeploy appends a deploy to the deploy log of an app
+func recordDeploy(appDir string, record DeployRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(appDir, DeployLogFile)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening deploy log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing deploy log: %w", err)
+	}
+	_ = util.FixFileOwnership(path)
+	return nil
+}
+
+// newDeployRecord returns the record of a deploy that started at start
+func newDeployRecord(start time.Time, services []Service, err error) DeployRecord {
+	record := DeployRecord{
+		Time:     start.UTC().Format(time.RFC3339),
+		Duration: time.Since(start).Seconds(),
+		Success:  err == nil,
+		Images:   make(map[string]string),
+	}
+	if err != nil {
+		record.Error, _, _ = strings.Cut(err.Error(), "\n")
+	}
+	for _, svc := range services {
+		record.Images[svc.Name] = svc.Image
+	}
+	return record
+}
+
+// deployEvent returns the notification of a deploy of an app
+func deployEvent(event, appDir string, services []Service, err error, duration time.Duration) notify.Event {
+	appName := filepath.Base(appDir)
+	var title, message string
+	switch event {
+	case notify.EventDeployStarted:
+		title = fmt.Sprintf("Deploying %s", appName)
+	case notify.EventDeploySucceeded:
+		title = fmt.Sprintf("Deployed %s", appName)
+	default:
+		title = fmt.Sprintf("Deploy of %s failed", appName)
+		message = strings.TrimSpace(err.Error())
+	}
+
+	e := notify.NewEvent(event, appName, title, message)
+	var images []string
+	for _, svc := range services {
+		images = append(images, svc.Name+"="+svc.Image)
+	}
+	e.Details["images"] = strings.Join(images, ", ")
+	if duration > 0 {
+		e.Details["duration"] = duration.Round(time.Second).String()
+	}
+	return e
+}
+
+// DeployHistory returns the deploys of an app, oldest first
+func DeployHistory(appDir string) ([]DeployRecord, error) {
+	file, err := os.Open(filepath.Join(appDir, DeployLogFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []DeployRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record DeployRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue // Skip a line cut by a crash
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -1,20 +1,24 @@
 package docker
 
 import (
-	"bufio"
 	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/maxvegac/portico/src/internal/config"
 	"github.com/maxvegac/portico/src/internal/embed"
+	"github.com/maxvegac/portico/src/internal/notify"
+	"github.com/maxvegac/portico/src/internal/secrets"
 	"github.com/maxvegac/portico/src/internal/util"
 )
 
@@ -30,9 +34,13 @@ func NewManager(registryURL string) *Manager {
 	}
 }
 
+// notifyWait is how long a deploy waits for its notifications once docker
+// compose up is done
+const notifyWait = 5 * time.Second
+
 // DeployApp deploys an application using docker compose
 // If services have replicas > 1, uses --scale to scale them
-func (dm *Manager) DeployApp(appDir string, services []Service) error {
+func (dm *Manager) DeployApp(appDir string, services []Service) (err error) {
 	composeFile := filepath.Join(appDir, "docker-compose.yml")
 
 	// Check if docker-compose.yml exists
@@ -40,6 +48,30 @@ func (dm *Manager) DeployApp(appDir string, services []Service) error {
 		return fmt.Errorf("docker-compose.yml not found in %s", appDir)
 	}
 
+	// Record the deploy (for metrics and the deploy history) and notify it in
+	// the background; the deploy doesn't fail if the log can't be written
+	start := time.Now()
+	started := notify.NotifyBackground(deployEvent(notify.EventDeployStarted, appDir, services, nil, 0))
+	defer func() {
+		_ = recordDeploy(appDir, newDeployRecord(start, services, err))
+		var finished notify.Pending
+		if err != nil {
+			finished = notify.NotifyBackground(deployEvent(notify.EventDeployFailed, appDir, services, err, time.Since(start)))
+		} else {
+			finished = notify.NotifyBackground(deployEvent(notify.EventDeploySucceeded, appDir, services, nil, time.Since(start)))
+		}
+		if !notify.Wait(notifyWait, started, finished) {
+			fmt.Println("Warning: deploy notifications are taking too long, not waiting for them")
+		}
+	}()
+
+	return dm.composeUp(appDir, services)
+}
+
+// composeUp runs docker compose up for an application
+func (dm *Manager) composeUp(appDir string, services []Service) error {
+	composeFile := filepath.Join(appDir, "docker-compose.yml")
+
 	// Extract app name from directory to ensure consistent project naming
 	// Docker Compose uses project name as prefix for service names (e.g., myapp-web)
 	appName := filepath.Base(appDir)
@@ -49,13 +81,25 @@ func (dm *Manager) DeployApp(appDir string, services []Service) error {
 		return fmt.Errorf("error ensuring portico-network exists: %w", err)
 	}
 
+	// Decrypt secrets into tmpfs for the /run/secrets mount
+	if err := MaterializeSecrets(appDir); err != nil {
+		return err
+	}
+
 	// Build docker compose command with explicit project name
 	// This ensures services are named consistently: appname-servicename
-	args := []string{"compose", "-f", composeFile, "-p", appName, "up", "-d"}
-
-	// Add --scale flags for services with replicas > 1
+	// --remove-orphans stops the services no longer in the file, such as the
+	// replicas of a service rendered per replica after a scale down
+	args := []string{"compose", "-f", composeFile, "-p", appName, "up", "-d", "--remove-orphans"}
+
+	// Add --scale flags for services with replicas > 1, except the services
+	// rendered per replica (one compose service each)
+	var metadata *PorticoMetadata
+	if compose, err := dm.LoadComposeFile(appDir); err == nil {
+		metadata = compose.XPortico
+	}
 	for _, svc := range services {
-		if svc.Replicas > 1 {
+		if svc.Replicas > 1 && IndexedReplicas(metadata, svc.Name) == 0 {
 			args = append(args, "--scale", fmt.Sprintf("%s=%d", svc.Name, svc.Replicas))
 		}
 	}
@@ -69,9 +113,43 @@ func (dm *Manager) DeployApp(appDir string, services []Service) error {
 		return fmt.Errorf("error running docker compose: %s\n%s", cmdErr, string(output))
 	}
 
+	// Staged changes are in docker-compose.yml, so they are live now
+	_ = dm.completePending(appDir)
+
 	return nil
 }
 
+// completePending clears the staged changes of an app after docker compose up
+// deployed them, whatever command ran it, and restarts once each service with
+// staged secret changes (secrets are read at startup)
+func (dm *Manager) completePending(appDir string) error {
+	metadata, err := dm.GetPorticoMetadata(appDir)
+	if err != nil || metadata.Pending == nil {
+		return err
+	}
+
+	restarted := make(map[string]bool)
+	for _, change := range metadata.Pending.Changes {
+		if !change.Restart || change.Service == "" || restarted[change.Service] {
+			continue
+		}
+		restarted[change.Service] = true
+		_ = dm.RestartService(appDir, change.Service)
+	}
+
+	// The deployed containers no longer mount them, unless the secret was
+	// rotated again while staging
+	for _, name := range metadata.Pending.Remove {
+		if !rotationMounts(metadata, name) {
+			_ = RemoveSecretFile(appDir, name)
+		}
+	}
+
+	return dm.UpdatePorticoMetadata(appDir, func(metadata *PorticoMetadata) {
+		metadata.Pending = nil
+	})
+}
+
 // StopApp stops an application
 func (dm *Manager) StopApp(appDir string) error {
 	composeFile := filepath.Join(appDir, "docker-compose.yml")
@@ -91,6 +169,9 @@ func (dm *Manager) StopApp(appDir string) error {
 		return fmt.Errorf("error stopping application: %s\n%s", err, string(output))
 	}
 
+	// Decrypted secrets are only needed while containers run
+	_ = secrets.Clear(secrets.RuntimeDir("apps", appName))
+
 	return nil
 }
 
@@ -105,6 +186,11 @@ func (dm *Manager) RestartApp(appDir string) error {
 	// Extract app name from directory for consistent project naming
 	appName := filepath.Base(appDir)
 
+	// Refresh decrypted secrets (restart keeps the existing mounts)
+	if err := MaterializeSecrets(appDir); err != nil {
+		return err
+	}
+
 	cmd := exec.Command("docker", "compose", "-f", composeFile, "-p", appName, "restart")
 	cmd.Dir = appDir
 
@@ -127,7 +213,19 @@ func (dm *Manager) RestartService(appDir string, serviceName string) error {
 	// Extract app name from directory for consistent project naming
 	appName := filepath.Base(appDir)
 
-	cmd := exec.Command("docker", "compose", "-f", composeFile, "-p", appName, "restart", serviceName)
+	// Refresh decrypted secrets (restart keeps the existing mounts)
+	if err := MaterializeSecrets(appDir); err != nil {
+		return err
+	}
+
+	// Services rendered per replica have one compose service per replica
+	args := []string{"compose", "-f", composeFile, "-p", appName, "restart", serviceName}
+	if compose, err := dm.LoadComposeFile(appDir); err == nil {
+		for replica := 2; replica <= IndexedReplicas(compose.XPortico, serviceName); replica++ {
+			args = append(args, ReplicaServiceName(serviceName, replica))
+		}
+	}
+	cmd := exec.Command("docker", args...)
 	cmd.Dir = appDir
 
 	output, err := cmd.CombinedOutput()
@@ -149,10 +247,140 @@ type ComposeFile struct {
 
 // PorticoMetadata stores Portico-specific configuration
 type PorticoMetadata struct {
-	Domain      string `yaml:"domain,omitempty"`
-	Port        int    `yaml:"http_port,omitempty"`
-	HttpEnabled bool   `yaml:"http_enabled,omitempty"`
-	Generated   string `yaml:"generated_hash,omitempty"` // SHA256 hash of the generated content
+	Domain      string                       `yaml:"domain,omitempty"`
+	Port        int                          `yaml:"http_port,omitempty"`
+	HttpEnabled bool                         `yaml:"http_enabled,omitempty"`
+	Proxy       *ProxyConfig                 `yaml:"proxy,omitempty"`            // Load balancing and health checks (Caddy)
+	Limits      []LimitRule                  `yaml:"limits,omitempty"`           // Request limits (Caddy)
+	Resources   map[string]ResourcesConfig   `yaml:"resources,omitempty"`        // Service -> CPU and memory limits (deploy.resources)
+	Headers     *HeadersConfig               `yaml:"headers,omitempty"`          // Response headers and CORS (Caddy)
+	Static      *StaticConfig                `yaml:"static,omitempty"`           // Static site served by Caddy (no containers)
+	Environment map[string]string            `yaml:"environment,omitempty"`      // App-level environment shared by all services
+	Pending     *PendingConfig               `yaml:"pending,omitempty"`          // Config changes not deployed yet (staging)
+	Global      []string                     `yaml:"global_secrets,omitempty"`   // Global secrets linked to the app
+	Sensitive   []string                     `yaml:"sensitive_env,omitempty"`    // Variables masked in listings (besides name patterns)
+	Rotations   []SecretRotation             `yaml:"secret_rotations,omitempty"` // Rotated secrets whose previous value is still mounted
+	References  map[string]map[string]string `yaml:"env_references,omitempty"`   // Service -> variable -> value with ${...} references, as configured
+	Overrides   map[string][]string          `yaml:"env_overrides,omitempty"`    // Service -> variables that override app-level ones
+	Indexed     map[string]int               `yaml:"indexed_replicas,omitempty"` // Service -> replicas, for services rendered per replica (${PORTICO_REPLICA})
+	Generated   string                       `yaml:"generated_hash,omitempty"`   // SHA256 hash of the generated content
+}
+
+// ProxyConfig stores how Caddy balances traffic across the replicas of the HTTP service
+type ProxyConfig struct {
+	LBPolicy    string             `yaml:"lb_policy,omitempty"` // round_robin, least_conn, ip_hash, cookie
+	HealthCheck *HealthCheckConfig `yaml:"health_check,omitempty"`
+}
+
+// HealthCheckConfig stores the active health check Caddy runs against each upstream
+type HealthCheckConfig struct {
+	Path     string `yaml:"path"`
+	Interval string `yaml:"interval,omitempty"` // Caddy duration, e.g. 10s
+	Timeout  string `yaml:"timeout,omitempty"`  // Caddy duration, e.g. 5s
+	Status   int    `yaml:"status,omitempty"`   // Expected status code (0 means any 2xx)
+}
+
+// LimitRule stores request limits for the whole site (empty Path) or for a route
+type LimitRule struct {
+	Path        string     `yaml:"path,omitempty"`          // Caddy path pattern, e.g. /api/*
+	MaxBodySize string     `yaml:"max_body_size,omitempty"` // Caddy size, e.g. 10MB
+	Timeout     string     `yaml:"timeout,omitempty"`       // Max time to wait for the backend response
+	RateLimit   *RateLimit `yaml:"rate_limit,omitempty"`
+}
+
+// RateLimit stores how many requests a single client IP may send per window
+type RateLimit struct {
+	Events int    `yaml:"events"`
+	Window string `yaml:"window"` // Caddy duration, e.g. 1m
+}
+
+// IsEmpty reports whether the rule no longer limits anything
+func (r LimitRule) IsEmpty() bool {
+	return r.MaxBodySize == "" && r.Timeout == "" && r.RateLimit == nil
+}
+
+// HeadersConfig stores the response headers Caddy sets for the app
+type HeadersConfig struct {
+	Profile     string            `yaml:"profile,omitempty"`      // strict, relaxed, api-cors (empty: default headers)
+	CORSOrigins []string          `yaml:"cors_origins,omitempty"` // Allowed origins ("*" for any)
+	Custom      map[string]string `yaml:"custom,omitempty"`       // Header name -> value (empty value removes the header)
+}
+
+// StaticConfig stores how a static site app is built and published
+type StaticConfig struct {
+	BuildImage   string `yaml:"build_image,omitempty"`   // Image to run the build in (e.g. node:20-alpine)
+	BuildCommand string `yaml:"build_command,omitempty"` // Build command (e.g. npm ci && npm run build)
+	OutputDir    string `yaml:"output_dir,omitempty"`    // Directory to publish, relative to the repository root
+	SPA          bool   `yaml:"spa,omitempty"`           // Serve index.html for unknown paths
+	Release      string `yaml:"release,omitempty"`       // Release currently served
+}
+
+// SecretRotation is a rotated secret whose previous value is mounted as
+// <secret>_previous until it expires
+type SecretRotation struct {
+	Secret  string `yaml:"secret"`
+	Expires string `yaml:"expires"` // RFC3339
+}
+
+// PreviousSecretName returns the name the previous value of a rotated secret is mounted as
+func PreviousSecretName(secret string) string {
+	return secret + "_previous"
+}
+
+// rotationMounts reports whether a file is the previous value of a rotation in progress
+func rotationMounts(metadata *PorticoMetadata, name string) bool {
+	for _, r := range metadata.Rotations {
+		if PreviousSecretName(r.Secret) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSecretFile removes a secret file of an app from env/
+func RemoveSecretFile(appDir, name string) error {
+	if err := os.Remove(filepath.Join(appDir, "env", name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing %s: %w", name, err)
+	}
+	return nil
+}
+
+// PendingConfig stores config changes written to docker-compose.yml but not deployed yet.
+// While it exists, config commands stage their changes until "config commit"
+type PendingConfig struct {
+	Since   string          `yaml:"since"` // RFC3339
+	Changes []PendingChange `yaml:"changes,omitempty"`
+	Remove  []string        `yaml:"remove_secrets,omitempty"` // Files of env/ to remove once deployed (expired previous values)
+}
+
+// PendingChange is a staged config change
+type PendingChange struct {
+	Time    string `yaml:"time"`   // RFC3339
+	Change  string `yaml:"change"` // e.g. env add API_KEY
+	Service string `yaml:"service,omitempty"`
+	Restart bool   `yaml:"restart,omitempty"` // The service must be restarted (secrets are read at startup)
+}
+
+// inheritSettings copies the sections managed by dedicated commands (proxy, ...)
+// from a previous metadata block, so commands that only know about domain and port
+// don't drop them when regenerating docker-compose.yml
+func (m *PorticoMetadata) inheritSettings(from *PorticoMetadata) {
+	if from == nil {
+		return
+	}
+	m.Proxy = from.Proxy
+	m.Limits = from.Limits
+	m.Resources = from.Resources
+	m.Headers = from.Headers
+	m.Static = from.Static
+	m.Pending = from.Pending
+	m.Global = from.Global
+	m.Sensitive = from.Sensitive
+	m.Rotations = from.Rotations
+	// Static sites are served by Caddy without an HTTP port
+	if m.Static != nil {
+		m.HttpEnabled = true
+	}
 }
 
 // LoadComposeFile loads and parses an existing docker-compose.yml
@@ -198,6 +426,10 @@ type TemplateService struct {
 	Volumes     []string
 	Secrets     []string
 	DependsOn   []string
+	Replicas    int
+	Resources   *ResourcesConfig
+	ReplicaOf   string // Service rendered per replica: the service and the replica number
+	Replica     int
 }
 
 // TemplateSecret represents a secret for the template
@@ -223,21 +455,115 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 		return err
 	}
 
+	// Keep the previous metadata around so settings sections survive regeneration
+	previousMetadata := existing.XPortico
+
 	// Update Portico metadata
 	if metadata != nil {
 		existing.XPortico = metadata
 	}
 
+	// Secrets are mounted from env/, or from tmpfs when they are encrypted at rest
+	secretsSource := SecretsSource(appDir)
+	secretsMount := secretsSource + ":/run/secrets:ro"
+
+	// App-level environment: the caller's (SaveApp) or the one already in the file
+	var appEnvironment map[string]string
+	if metadata != nil && metadata.Environment != nil {
+		appEnvironment = metadata.Environment
+	} else {
+		appEnvironment = AppEnvironment(previousMetadata)
+	}
+
+	// Facts for the built-in variables: the caller's or the ones already in the file
+	domain, httpPort := "", 0
+	if metadata != nil {
+		domain, httpPort = metadata.Domain, metadata.Port
+	} else if previousMetadata != nil {
+		domain = previousMetadata.Domain
+		if previousMetadata.HttpEnabled {
+			httpPort = previousMetadata.Port
+		}
+	}
+	addons := newAddonFields(filepath.Base(appDir))
+	references := make(map[string]map[string]string)
+	overrides := make(map[string][]string)
+	indexed := make(map[string]int)
+	serviceNames := make(map[string]bool)
+	for _, svc := range services {
+		serviceNames[svc.Name] = true
+	}
+
 	// Prepare template services with merge
 	templateServices := []TemplateService{}
 	for _, svc := range services {
+		// Service variables override app-level ones
+		rawEnvironment := make(map[string]string)
+		for k, v := range appEnvironment {
+			rawEnvironment[k] = v
+		}
+		for k, v := range svc.Environment {
+			rawEnvironment[k] = v
+			// Recorded: the merged environment can't tell an override with the
+			// app-level value from an inherited variable
+			if _, ok := appEnvironment[k]; ok {
+				overrides[svc.Name] = append(overrides[svc.Name], k)
+			}
+		}
+		sort.Strings(overrides[svc.Name])
+
+		// Expand ${...} references, with the built-in variables injected
+		replicas := svc.Replicas
+		if replicas == 0 {
+			replicas = 1
+		}
+		builtins := map[string]string{
+			"PORTICO_APP":       filepath.Base(appDir),
+			"PORTICO_SERVICE":   svc.Name,
+			"PORTICO_DOMAIN":    domain,
+			"PORTICO_HTTP_PORT": "",
+			"PORTICO_RELEASE":   releaseID(svc.Image, rawEnvironment),
+			"PORTICO_REPLICAS":  strconv.Itoa(replicas),
+		}
+		if httpPort > 0 {
+			builtins["PORTICO_HTTP_PORT"] = strconv.Itoa(httpPort)
+		}
+		perReplica := usesReplicaIndex(rawEnvironment)
+		if perReplica {
+			builtins["PORTICO_REPLICA"] = "1"
+		}
+		environment, err := resolveServiceEnvironment(rawEnvironment, builtins, addons)
+		if err != nil {
+			return fmt.Errorf("error resolving environment of service %s: %w (write $${ for a literal ${)", svc.Name, err)
+		}
+
+		// The raw values are kept in x-portico so commands show and save them
+		for k, v := range rawEnvironment {
+			if IsBuiltinVariable(k) || v == environment[k] {
+				continue
+			}
+			if references[svc.Name] == nil {
+				references[svc.Name] = make(map[string]string)
+			}
+			references[svc.Name][k] = v
+		}
+
 		templateSvc := TemplateService{
 			Name:        svc.Name,
 			Image:       svc.Image,
-			Environment: svc.Environment,
-			Volumes:     svc.Volumes,
+			Environment: environment,
 			Secrets:     svc.Secrets,
 			DependsOn:   svc.DependsOn,
+			Replicas:    svc.Replicas,
+			// Resources are owned by the resources command, like the other settings sections
+			Resources: serviceResources(previousMetadata, svc.Name),
+		}
+
+		// User volumes only: the template adds the logs mount and the secrets mount comes last
+		for _, vol := range svc.Volumes {
+			if !IsPorticoManagedVolume(vol) && !contains(templateSvc.Volumes, vol) {
+				templateSvc.Volumes = append(templateSvc.Volumes, vol)
+			}
 		}
 
 		// Handle ports - only expose ports explicitly added via ExtraPorts
@@ -246,40 +572,52 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 		templateSvc.Ports = append(templateSvc.Ports, svc.ExtraPorts...)
 
 		// Always add secrets mount
-		templateSvc.Volumes = append(templateSvc.Volumes, "./env:/run/secrets:ro")
+		templateSvc.Volumes = append(templateSvc.Volumes, secretsMount)
 
 		// If service exists, try to preserve custom fields from existing
 		if existingSvc, ok := existing.Services[svc.Name].(map[string]interface{}); ok {
-			// Preserve custom environment variables if they exist
-			if existingEnv, ok := existingSvc["environment"].([]interface{}); ok {
-				// Merge with existing environment
-				for _, envItem := range existingEnv {
-					if envStr, ok := envItem.(string); ok {
-						parts := strings.SplitN(envStr, "=", 2)
-						if len(parts) == 2 {
-							// Only preserve if not managed by Portico
-							if _, exists := templateSvc.Environment[parts[0]]; !exists {
-								templateSvc.Environment[parts[0]] = parts[1]
-							}
-						}
-					}
-				}
-			}
+			// Environment is not merged: callers pass the complete set (loaded from
+			// this file, manual edits included), so removed variables stay removed
 
 			// Preserve custom volumes (excluding the secrets mount)
 			if existingVolumes, ok := existingSvc["volumes"].([]interface{}); ok {
 				for _, vol := range existingVolumes {
 					if volStr, ok := vol.(string); ok {
 						// Only preserve if not the secrets mount and not in Portico-managed volumes
-						if volStr != "./env:/run/secrets:ro" && !contains(templateSvc.Volumes[:len(templateSvc.Volumes)-1], volStr) {
-							templateSvc.Volumes = append(templateSvc.Volumes[:len(templateSvc.Volumes)-1], volStr, "./env:/run/secrets:ro")
+						if !IsPorticoManagedVolume(volStr) && !contains(templateSvc.Volumes[:len(templateSvc.Volumes)-1], volStr) {
+							templateSvc.Volumes = append(templateSvc.Volumes[:len(templateSvc.Volumes)-1], volStr, secretsMount)
 						}
 					}
 				}
 			}
 		}
 
+		if !perReplica {
+			templateServices = append(templateServices, templateSvc)
+			continue
+		}
+
+		// All replicas of a compose service share one environment: render one
+		// service per replica, each with its own ${PORTICO_REPLICA}
+		indexed[svc.Name] = replicas
+		templateSvc.Replicas = 0
+		templateSvc.ReplicaOf = svc.Name
+		templateSvc.Replica = 1
 		templateServices = append(templateServices, templateSvc)
+		for replica := 2; replica <= replicas; replica++ {
+			replicaSvc := templateSvc
+			replicaSvc.Name = ReplicaServiceName(svc.Name, replica)
+			replicaSvc.Replica = replica
+			if serviceNames[replicaSvc.Name] {
+				return fmt.Errorf("service %s references ${PORTICO_REPLICA}, so its replica %d is rendered as service %s, which already exists", svc.Name, replica, replicaSvc.Name)
+			}
+			builtins["PORTICO_REPLICA"] = strconv.Itoa(replica)
+			replicaSvc.Environment, err = resolveServiceEnvironment(rawEnvironment, builtins, addons)
+			if err != nil {
+				return fmt.Errorf("error resolving environment of service %s: %w (write $${ for a literal ${)", svc.Name, err)
+			}
+			templateServices = append(templateServices, replicaSvc)
+		}
 	}
 
 	// Prepare secrets for template
@@ -288,7 +626,7 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 		for _, secret := range svc.Secrets {
 			if _, exists := templateSecrets[secret]; !exists {
 				templateSecrets[secret] = TemplateSecret{
-					File: fmt.Sprintf("./env/%s", secret),
+					File: secretsSource + "/" + secret,
 				}
 			}
 		}
@@ -316,7 +654,13 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 		return fmt.Errorf("error reading docker-compose template: %w", err)
 	}
 
-	t, err := template.New("docker-compose").Parse(string(templateDataBytes))
+	// quote keeps values with quotes, ": ", "#" or newlines valid YAML, and
+	// composeEscape keeps docker compose from interpolating $ in values
+	funcs := template.FuncMap{
+		"quote":         strconv.Quote,
+		"composeEscape": func(value string) string { return strings.ReplaceAll(value, "$", "$$") },
+	}
+	t, err := template.New("docker-compose").Funcs(funcs).Parse(string(templateDataBytes))
 	if err != nil {
 		return fmt.Errorf("error parsing docker-compose template: %w", err)
 	}
@@ -338,31 +682,41 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 		return fmt.Errorf("error parsing generated docker-compose: %w", err)
 	}
 
-	// Merge custom fields from existing compose (fields not managed by Portico)
-	for svcName, existingSvc := range existing.Services {
-		if existingSvcMap, ok := existingSvc.(map[string]interface{}); ok {
-			// Check if this service is in generated services
-			if generatedSvc, exists := generated.Services[svcName]; exists {
-				if generatedSvcMap, ok := generatedSvc.(map[string]interface{}); ok {
-					// Preserve custom fields that are not Portico-managed
-					porticoManagedFields := map[string]bool{
-						"image":       true,
-						"ports":       true,
-						"environment": true,
-						"volumes":     true,
-						"secrets":     true,
-						"depends_on":  true,
-						"logging":     true,
-						"networks":    true,
-					}
-					for k, v := range existingSvcMap {
-						if !porticoManagedFields[k] {
-							generatedSvcMap[k] = v
-						}
+	// Merge custom fields from existing compose (fields not managed by Portico).
+	// The replicas of a service rendered per replica take the fields of the service
+	rendered := &PorticoMetadata{Indexed: indexed}
+	for svcName, generatedSvc := range generated.Services {
+		source := svcName
+		if service, ok := IndexedReplicaOf(rendered, svcName); ok {
+			source = service
+		}
+		existingSvcMap, ok := existing.Services[source].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if generatedSvcMap, ok := generatedSvc.(map[string]interface{}); ok {
+			// Preserve custom fields that are not Portico-managed
+			porticoManagedFields := map[string]bool{
+				"image":       true,
+				"ports":       true,
+				"environment": true,
+				"volumes":     true,
+				"secrets":     true,
+				"depends_on":  true,
+				"deploy":      true,
+				"logging":     true,
+				"networks":    true,
+			}
+			for k, v := range existingSvcMap {
+				if k == "labels" {
+					if labels := mergeLabels(v, generatedSvcMap[k]); labels != nil {
+						generatedSvcMap[k] = labels
 					}
-					generated.Services[svcName] = generatedSvcMap
+				} else if !porticoManagedFields[k] {
+					generatedSvcMap[k] = v
 				}
 			}
+			generated.Services[svcName] = generatedSvcMap
 		}
 	}
 
@@ -391,10 +745,104 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 		}
 	}
 
+	// Settings sections are owned by their dedicated commands, not by the caller
+	generated.XPortico.inheritSettings(previousMetadata)
+	generated.XPortico.Environment = nil
+	if len(appEnvironment) > 0 {
+		// Stored escaped: docker compose interpolates the whole file, x-portico included
+		generated.XPortico.Environment = make(map[string]string)
+		for k, v := range appEnvironment {
+			generated.XPortico.Environment[k] = strings.ReplaceAll(v, "$", "$$")
+		}
+	}
+
+	generated.XPortico.References = nil
+	if len(references) > 0 {
+		generated.XPortico.References = make(map[string]map[string]string)
+		for svcName, env := range references {
+			generated.XPortico.References[svcName] = make(map[string]string)
+			for k, v := range env {
+				generated.XPortico.References[svcName][k] = strings.ReplaceAll(v, "$", "$$")
+			}
+		}
+	}
+
+	generated.XPortico.Overrides = nil
+	if len(overrides) > 0 {
+		generated.XPortico.Overrides = overrides
+	}
+
+	generated.XPortico.Indexed = nil
+	if len(indexed) > 0 {
+		generated.XPortico.Indexed = indexed
+	}
+
+	return writeComposeFile(composeFile, &generated)
+}
+
+// mergeLabels adds the custom labels of a service (a map or a KEY=VALUE list)
+// to the ones Portico generated. The replica labels are always Portico's: a
+// service no longer rendered per replica loses them
+func mergeLabels(custom, generated interface{}) interface{} {
+	labels := make(map[string]interface{})
+	switch l := custom.(type) {
+	case map[string]interface{}:
+		for k, v := range l {
+			labels[k] = v
+		}
+	case []interface{}:
+		for _, entry := range l {
+			if s, ok := entry.(string); ok {
+				k, v, _ := strings.Cut(s, "=")
+				labels[k] = v
+			}
+		}
+	}
+	delete(labels, ReplicaOfLabel)
+	delete(labels, ReplicaLabel)
+	if l, ok := generated.(map[string]interface{}); ok {
+		for k, v := range l {
+			labels[k] = v
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
+// UpdatePorticoMetadata applies changes to the x-portico section of docker-compose.yml
+// without touching services. Used by commands that manage settings sections (proxy, ...)
+func (dm *Manager) UpdatePorticoMetadata(appDir string, update func(*PorticoMetadata)) error {
+	composeFile := filepath.Join(appDir, "docker-compose.yml")
+
+	if _, err := os.Stat(composeFile); os.IsNotExist(err) {
+		return fmt.Errorf("docker-compose.yml not found in %s", appDir)
+	}
+
+	compose, err := dm.LoadComposeFile(appDir)
+	if err != nil {
+		return err
+	}
+
+	if compose.XPortico == nil {
+		compose.XPortico = &PorticoMetadata{}
+	}
+	update(compose.XPortico)
+
+	return writeComposeFile(composeFile, compose)
+}
+
+// writeComposeFile writes a compose file, storing the hash of its content in x-portico
+func writeComposeFile(composeFile string, compose *ComposeFile) error {
+	if compose.XPortico == nil {
+		compose.XPortico = &PorticoMetadata{}
+	}
+
 	// Calculate hash BEFORE adding the hash field itself
 	// Temporarily remove hash if it exists
-	generated.XPortico.Generated = ""
-	dataWithoutHash, err := yaml.Marshal(generated)
+	compose.XPortico.Generated = ""
+	dataWithoutHash, err := yaml.Marshal(compose)
 	if err != nil {
 		return fmt.Errorf("error marshaling docker-compose.yml for hash: %w", err)
 	}
@@ -404,10 +852,10 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 	hashStr := fmt.Sprintf("%x", hash)
 
 	// Now add the hash to metadata
-	generated.XPortico.Generated = hashStr
+	compose.XPortico.Generated = hashStr
 
 	// Marshal final version with hash
-	finalData, err := yaml.Marshal(generated)
+	finalData, err := yaml.Marshal(compose)
 	if err != nil {
 		return fmt.Errorf("error marshaling final docker-compose: %w", err)
 	}
@@ -425,6 +873,125 @@ func (dm *Manager) GenerateDockerCompose(appDir string, services []Service, meta
 	return nil
 }
 
+// IsPorticoManagedVolume reports whether a volume is one Portico adds to every
+// service (secrets mount, logs mount), as opposed to a user volume
+func IsPorticoManagedVolume(volume string) bool {
+	if volume == "./env:/run/secrets:ro" {
+		return true
+	}
+	if strings.HasPrefix(volume, secrets.RuntimeBase()+"/") && strings.HasSuffix(volume, ":/run/secrets:ro") {
+		return true
+	}
+	return strings.HasPrefix(volume, "/home/portico/logs/apps/") && strings.HasSuffix(volume, ":/app/logs:rw")
+}
+
+// AppEnvironment returns the app-level environment stored in x-portico
+func AppEnvironment(metadata *PorticoMetadata) map[string]string {
+	environment := make(map[string]string)
+	if metadata == nil {
+		return environment
+	}
+	for k, v := range metadata.Environment {
+		environment[k] = strings.ReplaceAll(v, "$$", "$")
+	}
+	return environment
+}
+
+// ServiceReferences returns the configured values (with ${...} references) of
+// the variables of a service, stored in x-portico
+func ServiceReferences(metadata *PorticoMetadata, service string) map[string]string {
+	references := make(map[string]string)
+	if metadata == nil {
+		return references
+	}
+	for k, v := range metadata.References[service] {
+		references[k] = strings.ReplaceAll(v, "$$", "$")
+	}
+	return references
+}
+
+// IsServiceOverride reports whether a variable of a service overrides the
+// app-level variable of the same name, as recorded in x-portico
+func IsServiceOverride(metadata *PorticoMetadata, service, key string) bool {
+	return metadata != nil && contains(metadata.Overrides[service], key)
+}
+
+// SecretsSource returns the host directory mounted at /run/secrets for an app:
+// ./env, or its tmpfs runtime directory when secrets are encrypted at rest
+func SecretsSource(appDir string) string {
+	if !secretsStore().Enabled() {
+		return "./env"
+	}
+	return secrets.RuntimeDir("apps", filepath.Base(appDir))
+}
+
+// MaterializeSecrets decrypts the secrets of an app into its tmpfs runtime
+// directory, along with the global secrets it links. With plaintext secrets,
+// only the linked global secrets are copied into env/
+func MaterializeSecrets(appDir string) error {
+	// env/ is mounted as is with plaintext secrets: keep old histories out of it
+	secrets.MoveLegacyHistory(filepath.Join(appDir, "env"))
+
+	store := secretsStore()
+	globals, err := linkedGlobalSecrets(appDir)
+	if err != nil {
+		return err
+	}
+
+	if !store.Enabled() {
+		for _, path := range globals {
+			value, err := store.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("error reading global secret %s: %w", filepath.Base(path), err)
+			}
+			if err := os.MkdirAll(filepath.Join(appDir, "env"), 0o755); err != nil {
+				return fmt.Errorf("error creating env directory: %w", err)
+			}
+			if err := store.WriteFile(filepath.Join(appDir, "env", filepath.Base(path)), value); err != nil {
+				return fmt.Errorf("error copying global secret %s: %w", filepath.Base(path), err)
+			}
+		}
+		return nil
+	}
+
+	if err := store.Materialize(filepath.Join(appDir, "env"), secrets.RuntimeDir("apps", filepath.Base(appDir)), globals...); err != nil {
+		return fmt.Errorf("error decrypting secrets: %w", err)
+	}
+	return nil
+}
+
+// linkedGlobalSecrets returns the files of the global secrets linked to an app
+func linkedGlobalSecrets(appDir string) ([]string, error) {
+	metadata, err := NewManager("").GetPorticoMetadata(appDir)
+	if err != nil || len(metadata.Global) == 0 {
+		return nil, nil
+	}
+
+	globalDir := secrets.GlobalDir(secrets.DefaultPorticoHome)
+	if cfg, err := config.LoadConfig(); err == nil {
+		globalDir = secrets.GlobalDir(cfg.PorticoHome)
+	}
+
+	var files []string
+	for _, name := range metadata.Global {
+		path := filepath.Join(globalDir, name)
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("global secret %s linked to %s not found", name, filepath.Base(appDir))
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// secretsStore returns the secrets store configured for this host
+func secretsStore() *secrets.Store {
+	keyFile := secrets.DefaultKeyFile
+	if cfg, err := config.LoadConfig(); err == nil {
+		keyFile = cfg.SecretsKey
+	}
+	return secrets.NewStore(keyFile)
+}
+
 // contains checks if a string slice contains a value
 func contains(slice []string, value string) bool {
 	for _, v := range slice {
@@ -518,47 +1085,6 @@ type Service struct {
 	Replicas    int // Number of instances (default: 1, 0 means 1)
 }
 
-// GetContainerStatus returns the status of containers for an app
-func (dm *Manager) GetContainerStatus(appDir string) ([]ContainerStatus, error) {
-	// Validate appDir path to prevent path traversal
-	if !filepath.IsAbs(appDir) {
-		appDir, _ = filepath.Abs(appDir)
-	}
-
-	composeFile := filepath.Join(appDir, "docker-compose.yml")
-	// Extract app name from directory for consistent project naming
-	appName := filepath.Base(appDir)
-	cmd := exec.Command("docker", "compose", "-f", composeFile, "-p", appName, "ps", "--format", "json")
-	cmd.Dir = appDir
-
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("error getting container status: %w", err)
-	}
-
-	var statuses []ContainerStatus
-	scanner := bufio.NewScanner(bytes.NewReader(output))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			// Parse JSON line to extract container info
-			// This is a simplified version - in production you'd use proper JSON parsing
-			statuses = append(statuses, ContainerStatus{
-				Name:   "container", // Extract from JSON
-				Status: "running",   // Extract from JSON
-			})
-		}
-	}
-
-	return statuses, nil
-}
-
-// ContainerStatus represents the status of a container
-type ContainerStatus struct {
-	Name   string
-	Status string
-}
-
 // ensureNetworkExists ensures that a Docker network exists, creating it if necessary
 func (dm *Manager) ensureNetworkExists(networkName string) error {
 	// Check if network exists
@@ -0,0 +1,233 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/secrets"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// BuiltinVariables are injected by Portico into every service
+var BuiltinVariables = []string{
+	"PORTICO_APP",       // App name
+	"PORTICO_SERVICE",   // Service name
+	"PORTICO_DOMAIN",    // App domain
+	"PORTICO_HTTP_PORT", // Port Caddy proxies to (empty when HTTP is disabled)
+	"PORTICO_RELEASE",   // Changes when the image or the environment of the service change
+	"PORTICO_REPLICAS",  // Number of replicas of the service
+	"PORTICO_REPLICA",   // Replica number (1, 2, ...), in services that reference it
+}
+
+// IsBuiltinVariable reports whether a variable is injected by Portico
+func IsBuiltinVariable(key string) bool {
+	return contains(BuiltinVariables, key)
+}
+
+// referencePattern matches ${NAME}, ${addon:instance.field} and the $${ escape
+var referencePattern = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+// HasReferences reports whether an env value references other values
+func HasReferences(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// envResolver expands the references in the environment of a service
+type envResolver struct {
+	env       map[string]string // Raw values, built-ins included
+	resolved  map[string]string
+	resolving map[string]bool // Detects reference cycles
+	addons    *addonFields
+}
+
+// resolve returns the value of a variable with its references expanded
+func (r *envResolver) resolve(key string) (string, error) {
+	if value, ok := r.resolved[key]; ok {
+		return value, nil
+	}
+	if r.resolving[key] {
+		return "", fmt.Errorf("%s references itself", key)
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+
+	value, err := r.expand(r.env[key])
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	r.resolved[key] = value
+	return value, nil
+}
+
+// expand replaces the references in a value; $${ is kept as a literal ${
+func (r *envResolver) expand(value string) (string, error) {
+	var out strings.Builder
+	last := 0
+	for _, match := range referencePattern.FindAllStringSubmatchIndex(value, -1) {
+		out.WriteString(value[last:match[0]])
+		last = match[1]
+		if value[match[0]:match[1]] == "$${" {
+			out.WriteString("${")
+			continue
+		}
+
+		ref := value[match[2]:match[3]]
+		var resolved string
+		var err error
+		if spec, ok := strings.CutPrefix(ref, "addon:"); ok {
+			resolved, err = r.addons.lookup(spec)
+		} else if _, ok := r.env[ref]; ok {
+			resolved, err = r.resolve(ref)
+		} else {
+			err = fmt.Errorf("unresolved reference ${%s}", ref)
+		}
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(resolved)
+	}
+	out.WriteString(value[last:])
+	return out.String(), nil
+}
+
+// addonFields reads the connection details of addon instances for ${addon:...}
+type addonFields struct {
+	app       string // Default database name, as in 'addons link'
+	addonsDir string
+	instances map[string]addon.Instance // Loaded on first use
+	store     *secrets.Store
+}
+
+// newAddonFields returns the addon lookup of an app on this host
+func newAddonFields(app string) *addonFields {
+	addonsDir := "/home/portico/addons"
+	if cfg, err := config.LoadConfig(); err == nil {
+		addonsDir = cfg.AddonsDir
+	}
+	return &addonFields{app: app, addonsDir: addonsDir, store: secretsStore()}
+}
+
+// lookup returns a field of an addon instance (instance.field): host, port,
+// user, database, or any file in the instance's secrets directory. Credentials
+// are refused: resolved values are written in plaintext to docker-compose.yml
+func (a *addonFields) lookup(spec string) (string, error) {
+	dot := strings.LastIndex(spec, ".")
+	if dot <= 0 || dot == len(spec)-1 {
+		return "", fmt.Errorf("invalid addon reference ${addon:%s}, expected ${addon:instance.field}", spec)
+	}
+	name, field := spec[:dot], spec[dot+1:]
+	if field == "password" || util.IsSensitiveKey(field) {
+		return "", fmt.Errorf("${addon:%s} is a credential and would be written in plaintext to docker-compose.yml; store it as a secret of the app and read it from /run/secrets", spec)
+	}
+
+	if a.instances == nil {
+		instancesDir := filepath.Join(a.addonsDir, "instances")
+		addonConfig, err := addon.NewManager(a.addonsDir, instancesDir).LoadConfig()
+		if err != nil {
+			return "", err
+		}
+		a.instances = addonConfig.Instances
+	}
+	instance, ok := a.instances[name]
+	if !ok {
+		return "", fmt.Errorf("unresolved reference ${addon:%s}: addon instance %s not found", spec, name)
+	}
+
+	secretsDir := filepath.Join(a.addonsDir, "instances", name, "secrets")
+	readSecret := func(file string) (string, bool) {
+		data, err := a.store.ReadFile(filepath.Join(secretsDir, file))
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+
+	switch field {
+	case "host":
+		return name, nil // Reachable by name on portico-network
+	case "port":
+		return strconv.Itoa(instance.Port), nil
+	case "user":
+		if value, ok := readSecret("db_user"); ok {
+			return value, nil
+		}
+		if value, ok := readSecret("db_name"); ok { // Same fallback as 'addons link'
+			return value, nil
+		}
+	case "database":
+		if value, ok := readSecret("db_name"); ok {
+			return value, nil
+		}
+		return a.app, nil
+	default:
+		if !strings.HasPrefix(field, ".") {
+			if value, ok := readSecret(field); ok {
+				return value, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unresolved reference ${addon:%s}: addon instance %s has no %s", spec, name, field)
+}
+
+// resolveServiceEnvironment expands the references in the environment of a
+// service, with the built-in variables added
+func resolveServiceEnvironment(environment, builtins map[string]string, addons *addonFields) (map[string]string, error) {
+	env := make(map[string]string, len(environment)+len(builtins))
+	for k, v := range environment {
+		env[k] = v
+	}
+	for k, v := range builtins {
+		env[k] = v // Built-ins can't be overridden
+	}
+
+	r := &envResolver{
+		env:       env,
+		resolved:  make(map[string]string),
+		resolving: make(map[string]bool),
+		addons:    addons,
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // Report the same error on every run
+	resolved := make(map[string]string, len(env))
+	for _, k := range keys {
+		value, err := r.resolve(k)
+		if err != nil {
+			return nil, err
+		}
+		resolved[k] = value
+	}
+	return resolved, nil
+}
+
+// releaseID identifies the revision of a service: a hash of its image (the
+// local image ID when available, so rebuilt :latest images count) and its
+// environment as configured, built-ins excluded
+func releaseID(image string, environment map[string]string) string {
+	h := sha256.New()
+	h.Write([]byte(image + "\n"))
+	if out, err := exec.Command("docker", "image", "inspect", "--format", "{{.Id}}", image).Output(); err == nil {
+		h.Write(out)
+	}
+	keys := make([]string, 0, len(environment))
+	for k := range environment {
+		if !IsBuiltinVariable(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, environment[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:12]
+}
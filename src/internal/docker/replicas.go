@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Docker compose starts every replica of a service with the same environment,
+// so a service that references ${PORTICO_REPLICA} is rendered as one compose
+// service per replica instead of deploy.replicas: the first replica keeps the
+// service name and the others are <service>-2, <service>-3, ... Every replica
+// is labelled with its service and number, and has the container name it would
+// have under deploy.replicas (<app>-<service>-<n>) as network alias. x-portico
+// records the number of replicas (indexed_replicas), so Portico reads them
+// back as one service
+
+// Labels of the containers of a service rendered per replica
+const (
+	ReplicaOfLabel = "portico.replica-of"
+	ReplicaLabel   = "portico.replica"
+)
+
+// ReplicaServiceName returns the docker-compose.yml service of a replica
+// (1, 2, ...) of a service rendered per replica
+func ReplicaServiceName(service string, replica int) string {
+	if replica <= 1 {
+		return service
+	}
+	return fmt.Sprintf("%s-%d", service, replica)
+}
+
+// IndexedReplicas returns the number of replicas of a service rendered per
+// replica, or 0 if the service uses deploy.replicas
+func IndexedReplicas(metadata *PorticoMetadata, service string) int {
+	if metadata == nil {
+		return 0
+	}
+	return metadata.Indexed[service]
+}
+
+// IndexedReplicaOf reports whether a docker-compose.yml service is a replica,
+// other than the first, of a service rendered per replica, and of which
+func IndexedReplicaOf(metadata *PorticoMetadata, composeService string) (string, bool) {
+	if metadata == nil {
+		return "", false
+	}
+	for service, replicas := range metadata.Indexed {
+		for replica := 2; replica <= replicas; replica++ {
+			if ReplicaServiceName(service, replica) == composeService {
+				return service, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ContainerService returns the service and replica number of a container from
+// its labels: the Portico labels of services rendered per replica, or the
+// docker compose ones
+func ContainerService(labels map[string]string) (string, int) {
+	if service := labels[ReplicaOfLabel]; service != "" {
+		replica, _ := strconv.Atoi(labels[ReplicaLabel])
+		return service, replica
+	}
+	replica, _ := strconv.Atoi(labels["com.docker.compose.container-number"])
+	return labels["com.docker.compose.service"], replica
+}
+
+// usesReplicaIndex reports whether an environment references ${PORTICO_REPLICA}
+func usesReplicaIndex(environment map[string]string) bool {
+	for _, value := range environment {
+		for _, match := range referencePattern.FindAllStringSubmatch(value, -1) {
+			if match[1] == "PORTICO_REPLICA" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// ResourcesConfig stores the CPU, memory and process limits of a service and
+// the resources reserved for it, rendered as deploy.resources in docker-compose.yml
+type ResourcesConfig struct {
+	CPUs              string `yaml:"cpus,omitempty" json:"cpus,omitempty"`                             // Limit in CPUs, e.g. 0.5
+	Memory            string `yaml:"memory,omitempty" json:"memory,omitempty"`                         // Limit, docker size, e.g. 512m
+	Pids              int    `yaml:"pids,omitempty" json:"pids,omitempty"`                             // Max number of processes
+	CPUsReservation   string `yaml:"cpus_reservation,omitempty" json:"cpus_reservation,omitempty"`     // Reserved CPUs
+	MemoryReservation string `yaml:"memory_reservation,omitempty" json:"memory_reservation,omitempty"` // Reserved memory (soft limit)
+}
+
+// IsEmpty reports whether the service is no longer limited
+func (r ResourcesConfig) IsEmpty() bool {
+	return !r.HasLimits() && !r.HasReservations()
+}
+
+// HasLimits reports whether a limit is set
+func (r ResourcesConfig) HasLimits() bool {
+	return r.CPUs != "" || r.Memory != "" || r.Pids > 0
+}
+
+// HasReservations reports whether a reservation is set
+func (r ResourcesConfig) HasReservations() bool {
+	return r.CPUsReservation != "" || r.MemoryReservation != ""
+}
+
+// MemoryBytes returns the memory limit in bytes (0 without a limit)
+func (r ResourcesConfig) MemoryBytes() uint64 {
+	n, _ := util.ParseBytes(r.Memory, util.BinarySizes)
+	return n
+}
+
+// String describes the limits and reservations, e.g. "cpus 0.5, memory 512m"
+func (r ResourcesConfig) String() string {
+	var parts []string
+	if r.CPUs != "" {
+		parts = append(parts, "cpus "+r.CPUs)
+	}
+	if r.Memory != "" {
+		parts = append(parts, "memory "+r.Memory)
+	}
+	if r.Pids > 0 {
+		parts = append(parts, fmt.Sprintf("pids %d", r.Pids))
+	}
+	if r.CPUsReservation != "" {
+		parts = append(parts, "reserved cpus "+r.CPUsReservation)
+	}
+	if r.MemoryReservation != "" {
+		parts = append(parts, "reserved memory "+r.MemoryReservation)
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// serviceResources returns the resources of a service, nil if it is unlimited
+func serviceResources(metadata *PorticoMetadata, service string) *ResourcesConfig {
+	if metadata == nil {
+		return nil
+	}
+	resources, ok := metadata.Resources[service]
+	if !ok || resources.IsEmpty() {
+		return nil
+	}
+	return &resources
+}
+
+// MemoryCommitment is the memory limit of a service, times its replicas
+type MemoryCommitment struct {
+	App      string
+	Service  string
+	Replicas int
+	Limit    uint64 // Bytes per replica
+}
+
+// CommittedMemory returns the memory limits of the services of every app in
+// appsDir. Services without a memory limit are left out
+func (dm *Manager) CommittedMemory(appsDir string) ([]MemoryCommitment, error) {
+	entries, err := os.ReadDir(appsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var commitments []MemoryCommitment
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		appDir := filepath.Join(appsDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(appDir, "docker-compose.yml")); err != nil {
+			continue
+		}
+		compose, err := dm.LoadComposeFile(appDir)
+		if err != nil || compose.XPortico == nil {
+			continue
+		}
+		for service, resources := range compose.XPortico.Resources {
+			svc, ok := compose.Services[service].(map[string]interface{})
+			if !ok || resources.MemoryBytes() == 0 {
+				continue
+			}
+			commitments = append(commitments, MemoryCommitment{
+				App:      entry.Name(),
+				Service:  service,
+				Replicas: max(composeReplicas(svc), IndexedReplicas(compose.XPortico, service)),
+				Limit:    resources.MemoryBytes(),
+			})
+		}
+	}
+	return commitments, nil
+}
+
+// composeReplicas returns deploy.replicas of a docker-compose.yml service (1 if unset)
+func composeReplicas(svc map[string]interface{}) int {
+	if deploy, ok := svc["deploy"].(map[string]interface{}); ok {
+		if replicas, ok := deploy["replicas"].(int); ok && replicas > 0 {
+			return replicas
+		}
+	}
+	return 1
+}
+
+// HostMemory returns the memory of the Docker host in bytes (docker info)
+func (dm *Manager) HostMemory() (uint64, error) {
+	output, err := exec.Command("docker", "info", "--format", "{{.MemTotal}}").Output()
+	if err != nil {
+		return 0, fmt.Errorf("error getting host memory: %w", err)
+	}
+	memory, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil || memory == 0 {
+		return 0, fmt.Errorf("error getting host memory: unexpected docker info output %q", strings.TrimSpace(string(output)))
+	}
+	return memory, nil
+}
@@ -0,0 +1,230 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/maxvegac/portico/src/internal/notify"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// Release is what an app runs, kept before a deploy to roll back to
+type Release struct {
+	Compose []byte            // docker-compose.yml
+	Images  map[string]string // Image ID of the running containers, by image reference
+}
+
+// CurrentRelease returns the release an app runs, or nil if it runs no
+// containers (new or stopped apps). The image IDs let a rollback restore images
+// whose tag was moved by the new build (e.g. portico-app:latest)
+func (dm *Manager) CurrentRelease(appDir string) (*Release, error) {
+	data, err := os.ReadFile(filepath.Join(appDir, "docker-compose.yml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	release := &Release{Compose: data, Images: make(map[string]string)}
+	statuses, err := dm.GetContainerStatus(appDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range statuses {
+		if c.Running() && c.Image != "" && c.ImageID != "" {
+			release.Images[c.Image] = c.ImageID
+		}
+	}
+	if len(release.Images) == 0 {
+		return nil, nil
+	}
+	return release, nil
+}
+
+// DeployWatch configures how an app is watched after a deploy
+type DeployWatch struct {
+	Window      time.Duration // How long the containers are watched
+	MaxRestarts int           // Restarts of a container within the window that make a crash loop
+	Interval    time.Duration // Time between checks
+}
+
+// WatchFailure is why a release failed the deploy watch
+type WatchFailure struct {
+	Container string
+	Reason    string // e.g. "restarted 3 times", "exited with code 1"
+	CrashLoop bool   // false: the health check failed
+}
+
+// Error implements error
+func (f *WatchFailure) Error() string {
+	return f.Container + " " + f.Reason
+}
+
+// deployWatchFile marks an app whose deploy is being watched; it holds the
+// time the watch ends, in case the watch is killed before removing it
+const deployWatchFile = ".deploy-watch"
+
+// DeployWatchRunning reports whether the deploy of an app is being watched.
+// Portico daemon doesn't restart the containers of the app meanwhile: a
+// crashed container must stay exited for the watch to roll back right away
+func DeployWatchRunning(appDir string) bool {
+	data, err := os.ReadFile(filepath.Join(appDir, deployWatchFile))
+	if err != nil {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	return err == nil && time.Now().Before(until)
+}
+
+// WatchDeploy watches the containers of an app after a deploy. Returns a
+// *WatchFailure if, within the window, a container restarts MaxRestarts
+// times, exits with an error, or fails its health check. Containers still
+// starting their health check when the window ends pass
+func (dm *Manager) WatchDeploy(appDir string, watch DeployWatch) error {
+	if watch.Interval <= 0 {
+		watch.Interval = 2 * time.Second
+	}
+	if watch.MaxRestarts <= 0 {
+		watch.MaxRestarts = 3
+	}
+
+	// Restarts are counted from the first check, for containers the deploy
+	// kept. docker only counts the restarts of its restart policy, so a new
+	// start time counts too (at least one restart since the previous check)
+	type seen struct {
+		startedAt    string
+		restartCount int
+		restarts     int
+	}
+	tracked := make(map[string]*seen)
+	deadline := time.Now().Add(watch.Window)
+
+	// Keep the daemon from restarting containers that crash during the watch
+	marker := filepath.Join(appDir, deployWatchFile)
+	until := deadline.Add(time.Minute).UTC().Format(time.RFC3339)
+	if err := os.WriteFile(marker, []byte(until+"\n"), 0o644); err == nil {
+		defer os.Remove(marker)
+	}
+	for {
+		statuses, err := dm.GetContainerStatus(appDir)
+		if err != nil {
+			return err
+		}
+		for _, c := range statuses {
+			s, ok := tracked[c.ID]
+			if !ok {
+				s = &seen{startedAt: c.StartedAt, restartCount: c.RestartCount}
+				tracked[c.ID] = s
+			} else if c.StartedAt != "" && c.StartedAt != s.startedAt {
+				s.restarts += max(c.RestartCount-s.restartCount, 1)
+				s.startedAt, s.restartCount = c.StartedAt, c.RestartCount
+			}
+			if failure := checkContainer(c, s.restarts, watch.MaxRestarts); failure != nil {
+				if failure.CrashLoop {
+					event := notify.NewEvent(notify.EventCrashLoop, filepath.Base(appDir),
+						fmt.Sprintf("%s is crash-looping", c.Name), failure.Reason)
+					event.Details["image"] = c.Image
+					notify.Notify(event)
+				}
+				return failure
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+		time.Sleep(watch.Interval)
+	}
+}
+
+// checkContainer returns why a container fails the deploy watch, if it does
+func checkContainer(c ContainerStatus, restarts, maxRestarts int) *WatchFailure {
+	switch {
+	case restarts >= maxRestarts:
+		return &WatchFailure{Container: c.Name, Reason: fmt.Sprintf("restarted %d times", restarts), CrashLoop: true}
+	case c.State == "dead" || c.State == "exited" && c.ExitCode != 0:
+		return &WatchFailure{Container: c.Name, Reason: fmt.Sprintf("exited with code %d", c.ExitCode), CrashLoop: true}
+	case c.Health == "unhealthy":
+		return &WatchFailure{Container: c.Name, Reason: "failed its health check"}
+	}
+	return nil
+}
+
+// RollbackApp restores a release of an app and redeploys it. Images whose tag
+// now points to another image are tagged back to the image the release ran.
+// The rollback is recorded in the deploy history with the reason
+func (dm *Manager) RollbackApp(appDir string, release *Release, reason string) (err error) {
+	start := time.Now()
+	appName := filepath.Base(appDir)
+	images := composeImages(release.Compose)
+
+	defer func() {
+		record := newDeployRecord(start, nil, err)
+		record.Images = images
+		record.Action = "rollback"
+		record.Reason = reason
+		_ = recordDeploy(appDir, record)
+
+		title := fmt.Sprintf("Rolled back %s", appName)
+		message := reason
+		if err != nil {
+			title = fmt.Sprintf("Rollback of %s failed", appName)
+			message += "\n" + err.Error()
+		}
+		event := notify.NewEvent(notify.EventRollback, appName, title, message)
+		var list []string
+		for service, image := range images {
+			list = append(list, service+"="+image)
+		}
+		sort.Strings(list)
+		event.Details["images"] = strings.Join(list, ", ")
+		notify.Notify(event)
+	}()
+
+	for ref, id := range release.Images {
+		output, err := exec.Command("docker", "image", "inspect", "--format", "{{.Id}}", ref).Output()
+		if err == nil && strings.TrimSpace(string(output)) == id {
+			continue
+		}
+		if output, err := exec.Command("docker", "tag", id, ref).CombinedOutput(); err != nil {
+			return fmt.Errorf("error restoring image %s: %s\n%s", ref, err, string(output))
+		}
+	}
+
+	composeFile := filepath.Join(appDir, "docker-compose.yml")
+	if err := os.WriteFile(composeFile, release.Compose, 0o644); err != nil {
+		return fmt.Errorf("error restoring docker-compose.yml: %w", err)
+	}
+	_ = util.FixFileOwnership(composeFile)
+
+	// Replicas are in the deploy section of docker-compose.yml
+	return dm.composeUp(appDir, nil)
+}
+
+// composeImages returns the image of each service of a docker-compose.yml
+func composeImages(data []byte) map[string]string {
+	images := make(map[string]string)
+	var compose ComposeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return images
+	}
+	for name, svc := range compose.Services {
+		if _, ok := IndexedReplicaOf(compose.XPortico, name); ok {
+			continue // Same image as the service
+		}
+		if svcMap, ok := svc.(map[string]interface{}); ok {
+			if image, ok := svcMap["image"].(string); ok {
+				images[name] = image
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,354 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// ContainerStatus represents the status of a container (one replica of a service)
+type ContainerStatus struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Service       string  `json:"service"`
+	Replica       int     `json:"replica"`          // Replica number (1, 2, ...)
+	State         string  `json:"state"`            // running, exited, restarting, paused, created, dead
+	Status        string  `json:"status"`           // Human readable, e.g. "Up 2 hours (healthy)"
+	Health        string  `json:"health,omitempty"` // healthy, unhealthy, starting (empty without health check)
+	RestartCount  int     `json:"restart_count"`
+	StartedAt     string  `json:"started_at,omitempty"` // RFC3339
+	Created       string  `json:"created,omitempty"`    // RFC3339, when the container was (re)created by a deploy
+	ExitCode      int     `json:"exit_code"`
+	Image         string  `json:"image"`
+	ImageID       string  `json:"image_id,omitempty"`
+	ImageDigest   string  `json:"image_digest,omitempty"` // Registry digest (repo@sha256:...), when pulled from a registry
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryUsage   uint64  `json:"memory_usage"` // Bytes
+	MemoryLimit   uint64  `json:"memory_limit"` // Bytes
+	MemoryPercent float64 `json:"memory_percent"`
+	NetworkRx     uint64  `json:"network_rx"`    // Bytes received since the container started
+	NetworkTx     uint64  `json:"network_tx"`    // Bytes sent since the container started
+	NetworkExact  bool    `json:"network_exact"` // Network bytes read from /proc (docker stats rounds them)
+
+	pid int // Main process, to read the network counters
+}
+
+// Running reports whether the container is running
+func (c ContainerStatus) Running() bool {
+	return c.State == "running"
+}
+
+// composePsEntry is a container as printed by docker compose ps --format json
+type composePsEntry struct {
+	ID       string `json:"ID"`
+	Name     string `json:"Name"`
+	Service  string `json:"Service"`
+	State    string `json:"State"`
+	Status   string `json:"Status"`
+	Health   string `json:"Health"`
+	ExitCode int    `json:"ExitCode"`
+	Image    string `json:"Image"`
+}
+
+// containerInspect holds the fields of docker inspect used for the status
+type containerInspect struct {
+	ID           string `json:"Id"`
+	Created      string `json:"Created"`
+	Image        string `json:"Image"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Pid       int    `json:"Pid"`
+		StartedAt string `json:"StartedAt"`
+		ExitCode  int    `json:"ExitCode"`
+		Health    *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// GetContainerStatus returns the status of every container of an app (all
+// replicas, stopped ones included), sorted by service and replica. CPU and
+// memory are filled by AddContainerStats
+func (dm *Manager) GetContainerStatus(appDir string) ([]ContainerStatus, error) {
+	// Validate appDir path to prevent path traversal
+	if !filepath.IsAbs(appDir) {
+		appDir, _ = filepath.Abs(appDir)
+	}
+
+	composeFile := filepath.Join(appDir, "docker-compose.yml")
+	// Extract app name from directory for consistent project naming
+	appName := filepath.Base(appDir)
+	cmd := exec.Command("docker", "compose", "-f", composeFile, "-p", appName, "ps", "--all", "--format", "json")
+	cmd.Dir = appDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting container status: %w", err)
+	}
+
+	entries, err := parseComposePs(output)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing container status: %w", err)
+	}
+
+	var statuses []ContainerStatus
+	var ids []string
+	for _, e := range entries {
+		statuses = append(statuses, ContainerStatus{
+			ID:       e.ID,
+			Name:     e.Name,
+			Service:  e.Service,
+			Replica:  1,
+			State:    e.State,
+			Status:   e.Status,
+			Health:   e.Health,
+			ExitCode: e.ExitCode,
+			Image:    e.Image,
+		})
+		ids = append(ids, e.ID)
+	}
+	if len(statuses) == 0 {
+		return statuses, nil
+	}
+
+	// Restart count, start time, replica number and image from docker inspect
+	inspected, err := inspectContainers(ids)
+	if err != nil {
+		return nil, err
+	}
+	imageIDs := make(map[string]bool)
+	for i := range statuses {
+		info, ok := inspected[statuses[i].ID]
+		if !ok {
+			continue
+		}
+		statuses[i].RestartCount = info.RestartCount
+		statuses[i].Created = info.Created
+		statuses[i].ExitCode = info.State.ExitCode
+		statuses[i].pid = info.State.Pid
+		if !strings.HasPrefix(info.State.StartedAt, "0001-") {
+			statuses[i].StartedAt = info.State.StartedAt
+		}
+		if info.State.Health != nil {
+			statuses[i].Health = info.State.Health.Status
+		}
+		// Services rendered per replica are reported as replicas of the service
+		if service, replica := ContainerService(info.Config.Labels); service != "" && replica > 0 {
+			statuses[i].Service = service
+			statuses[i].Replica = replica
+		}
+		statuses[i].ImageID = info.Image
+		imageIDs[info.Image] = true
+	}
+
+	// Registry digests of the images
+	digests := imageDigests(imageIDs)
+	for i := range statuses {
+		statuses[i].ImageDigest = digests[statuses[i].ImageID]
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Service != statuses[j].Service {
+			return statuses[i].Service < statuses[j].Service
+		}
+		return statuses[i].Replica < statuses[j].Replica
+	})
+
+	return statuses, nil
+}
+
+// parseComposePs parses docker compose ps --format json: one object per line
+// (compose >= 2.21) or a single array (older versions)
+func parseComposePs(output []byte) ([]composePsEntry, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, nil
+	}
+	var entries []composePsEntry
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry composePsEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// inspectContainers runs docker inspect on containers, by container ID (or prefix)
+func inspectContainers(ids []string) (map[string]containerInspect, error) {
+	output, err := exec.Command("docker", append([]string{"inspect"}, ids...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error inspecting containers: %w", err)
+	}
+	var inspected []containerInspect
+	if len(bytes.TrimSpace(output)) > 0 {
+		if err := json.Unmarshal(output, &inspected); err != nil {
+			return nil, fmt.Errorf("error parsing docker inspect: %w", err)
+		}
+	}
+
+	// docker compose ps prints short IDs
+	result := make(map[string]containerInspect)
+	for _, info := range inspected {
+		for _, id := range ids {
+			if id != "" && strings.HasPrefix(info.ID, id) {
+				result[id] = info
+			}
+		}
+	}
+	return result, nil
+}
+
+// imageDigests returns the first registry digest of each image ID; images built
+// locally have none
+func imageDigests(imageIDs map[string]bool) map[string]string {
+	digests := make(map[string]string)
+	for id := range imageIDs {
+		if id == "" {
+			continue
+		}
+		output, err := exec.Command("docker", "image", "inspect", "--format", "{{json .RepoDigests}}", id).Output()
+		if err != nil {
+			continue
+		}
+		var repoDigests []string
+		if err := json.Unmarshal(bytes.TrimSpace(output), &repoDigests); err == nil && len(repoDigests) > 0 {
+			digests[id] = repoDigests[0]
+		}
+	}
+	return digests
+}
+
+// containerStatsEntry is a container as printed by docker stats --format json
+type containerStatsEntry struct {
+	ID       string `json:"ID"`
+	CPUPerc  string `json:"CPUPerc"`  // e.g. 0.52%
+	MemUsage string `json:"MemUsage"` // e.g. 12.5MiB / 1.944GiB
+	MemPerc  string `json:"MemPerc"`  // e.g. 0.63%
+	NetIO    string `json:"NetIO"`    // e.g. 1.2kB / 648B
+}
+
+// AddContainerStats fills CPU, memory and network usage of the running
+// containers with docker stats (takes about a second)
+func (dm *Manager) AddContainerStats(statuses []ContainerStatus) error {
+	var ids []string
+	for _, s := range statuses {
+		if s.Running() && s.ID != "" {
+			ids = append(ids, s.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := append([]string{"stats", "--no-stream", "--format", "{{json .}}"}, ids...)
+	output, err := exec.Command("docker", args...).Output()
+	if err != nil {
+		return fmt.Errorf("error getting container stats: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry containerStatsEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return fmt.Errorf("error parsing container stats: %w", err)
+		}
+		for i := range statuses {
+			if statuses[i].ID == "" || !strings.HasPrefix(entry.ID, statuses[i].ID) && !strings.HasPrefix(statuses[i].ID, entry.ID) {
+				continue
+			}
+			statuses[i].CPUPercent = parsePercent(entry.CPUPerc)
+			statuses[i].MemoryPercent = parsePercent(entry.MemPerc)
+			if usage, limit, ok := strings.Cut(entry.MemUsage, "/"); ok {
+				statuses[i].MemoryUsage = parseSize(usage)
+				statuses[i].MemoryLimit = parseSize(limit)
+			}
+			if rx, tx, ok := readNetDev(statuses[i].pid); ok {
+				statuses[i].NetworkRx, statuses[i].NetworkTx = rx, tx
+				statuses[i].NetworkExact = true
+			} else if rx, tx, ok := strings.Cut(entry.NetIO, "/"); ok {
+				statuses[i].NetworkRx = parseSize(rx)
+				statuses[i].NetworkTx = parseSize(tx)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// readNetDev returns the bytes received and sent by the network interfaces of
+// the namespace of a process (loopback excluded), from /proc/<pid>/net/dev.
+// Needs access to the host /proc, as root
+func readNetDev(pid int) (rx, tx uint64, ok bool) {
+	if pid <= 0 {
+		return 0, 0, false
+	}
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "net", "dev"))
+	if err != nil {
+		return 0, 0, false
+	}
+	// Two header lines, then "iface: rx_bytes rx_packets ... (8 fields) tx_bytes ..."
+	lines := strings.Split(string(data), "\n")
+	if len(lines) < 2 {
+		return 0, 0, false
+	}
+	for _, line := range lines[2:] {
+		iface, counters, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(iface) == "lo" {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 9 {
+			continue
+		}
+		r, err1 := strconv.ParseUint(fields[0], 10, 64)
+		t, err2 := strconv.ParseUint(fields[8], 10, 64)
+		if err1 != nil || err2 != nil {
+			return 0, 0, false
+		}
+		rx += r
+		tx += t
+	}
+	return rx, tx, true
+}
+
+// parseSize parses a docker stats size (e.g. 12.5MiB, 1.2kB) into bytes, 0 if
+// it is not a size
+func parseSize(value string) uint64 {
+	n, _ := util.ParseBytes(value, util.DecimalSizes)
+	return n
+}
+
+// parsePercent parses a docker stats percentage (e.g. 12.34%)
+func parsePercent(value string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	return f
+}
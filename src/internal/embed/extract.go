@@ -14,6 +14,7 @@ func ExtractStaticFiles(targetDir string) error {
 		"static/reverse-proxy/Caddyfile",
 		"static/config.yml",
 		"static/reverse-proxy/docker-compose.yml",
+		"static/reverse-proxy/Dockerfile",
 		"static/www/index.html",
 	}
 
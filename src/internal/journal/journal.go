@@ -0,0 +1,106 @@
+// Package journal records who changed what on the server: every mutating
+// command appends an event to logs/events.log, one JSON object per line
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// Event is a change made by a command. Summaries never hold secret values:
+// secrets are recorded by name and version, sensitive env values masked
+type Event struct {
+	Time    string `json:"time"`  // RFC3339
+	Actor   string `json:"actor"` // SSH key name or Unix user
+	App     string `json:"app,omitempty"`
+	Action  string `json:"action"`            // The command, e.g. env add, apps destroy
+	Summary string `json:"summary,omitempty"` // What changed, e.g. API_URL for service web
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
+	Error   string `json:"error,omitempty"` // First line of the error, if the action failed
+}
+
+// Path returns the journal file of a Portico home
+func Path(porticoHome string) string {
+	return filepath.Join(porticoHome, "logs", "events.log")
+}
+
+// Append appends an event to the journal, filling in its time and actor
+func Append(porticoHome string, event Event) error {
+	if event.Time == "" {
+		event.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	if event.Actor == "" {
+		event.Actor = util.CurrentActor(porticoHome)
+	}
+	event.Error, _, _ = strings.Cut(event.Error, "\n")
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	path := Path(porticoHome)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening journal: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing journal: %w", err)
+	}
+	_ = util.FixFileOwnership(path)
+	return nil
+}
+
+// Record appends an event to the journal and prints a warning if it can't.
+// The journal never fails the command it records
+func Record(event Event) {
+	cfg, err := config.LoadConfig()
+	if err == nil {
+		err = Append(cfg.PorticoHome, event)
+	}
+	if err != nil {
+		fmt.Printf("Warning: could not record event: %v\n", err)
+	}
+}
+
+// Read returns the events of the journal, oldest first
+func Read(porticoHome string) ([]Event, error) {
+	file, err := os.Open(Path(porticoHome))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue // Skip a line cut by a crash
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/maxvegac/portico/src/internal/accesslog"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// maxAccessLogRead is how much of an access log is read per update; the rest
+// is read by the next updates
+const maxAccessLogRead = 64 << 20
+
+// RequestStats are the request counters of an app
+type RequestStats struct {
+	Requests    map[int]uint64 `json:"requests"`     // By status code
+	DurationSum float64        `json:"duration_sum"` // Seconds
+	BytesSent   uint64         `json:"bytes_sent"`
+}
+
+// accessLogPosition is how far an access log has been read
+type accessLogPosition struct {
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// AccessLogs counts the requests of each app from the Caddy access logs
+// (logs/apps/<app>.log, JSON). Each update only reads what was appended since
+// the previous one; a rotated or truncated log is read again from the start
+type AccessLogs struct {
+	Dir       string                        `json:"-"`
+	Positions map[string]*accessLogPosition `json:"positions"`
+	Apps      map[string]*RequestStats      `json:"apps"`
+}
+
+// NewAccessLogs returns counters for the access logs of dir
+func NewAccessLogs(dir string) *AccessLogs {
+	return &AccessLogs{
+		Dir:       dir,
+		Positions: make(map[string]*accessLogPosition),
+		Apps:      make(map[string]*RequestStats),
+	}
+}
+
+// LoadAccessLogs returns the counters saved in stateFile, or new counters if
+// it doesn't exist
+func LoadAccessLogs(dir, stateFile string) (*AccessLogs, error) {
+	logs := NewAccessLogs(dir)
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return logs, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, logs); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", stateFile, err)
+	}
+	if logs.Positions == nil {
+		logs.Positions = make(map[string]*accessLogPosition)
+	}
+	if logs.Apps == nil {
+		logs.Apps = make(map[string]*RequestStats)
+	}
+	return logs, nil
+}
+
+// Save writes the counters to stateFile
+func (l *AccessLogs) Save(stateFile string) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	tmp := stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, stateFile); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = util.FixFileOwnership(stateFile)
+	return nil
+}
+
+// Update reads the new lines of the access logs of apps
+func (l *AccessLogs) Update(apps []string) error {
+	for _, app := range apps {
+		if err := l.updateApp(app); err != nil {
+			return fmt.Errorf("error reading access log of %s: %w", app, err)
+		}
+	}
+	return nil
+}
+
+// updateApp reads the new lines of the access log of an app
+func (l *AccessLogs) updateApp(app string) error {
+	file, err := os.Open(filepath.Join(l.Dir, app+".log"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	var inode uint64
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		inode = stat.Ino
+	}
+
+	position := l.Positions[app]
+	if position == nil || position.Inode != inode || position.Offset > info.Size() {
+		// New, rotated or truncated log
+		position = &accessLogPosition{Inode: inode}
+		l.Positions[app] = position
+	}
+	if position.Offset == info.Size() {
+		return nil
+	}
+
+	if _, err := file.Seek(position.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxAccessLogRead))
+	if err != nil {
+		return err
+	}
+	// Only complete lines: the last one may still be being written
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil
+	}
+	data = data[:end+1]
+	position.Offset += int64(len(data))
+
+	stats := l.Apps[app]
+	if stats == nil {
+		stats = &RequestStats{Requests: make(map[int]uint64)}
+		l.Apps[app] = stats
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		entry, ok := accesslog.Parse(line)
+		if !ok {
+			continue
+		}
+		stats.Requests[entry.Status]++
+		stats.DurationSum += entry.Duration
+		stats.BytesSent += entry.Size
+	}
+	return nil
+}
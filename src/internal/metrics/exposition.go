@@ -0,0 +1,75 @@
+// Package metrics provides the Prometheus exporter of Portico: the text
+// exposition format and the request counters read from the Caddy access logs
+package metrics
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Writer builds metrics in the Prometheus text exposition format
+type Writer struct {
+	b strings.Builder
+}
+
+// Family starts a metric family: samples of the family follow it. typ is
+// counter, gauge or summary
+func (w *Writer) Family(name, typ, help string) {
+	w.b.WriteString("# HELP " + name + " " + escapeHelp(help) + "\n")
+	w.b.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// Sample writes a sample; labels are name/value pairs
+func (w *Writer) Sample(name string, value float64, labels ...string) {
+	w.b.WriteString(name)
+	if len(labels) > 0 {
+		w.b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			w.b.WriteString(labels[i] + `="` + escapeLabel(labels[i+1]) + `"`)
+		}
+		w.b.WriteByte('}')
+	}
+	w.b.WriteByte(' ')
+	w.b.WriteString(formatValue(value))
+	w.b.WriteByte('\n')
+}
+
+// String returns the metrics written
+func (w *Writer) String() string {
+	return w.b.String()
+}
+
+// Bool returns 1 for true and 0 for false
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// formatValue formats a sample value
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes a help text
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
@@ -0,0 +1,233 @@
+// Package notify sends Portico events (deploys, rollbacks, crash loops) to the
+// notification channels of config.yml: generic webhooks, Slack-compatible
+// webhooks and email
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/maxvegac/portico/src/internal/config"
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// Events
+const (
+	EventDeployStarted   = "deploy_started"
+	EventDeploySucceeded = "deploy_succeeded"
+	EventDeployFailed    = "deploy_failed"
+	EventRollback        = "rollback"
+	EventCrashLoop       = "crash_loop"
+	EventContainerDied   = "container_died"
+	EventUnhealthy       = "container_unhealthy"
+	EventBackupFailed    = "backup_failed"
+	EventTest            = "test"
+)
+
+// Events are the events channels can subscribe to
+var Events = []string{EventDeployStarted, EventDeploySucceeded, EventDeployFailed, EventRollback, EventCrashLoop, EventContainerDied, EventUnhealthy, EventBackupFailed}
+
+// timeout is how long a notification may take
+const timeout = 10 * time.Second
+
+// Event is something that happened on the server; it is the data of message
+// templates and the JSON body of webhooks
+type Event struct {
+	Event   string            `json:"event"`
+	App     string            `json:"app,omitempty"`
+	Title   string            `json:"title"`
+	Message string            `json:"message,omitempty"`
+	Host    string            `json:"host"`
+	Actor   string            `json:"actor,omitempty"`
+	Time    string            `json:"time"` // RFC3339
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// DefaultTemplate is the message of channels without a template
+const DefaultTemplate = `[{{.Host}}] {{.Title}}{{if .Message}}
+{{.Message}}{{end}}{{range $k, $v := .Details}}
+{{$k}}: {{$v}}{{end}}`
+
+// NewEvent returns an event of an app at the current time
+func NewEvent(event, app, title, message string) Event {
+	host, _ := os.Hostname()
+	return Event{
+		Event:   event,
+		App:     app,
+		Title:   title,
+		Message: message,
+		Host:    host,
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Details: make(map[string]string),
+	}
+}
+
+// Wants reports whether a channel receives an event
+func Wants(channel config.Notification, event Event) bool {
+	if event.Event == EventTest {
+		return true
+	}
+	if len(channel.Events) > 0 && !contains(channel.Events, event.Event) {
+		return false
+	}
+	if len(channel.Apps) > 0 && event.App != "" && !contains(channel.Apps, event.App) {
+		return false
+	}
+	return true
+}
+
+// Send sends an event to every channel that wants it. Returns the error of
+// each channel that failed, by channel name
+func Send(cfg *config.Config, event Event) map[string]error {
+	errs := make(map[string]error)
+	for _, channel := range cfg.Notifications {
+		if !Wants(channel, event) {
+			continue
+		}
+		if err := SendTo(cfg, channel, event); err != nil {
+			errs[channel.Name] = err
+		}
+	}
+	return errs
+}
+
+// Notify sends an event and prints a warning for each channel that failed.
+// Notifications never fail the operation they report
+func Notify(event Event) {
+	<-NotifyBackground(event)
+}
+
+// Pending is closed once a notification sent in the background was tried on
+// every channel
+type Pending <-chan struct{}
+
+// NotifyBackground sends an event like Notify without waiting for the
+// channels. The config is loaded before returning: viper isn't safe for
+// concurrent use
+func NotifyBackground(event Event) Pending {
+	done := make(chan struct{})
+	cfg, err := config.LoadConfig()
+	if err != nil || len(cfg.Notifications) == 0 {
+		close(done)
+		return done
+	}
+	if event.Actor == "" {
+		event.Actor = util.CurrentActor(cfg.PorticoHome)
+	}
+	go func() {
+		defer close(done)
+		for name, err := range Send(cfg, event) {
+			fmt.Printf("Warning: notification %s failed: %v\n", name, err)
+		}
+	}()
+	return done
+}
+
+// Wait waits for notifications sent in the background, at most maxWait in
+// total. Returns false if some were still being sent; they are lost when the
+// process exits
+func Wait(maxWait time.Duration, pending ...Pending) bool {
+	deadline := time.NewTimer(maxWait)
+	defer deadline.Stop()
+	for _, p := range pending {
+		select {
+		case <-p:
+		case <-deadline.C:
+			return false
+		}
+	}
+	return true
+}
+
+// SendTo sends an event to a channel
+func SendTo(cfg *config.Config, channel config.Notification, event Event) error {
+	message, err := Render(channel, event)
+	if err != nil {
+		return err
+	}
+
+	switch channel.Type {
+	case "webhook":
+		body := []byte(message)
+		contentType := "text/plain; charset=utf-8"
+		if channel.Template == "" {
+			// The event as JSON, for programs
+			body, err = json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			contentType = "application/json"
+		}
+		return post(channel, body, contentType)
+	case "slack":
+		body, err := json.Marshal(map[string]string{"text": message})
+		if err != nil {
+			return err
+		}
+		return post(channel, body, "application/json")
+	case "email":
+		return sendEmail(cfg.SMTP, channel.To, event.Title, message)
+	default:
+		return fmt.Errorf("unknown channel type %q (webhook, slack, email)", channel.Type)
+	}
+}
+
+// Render returns the message of an event with the template of a channel
+func Render(channel config.Notification, event Event) (string, error) {
+	text := channel.Template
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New(channel.Name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, event); err != nil {
+		return "", fmt.Errorf("error rendering template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// post sends a body to the URL of a webhook channel
+func post(channel config.Notification, body []byte, contentType string) error {
+	if channel.URL == "" {
+		return fmt.Errorf("no url")
+	}
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "portico")
+	for name, value := range channel.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded %s", channel.URL, resp.Status)
+	}
+	return nil
+}
+
+// contains reports whether a list has a value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
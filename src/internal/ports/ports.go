@@ -0,0 +1,178 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/maxvegac/portico/src/internal/addon"
+	"github.com/maxvegac/portico/src/internal/app"
+)
+
+// Range used when a host port is allocated automatically (ports add ... auto)
+const (
+	AutoRangeStart = 20000
+	AutoRangeEnd   = 29999
+)
+
+// Allocation is a host port published by Portico
+type Allocation struct {
+	HostPort      int
+	ContainerPort int
+	Protocol      string // tcp or udp
+	Kind          string // app, addon or proxy
+	Owner         string // app/service, addon instance name or caddy
+}
+
+// Registry holds the host ports allocated across all apps and addon instances.
+// It is derived from docker-compose.yml files and the addons config, so it is
+// always in sync with what is actually published
+type Registry struct {
+	Allocations []Allocation
+}
+
+// Scan builds the registry from the apps and addon instances on this server
+func Scan(appsDir, addonsDir string) (*Registry, error) {
+	registry := &Registry{
+		// Reserved for the Caddy reverse proxy
+		Allocations: []Allocation{
+			{HostPort: 80, ContainerPort: 80, Protocol: "tcp", Kind: "proxy", Owner: "caddy"},
+			{HostPort: 443, ContainerPort: 443, Protocol: "tcp", Kind: "proxy", Owner: "caddy"},
+		},
+	}
+
+	// App services (extra ports)
+	am := app.NewManager(appsDir, "")
+	appNames, err := am.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+	for _, appName := range appNames {
+		a, err := am.LoadApp(appName)
+		if err != nil {
+			continue
+		}
+		for _, svc := range a.Services {
+			for _, mapping := range svc.ExtraPorts {
+				hostPort, containerPort, protocol, err := ParseMapping(mapping)
+				if err != nil {
+					continue
+				}
+				registry.Allocations = append(registry.Allocations, Allocation{
+					HostPort:      hostPort,
+					ContainerPort: containerPort,
+					Protocol:      protocol,
+					Kind:          "app",
+					Owner:         appName + "/" + svc.Name,
+				})
+			}
+		}
+	}
+
+	// Addon instances
+	addonManager := addon.NewManager(addonsDir, filepath.Join(addonsDir, "instances"))
+	addonConfig, err := addonManager.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading addons config: %w", err)
+	}
+	for name, inst := range addonConfig.Instances {
+		if inst.Port == 0 {
+			continue
+		}
+		registry.Allocations = append(registry.Allocations, Allocation{
+			HostPort: inst.Port,
+			Protocol: "tcp",
+			Kind:     "addon",
+			Owner:    name,
+		})
+	}
+
+	sort.Slice(registry.Allocations, func(i, j int) bool {
+		a, b := registry.Allocations[i], registry.Allocations[j]
+		if a.HostPort != b.HostPort {
+			return a.HostPort < b.HostPort
+		}
+		return a.Protocol < b.Protocol
+	})
+
+	return registry, nil
+}
+
+// Lookup returns the allocation using a host port, or nil if the port is free
+func (r *Registry) Lookup(hostPort int, protocol string) *Allocation {
+	for i := range r.Allocations {
+		if r.Allocations[i].HostPort == hostPort && r.Allocations[i].Protocol == protocol {
+			return &r.Allocations[i]
+		}
+	}
+	return nil
+}
+
+// Check returns an error if a host port is allocated by Portico or in use on the host
+func (r *Registry) Check(hostPort int, protocol string) error {
+	if alloc := r.Lookup(hostPort, protocol); alloc != nil {
+		return fmt.Errorf("host port %d/%s is already allocated to %s %s", hostPort, protocol, alloc.Kind, alloc.Owner)
+	}
+	if !HostPortAvailable(hostPort, protocol) {
+		return fmt.Errorf("host port %d/%s is in use by another process", hostPort, protocol)
+	}
+	return nil
+}
+
+// Allocate returns the first free host port starting at start (up to end)
+func (r *Registry) Allocate(protocol string, start, end int) (int, error) {
+	for port := start; port <= end; port++ {
+		if r.Check(port, protocol) == nil {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free %s port between %d and %d", protocol, start, end)
+}
+
+// ParseMapping parses a compose port mapping: [ip:]host:container[/protocol]
+func ParseMapping(mapping string) (hostPort, containerPort int, protocol string, err error) {
+	protocol = "tcp"
+	if idx := strings.LastIndex(mapping, "/"); idx >= 0 {
+		protocol = strings.ToLower(mapping[idx+1:])
+		mapping = mapping[:idx]
+	}
+
+	parts := strings.Split(mapping, ":")
+	if len(parts) < 2 {
+		return 0, 0, "", fmt.Errorf("invalid port mapping %s", mapping)
+	}
+	hostPort, err = strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid host port in %s", mapping)
+	}
+	containerPort, err = strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid container port in %s", mapping)
+	}
+
+	return hostPort, containerPort, protocol, nil
+}
+
+// HostPortAvailable reports whether a port can be bound on the host.
+// Privileged ports that can't be checked without root are assumed free
+func HostPortAvailable(port int, protocol string) bool {
+	address := fmt.Sprintf(":%d", port)
+	var err error
+	if protocol == "udp" {
+		var conn net.PacketConn
+		if conn, err = net.ListenPacket("udp", address); err == nil {
+			_ = conn.Close()
+		}
+	} else {
+		var listener net.Listener
+		if listener, err = net.Listen("tcp", address); err == nil {
+			_ = listener.Close()
+		}
+	}
+	return err == nil || errors.Is(err, syscall.EACCES)
+}
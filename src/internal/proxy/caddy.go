@@ -3,7 +3,9 @@ package proxy
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/maxvegac/portico/src/internal/embed"
 	"github.com/maxvegac/portico/src/internal/util"
@@ -21,7 +23,15 @@ func NewCaddyManager(configDir, _ string) *CaddyManager {
 	}
 }
 
-// UpdateCaddyfile copies the static Caddyfile to the proxy directory
+// AppsConfigDir returns the directory of the app Caddyfiles imported by the
+// proxy (<app>.caddy). Caddy only mounts this directory: the app directories
+// hold docker-compose.yml and secrets
+func AppsConfigDir(appsDir string) string {
+	return filepath.Join(filepath.Dir(appsDir), "caddy", "apps")
+}
+
+// UpdateCaddyfile copies the static Caddyfile to the proxy directory and the
+// Caddyfiles of the apps to AppsConfigDir
 func (cm *CaddyManager) UpdateCaddyfile(appsDir string) error {
 	caddyfilePath := filepath.Join(cm.ConfigDir, "Caddyfile")
 
@@ -44,9 +54,103 @@ func (cm *CaddyManager) UpdateCaddyfile(appsDir string) error {
 	// Fix file ownership if running as root
 	_ = util.FixFileOwnership(caddyfilePath)
 
+	if err := syncAppCaddyfiles(appsDir); err != nil {
+		return err
+	}
+	return cm.upgradeProxyCompose()
+}
+
+// appsConfigMount is the mount of AppsConfigDir in the proxy docker-compose.yml
+const appsConfigMount = "/home/portico/caddy/apps:/home/portico/caddy/apps:ro"
+
+// upgradeProxyCompose replaces a proxy docker-compose.yml written by an older
+// version, which mounted all of apps/ instead of AppsConfigDir, and recreates
+// the proxy: the Caddyfile now imports the app Caddyfiles from AppsConfigDir
+func (cm *CaddyManager) upgradeProxyCompose() error {
+	composePath := filepath.Join(cm.ConfigDir, "docker-compose.yml")
+	current, err := os.ReadFile(composePath)
+	if err != nil || strings.Contains(string(current), appsConfigMount) {
+		return nil // Not installed here, or up to date
+	}
+	if err := embed.ExtractStaticFile("static/reverse-proxy/docker-compose.yml", composePath); err != nil {
+		return err
+	}
+	if err := embed.ExtractStaticFile("static/reverse-proxy/Dockerfile", filepath.Join(cm.ConfigDir, "Dockerfile")); err != nil {
+		return err
+	}
+	_ = util.FixFileOwnership(composePath)
+
+	fmt.Println("Updating the reverse proxy: it now mounts only the app Caddyfiles")
+	cmd := exec.Command("docker", "compose", "-f", composePath, "up", "-d")
+	cmd.Dir = cm.ConfigDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error recreating the reverse proxy: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
 	return nil
 }
 
+// syncAppCaddyfiles copies apps/<app>/Caddyfile to AppsConfigDir as
+// <app>.caddy, and removes the files of apps without a Caddyfile
+func syncAppCaddyfiles(appsDir string) error {
+	dir := AppsConfigDir(appsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+
+	wanted := make(map[string]bool)
+	entries, err := os.ReadDir(appsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading apps directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(appsDir, entry.Name(), "Caddyfile"))
+		if err != nil {
+			continue // No HTTP
+		}
+		name := entry.Name() + ".caddy"
+		wanted[name] = true
+		target := filepath.Join(dir, name)
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return fmt.Errorf("error writing %s: %w", target, err)
+		}
+		_ = util.FixFileOwnership(target)
+	}
+
+	existing, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", dir, err)
+	}
+	for _, entry := range existing {
+		if filepath.Ext(entry.Name()) == ".caddy" && !wanted[entry.Name()] {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return fmt.Errorf("error removing %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// HasModule reports whether the running proxy's Caddy includes a module
+// (e.g. http.handlers.rate_limit, only in the Portico Caddy build)
+func (cm *CaddyManager) HasModule(module string) (bool, error) {
+	composePath := filepath.Join(cm.ConfigDir, "docker-compose.yml")
+	cmd := exec.Command("docker", "compose", "-f", composePath, "exec", "-T", "caddy", "caddy", "list-modules")
+	cmd.Dir = cm.ConfigDir
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("error listing the reverse proxy modules (is it running?): %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == module {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // ReloadCaddy reloads the Caddy configuration
 func (cm *CaddyManager) ReloadCaddy() error {
 	// This would typically send a signal to Caddy to reload
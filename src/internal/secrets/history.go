@@ -0,0 +1,174 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// HistoryDir is the directory next to a secrets directory (apps/<app>/ for
+// env/, the Portico home for the global secrets) that keeps the previous
+// versions of its secrets. It is outside the secrets directory because, with
+// plaintext secrets, env/ itself is mounted at /run/secrets. Versions are
+// encrypted like the secrets: without a master key they are plaintext too
+const HistoryDir = ".secrets-history"
+
+// legacyHistoryDir is where versions were kept before, inside the secrets directory
+const legacyHistoryDir = ".history"
+
+// historyLog is the file of a secret's history directory listing its versions
+const historyLog = "versions.yml"
+
+// Version is an entry of the history of a secret
+type Version struct {
+	Version int    `yaml:"version"`
+	Time    string `yaml:"time"`
+	Author  string `yaml:"author"`
+	Action  string `yaml:"action"` // add, edit, delete, rollback to vN
+	Deleted bool   `yaml:"deleted,omitempty"`
+}
+
+// historyRoot returns the history directory of a secrets directory
+func historyRoot(dir string) string {
+	MoveLegacyHistory(dir)
+	return filepath.Join(filepath.Dir(dir), HistoryDir)
+}
+
+// MoveLegacyHistory moves a history left inside a secrets directory by
+// earlier versions out of it, so it is no longer mounted in containers
+func MoveLegacyHistory(dir string) {
+	legacy := filepath.Join(dir, legacyHistoryDir)
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+	root := filepath.Join(filepath.Dir(dir), HistoryDir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		_ = os.Rename(legacy, root)
+	}
+}
+
+// secretHistoryDir returns the history directory of a secret
+func secretHistoryDir(dir, name string) string {
+	return filepath.Join(historyRoot(dir), name)
+}
+
+// History returns the versions of a secret, oldest first
+func History(dir, name string) ([]Version, error) {
+	data, err := os.ReadFile(filepath.Join(secretHistoryDir(dir, name), historyLog))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var versions []Version
+	if err := yaml.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("error parsing history of %s: %w", name, err)
+	}
+	return versions, nil
+}
+
+// WriteVersion writes a secret file and keeps a copy of the value as a new
+// version of its history (encrypted like the secret itself). Returns the version
+func (s *Store) WriteVersion(dir, name string, plaintext []byte, author, action string) (int, error) {
+	if err := s.WriteFile(filepath.Join(dir, name), plaintext); err != nil {
+		return 0, err
+	}
+	return s.record(dir, name, plaintext, Version{Author: author, Action: action})
+}
+
+// RecordDelete adds a deletion to the history of a secret, so the last value
+// can still be rolled back. The secret file itself is removed by the caller
+func (s *Store) RecordDelete(dir, name, author string) (int, error) {
+	return s.record(dir, name, nil, Version{Author: author, Action: "delete", Deleted: true})
+}
+
+// record appends a version to the history of a secret
+func (s *Store) record(dir, name string, plaintext []byte, version Version) (int, error) {
+	historyDir := secretHistoryDir(dir, name)
+	if err := os.MkdirAll(historyDir, 0o700); err != nil {
+		return 0, fmt.Errorf("error creating history directory: %w", err)
+	}
+	_ = os.Chmod(filepath.Dir(historyDir), 0o700)
+	_ = util.FixFileOwnership(filepath.Dir(historyDir))
+	_ = util.FixFileOwnership(historyDir)
+
+	versions, err := History(dir, name)
+	if err != nil {
+		return 0, err
+	}
+	version.Version = 1
+	if len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+	version.Time = time.Now().UTC().Format(time.RFC3339)
+
+	if !version.Deleted {
+		if err := s.WriteFile(filepath.Join(historyDir, strconv.Itoa(version.Version)), plaintext); err != nil {
+			return 0, fmt.Errorf("error writing version %d of %s: %w", version.Version, name, err)
+		}
+	}
+
+	data, err := yaml.Marshal(append(versions, version))
+	if err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(filepath.Join(historyDir, historyLog), data); err != nil {
+		return 0, fmt.Errorf("error writing history of %s: %w", name, err)
+	}
+	return version.Version, nil
+}
+
+// ReadVersion returns the value of a version of a secret
+func (s *Store) ReadVersion(dir, name string, version int) ([]byte, error) {
+	versions, err := History(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Version != version {
+			continue
+		}
+		if v.Deleted {
+			return nil, fmt.Errorf("version %d of %s is a deletion", version, name)
+		}
+		return s.ReadFile(filepath.Join(secretHistoryDir(dir, name), strconv.Itoa(version)))
+	}
+	return nil, fmt.Errorf("version %d of %s not found", version, name)
+}
+
+// historyFiles returns the stored versions of all secrets of a secrets directory
+func historyFiles(dir string) ([]string, error) {
+	root := historyRoot(dir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		versionFiles, err := ListFiles(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range versionFiles {
+			if filepath.Base(path) != historyLog {
+				files = append(files, path)
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,406 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxvegac/portico/src/internal/util"
+)
+
+// DefaultKeyFile is where the host master key lives. It is kept outside
+// /home/portico so backups of the apps and addons don't include it
+const DefaultKeyFile = "/etc/portico/secrets.key"
+
+// DefaultPorticoHome is where global secrets live when no config is available
+const DefaultPorticoHome = "/home/portico"
+
+// keySize is the size of the master key (AES-256)
+const keySize = 32
+
+// magic prefixes every encrypted secret file, followed by the nonce and the ciphertext
+var magic = []byte("PORTICO-SECRET-V1\n")
+
+// Store reads and writes secret files, encrypting them with the host master key
+// when it exists. Without a key, secrets are plain files (previous behaviour)
+type Store struct {
+	KeyFile string
+	keys    [][]byte // Current key first, then a pending one (interrupted rekey)
+}
+
+// NewStore creates a Store using the given master key file
+func NewStore(keyFile string) *Store {
+	if keyFile == "" {
+		keyFile = DefaultKeyFile
+	}
+	return &Store{KeyFile: keyFile}
+}
+
+// Enabled reports whether secrets are encrypted on this host (the master key exists)
+func (s *Store) Enabled() bool {
+	_, err := os.Stat(s.KeyFile)
+	return err == nil
+}
+
+// pendingKeyFile holds the new key while a rekey is in progress
+func (s *Store) pendingKeyFile() string {
+	return s.KeyFile + ".new"
+}
+
+// loadKeys reads the master key (and a pending rekey key, if any)
+func (s *Store) loadKeys() ([][]byte, error) {
+	if s.keys != nil {
+		return s.keys, nil
+	}
+
+	var keys [][]byte
+	for _, path := range []string{s.KeyFile, s.pendingKeyFile()} {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) && path != s.KeyFile {
+				continue
+			}
+			return nil, fmt.Errorf("error reading secrets key %s: %w", path, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("invalid secrets key %s: expected %d bytes, got %d", path, keySize, len(key))
+		}
+		keys = append(keys, key)
+	}
+
+	s.keys = keys
+	return keys, nil
+}
+
+// GenerateKey creates the master key. It fails if a key already exists
+func (s *Store) GenerateKey() error {
+	if s.Enabled() {
+		return fmt.Errorf("secrets key %s already exists", s.KeyFile)
+	}
+	if err := writeKey(s.KeyFile); err != nil {
+		return err
+	}
+	s.keys = nil
+	return nil
+}
+
+// writeKey writes a new random key readable only by its owner
+func writeKey(path string) error {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("error generating secrets key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating secrets key directory: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return fmt.Errorf("error writing secrets key: %w", err)
+	}
+
+	// The portico user runs deploys, so it must be able to read the key
+	_ = util.FixFileOwnership(path)
+	return nil
+}
+
+// IsEncrypted reports whether file content was written by an encrypting Store
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// ReadFile returns the plaintext of a secret file, decrypting it if needed
+func (s *Store) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	keys, err := s.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if plaintext, err := decrypt(key, data); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, fmt.Errorf("error decrypting %s: wrong secrets key or corrupted file", path)
+}
+
+// WriteFile writes a secret file, encrypted if the master key exists
+func (s *Store) WriteFile(path string, plaintext []byte) error {
+	data := plaintext
+	if s.Enabled() {
+		keys, err := s.loadKeys()
+		if err != nil {
+			return err
+		}
+		if data, err = encrypt(keys[0], plaintext); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, data)
+}
+
+// encrypt seals plaintext with AES-256-GCM
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	out := append([]byte{}, magic...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, magic), nil
+}
+
+// decrypt opens data written by encrypt
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(magic):]
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted secret is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, magic)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic replaces a file through a temporary file in the same directory,
+// so an interrupted write never leaves a half-written secret
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	// CreateTemp already uses 0600
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	// Fix file ownership if running as root
+	_ = util.FixFileOwnership(path)
+	return nil
+}
+
+// ListFiles returns the secret files of a secrets directory (env/ of an app,
+// secrets/ of an addon instance). Hidden files and directories are skipped
+func ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files, nil
+}
+
+// withHistory returns the secret files of a secrets directory and their stored versions
+func withHistory(dir string) ([]string, error) {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := historyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, versions...), nil
+}
+
+// Migrate encrypts the plaintext secret files of the given directories.
+// Returns the number of files encrypted
+func (s *Store) Migrate(dirs []string) (int, error) {
+	if !s.Enabled() {
+		return 0, fmt.Errorf("secrets key %s not found", s.KeyFile)
+	}
+
+	count := 0
+	for _, dir := range dirs {
+		files, err := withHistory(dir)
+		if err != nil {
+			return count, fmt.Errorf("error listing %s: %w", dir, err)
+		}
+		for _, path := range files {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return count, err
+			}
+			if IsEncrypted(data) {
+				continue
+			}
+			if err := s.WriteFile(path, data); err != nil {
+				return count, fmt.Errorf("error encrypting %s: %w", path, err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Rekey generates a new master key and re-encrypts the secret files of the
+// given directories with it. The new key is written next to the current one
+// first, so an interrupted rekey can be resumed by running it again
+func (s *Store) Rekey(dirs []string) (int, error) {
+	if !s.Enabled() {
+		return 0, fmt.Errorf("secrets key %s not found", s.KeyFile)
+	}
+
+	// Resume an interrupted rekey with its pending key
+	pending := s.pendingKeyFile()
+	if _, err := os.Stat(pending); os.IsNotExist(err) {
+		if err := writeKey(pending); err != nil {
+			return 0, err
+		}
+	}
+	s.keys = nil
+	keys, err := s.loadKeys()
+	if err != nil {
+		return 0, err
+	}
+	newKey := keys[len(keys)-1]
+
+	count := 0
+	for _, dir := range dirs {
+		files, err := withHistory(dir)
+		if err != nil {
+			return count, fmt.Errorf("error listing %s: %w", dir, err)
+		}
+		for _, path := range files {
+			plaintext, err := s.ReadFile(path)
+			if err != nil {
+				return count, err
+			}
+			data, err := encrypt(newKey, plaintext)
+			if err != nil {
+				return count, err
+			}
+			if err := writeFileAtomic(path, data); err != nil {
+				return count, fmt.Errorf("error re-encrypting %s: %w", path, err)
+			}
+			count++
+		}
+	}
+
+	if err := os.Rename(pending, s.KeyFile); err != nil {
+		return count, fmt.Errorf("error replacing secrets key: %w", err)
+	}
+	s.keys = nil
+	return count, nil
+}
+
+// GlobalDir is the directory of the global secrets, shared by the apps that link them
+func GlobalDir(porticoHome string) string {
+	return filepath.Join(porticoHome, "secrets")
+}
+
+// RuntimeBase is the tmpfs directory decrypted secrets are written to at deploy time
+func RuntimeBase() string {
+	if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+		return "/dev/shm/portico"
+	}
+	return filepath.Join(os.TempDir(), "portico-secrets")
+}
+
+// RuntimeDir returns the tmpfs directory mounted at /run/secrets for an app
+// (kind "apps") or an addon instance (kind "addons")
+func RuntimeDir(kind, name string) string {
+	return filepath.Join(RuntimeBase(), kind, name)
+}
+
+// Materialize decrypts the secret files of srcDir, and the extra files (global
+// secrets), into runtimeDir. Files are rewritten in place (same inode) because
+// running containers bind-mount them, and files no longer wanted are removed
+func (s *Store) Materialize(srcDir, runtimeDir string, extra ...string) error {
+	if err := os.MkdirAll(RuntimeBase(), 0o700); err != nil {
+		return fmt.Errorf("error creating %s: %w", RuntimeBase(), err)
+	}
+	_ = util.FixFileOwnership(RuntimeBase())
+	if err := os.MkdirAll(runtimeDir, 0o755); err != nil {
+		return fmt.Errorf("error creating %s: %w", runtimeDir, err)
+	}
+	_ = util.FixFileOwnership(filepath.Dir(runtimeDir))
+	_ = util.FixFileOwnership(runtimeDir)
+
+	files, err := ListFiles(srcDir)
+	if err != nil {
+		return fmt.Errorf("error listing %s: %w", srcDir, err)
+	}
+	files = append(files, extra...)
+
+	wanted := make(map[string]bool)
+	for _, path := range files {
+		plaintext, err := s.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(path)
+		wanted[name] = true
+
+		target := filepath.Join(runtimeDir, name)
+		if err := os.WriteFile(target, plaintext, 0o600); err != nil {
+			return fmt.Errorf("error writing %s: %w", target, err)
+		}
+		_ = util.FixFileOwnership(target)
+	}
+
+	// Remove secrets that were deleted
+	existing, err := os.ReadDir(runtimeDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range existing {
+		if !wanted[entry.Name()] {
+			_ = os.RemoveAll(filepath.Join(runtimeDir, entry.Name()))
+		}
+	}
+
+	return nil
+}
+
+// Clear removes the decrypted secrets of an app or addon instance
+func Clear(runtimeDir string) error {
+	return os.RemoveAll(runtimeDir)
+}
@@ -0,0 +1,279 @@
+// Package tui provides the terminal primitives of 'portico tui': raw mode,
+// key input and full-screen drawing with ANSI escape sequences
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"unicode/utf8"
+)
+
+// KeyCode identifies a key
+type KeyCode int
+
+// Keys read by Terminal.Keys
+const (
+	KeyRune KeyCode = iota // A printable character, in Key.Rune
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyCtrlC
+)
+
+// Key is a key press
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// Terminal is the controlling terminal in raw mode, showing the alternate screen
+type Terminal struct {
+	tty    *os.File
+	saved  string // stty settings to restore
+	mu     sync.Mutex
+	width  int
+	height int
+	resize chan struct{}
+}
+
+// Open switches the controlling terminal to raw mode and the alternate screen
+func Open() (*Terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("a terminal is required: %w", err)
+	}
+
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("error reading terminal settings: %w", err)
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("error setting raw mode: %w", err)
+	}
+
+	t := &Terminal{tty: tty, saved: strings.TrimSpace(saved), resize: make(chan struct{}, 1)}
+	t.updateSize()
+
+	// Track the terminal size
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			t.updateSize()
+			select {
+			case t.resize <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	// Alternate screen, hidden cursor
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l")
+	return t, nil
+}
+
+// Close restores the terminal
+func (t *Terminal) Close() {
+	fmt.Fprint(t.tty, "\x1b[0m\x1b[?25h\x1b[?1049l")
+	_, _ = stty(t.tty, t.saved)
+	t.tty.Close()
+}
+
+// Resized receives a value when the terminal size changes
+func (t *Terminal) Resized() <-chan struct{} {
+	return t.resize
+}
+
+// Size returns the width and height of the terminal
+func (t *Terminal) Size() (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.width, t.height
+}
+
+// updateSize reads the terminal size (80x24 if unknown)
+func (t *Terminal) updateSize() {
+	width, height := 80, 24
+	if out, err := stty(t.tty, "size"); err == nil {
+		var rows, cols int
+		if _, err := fmt.Sscanf(out, "%d %d", &rows, &cols); err == nil && rows > 0 && cols > 0 {
+			width, height = cols, rows
+		}
+	}
+	t.mu.Lock()
+	t.width, t.height = width, height
+	t.mu.Unlock()
+}
+
+// Draw replaces the screen with lines, cut to the terminal size. Lines may
+// contain styles (see Reverse and Bold); they are cut before being styled
+func (t *Terminal) Draw(lines []string) {
+	_, height := t.Size()
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\x1b[K") // Clear the rest of the line
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J") // Clear below
+	fmt.Fprint(t.tty, b.String())
+}
+
+// Keys reads key presses until the terminal is closed
+func (t *Terminal) Keys() <-chan Key {
+	keys := make(chan Key)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := t.tty.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range parseKeys(buf[:n]) {
+				keys <- key
+			}
+		}
+	}()
+	return keys
+}
+
+// escapeKeys are the escape sequences of the special keys
+var escapeKeys = map[string]KeyCode{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[5~": KeyPgUp,
+	"\x1b[6~": KeyPgDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// parseKeys splits the bytes of one read into key presses
+func parseKeys(data []byte) []Key {
+	var keys []Key
+	for len(data) > 0 {
+		if data[0] == 0x1b {
+			if len(data) == 1 {
+				keys = append(keys, Key{Code: KeyEsc})
+				return keys
+			}
+			matched := false
+			for seq, code := range escapeKeys {
+				if strings.HasPrefix(string(data), seq) {
+					keys = append(keys, Key{Code: code})
+					data = data[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				// Unknown sequence: skip it
+				keys = append(keys, Key{Code: KeyEsc})
+				return keys
+			}
+			continue
+		}
+
+		switch data[0] {
+		case '\r', '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		default:
+			r, size := utf8.DecodeRune(data)
+			if r >= 0x20 {
+				keys = append(keys, Key{Code: KeyRune, Rune: r})
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// Fit cuts or pads a line to width characters
+func Fit(line string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(strings.ReplaceAll(line, "\t", "    "))
+	if len(runes) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
+
+// Reverse shows a line in reverse video (selection)
+func Reverse(line string) string {
+	return "\x1b[7m" + line + "\x1b[0m"
+}
+
+// Bold shows a line in bold (titles)
+func Bold(line string) string {
+	return "\x1b[1m" + line + "\x1b[0m"
+}
+
+// stty runs stty on the terminal
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// ansiPattern matches ANSI escape sequences
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// Plain removes ANSI escape sequences and control characters from output of
+// other programs, so it can't break the screen
+func Plain(line string) string {
+	line = ansiPattern.ReplaceAllString(line, "")
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"bufio"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// CurrentActor returns who is running portico, for history and audit records:
+//   - the name of the SSH key the session was opened with (the comment set by
+//     "portico ssh add"), when sshd exposes it (ExposeAuthInfo yes)
+//   - the user that ran sudo, or the current user
+//
+// When portico runs as root, PORTICO_ACTOR names who it acts for (e.g. a CI
+// job) and is recorded next to the real caller: "alice (as ci)". It is
+// ignored otherwise, so a user can't record changes under another name
+func CurrentActor(porticoHome string) string {
+	actor := realActor(porticoHome)
+	if os.Geteuid() != 0 {
+		return actor
+	}
+	if as := strings.TrimSpace(os.Getenv("PORTICO_ACTOR")); as != "" && as != actor {
+		return actor + " (as " + as + ")"
+	}
+	return actor
+}
+
+// realActor returns the SSH key name, the sudo user or the current user
+func realActor(porticoHome string) string {
+	if name := sshKeyName(porticoHome); name != "" {
+		return name
+	}
+
+	// sudo sets SUDO_USER; for other users it's just an environment variable
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && os.Geteuid() == 0 {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// sshKeyName looks up the public key of the current SSH session
+// (SSH_USER_AUTH) in the authorized_keys of portico and returns its name
+func sshKeyName(porticoHome string) string {
+	authFile := os.Getenv("SSH_USER_AUTH")
+	if authFile == "" {
+		return ""
+	}
+	data, err := os.ReadFile(authFile)
+	if err != nil {
+		return ""
+	}
+
+	// Lines look like "publickey ssh-ed25519 AAAA..."
+	var sessionKeys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "publickey" {
+			sessionKeys = append(sessionKeys, fields[1]+" "+fields[2])
+		}
+	}
+	if len(sessionKeys) == 0 {
+		return ""
+	}
+
+	file, err := os.Open(filepath.Join(porticoHome, ".ssh", "authorized_keys"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		// Skip options (command="...", no-pty, ...) before the key type
+		for len(fields) >= 3 && !strings.HasPrefix(fields[0], "ssh-") && !strings.HasPrefix(fields[0], "ecdsa-") && !strings.HasPrefix(fields[0], "sk-") {
+			fields = fields[1:]
+		}
+		if len(fields) < 3 {
+			continue
+		}
+		for _, key := range sessionKeys {
+			if fields[0]+" "+fields[1] == key {
+				return strings.Join(fields[2:], " ")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,217 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EnvVar is a variable read from a dotenv file
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+var dotenvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ParseDotenv parses a dotenv file following the rules of docker compose:
+//   - blank lines and lines starting with # are ignored, "export " is allowed
+//   - unquoted values are trimmed, and " #" starts an inline comment
+//   - 'single quoted' values are literal and may span several lines
+//   - "double quoted" values may span several lines and support \n, \r, \t, \\, \" and \$
+//   - $VAR, ${VAR}, ${VAR:-default} and ${VAR-default} are expanded in unquoted and
+//     double quoted values, from earlier variables of the file only
+//
+// Values are returned in the syntax of Portico env values, which are resolved
+// when docker-compose.yml is generated: references to variables not in the file
+// (built-ins, ${addon:...}, other variables of the service) are left as ${VAR},
+// and a literal ${ is written $${. The environment of the process is never read,
+// so a KEY without "=" is an error.
+//
+// Variables are returned in file order; a repeated key keeps its last value
+func ParseDotenv(content string) ([]EnvVar, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff")
+
+	var vars []EnvVar
+	index := make(map[string]int)
+	lookup := func(key string) (string, bool) {
+		if i, ok := index[key]; ok {
+			return vars[i].Value, true
+		}
+		return "", false
+	}
+
+	lineNo := 0
+	for len(content) > 0 {
+		var line string
+		line, content = cutLine(content)
+		lineNo++
+		startLine := lineNo
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+
+		key, rest, hasValue := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !dotenvKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", startLine, key)
+		}
+
+		var value string
+		if !hasValue {
+			return nil, fmt.Errorf("line %d: %s has no value (write %s=value)", startLine, key, key)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		switch {
+		case strings.HasPrefix(rest, "'"), strings.HasPrefix(rest, `"`):
+			quote := rest[0]
+			body := rest[1:]
+			// Quoted values may continue on the next lines
+			end := closingQuote(body, quote)
+			for end < 0 && len(content) > 0 {
+				var next string
+				next, content = cutLine(content)
+				lineNo++
+				body += "\n" + next
+				end = closingQuote(body, quote)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated %c quoted value for %s", startLine, quote, key)
+			}
+			if trailing := strings.TrimSpace(body[end+1:]); trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return nil, fmt.Errorf("line %d: unexpected characters after quoted value for %s", startLine, key)
+			}
+			body = body[:end]
+			if quote == '\'' {
+				value = strings.ReplaceAll(body, "${", "$${")
+			} else {
+				value = expandDotenv(unescapeDotenv(body), lookup)
+			}
+		default:
+			// Inline comment: " #" outside quotes
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				rest = rest[:idx]
+			} else if idx := strings.Index(rest, "\t#"); idx >= 0 {
+				rest = rest[:idx]
+			}
+			value = expandDotenv(strings.TrimSpace(rest), lookup)
+		}
+
+		if i, ok := index[key]; ok {
+			vars[i].Value = value
+			continue
+		}
+		index[key] = len(vars)
+		vars = append(vars, EnvVar{Key: key, Value: value})
+	}
+
+	return vars, nil
+}
+
+// cutLine returns the first line of s and the rest
+func cutLine(s string) (string, string) {
+	line, rest, _ := strings.Cut(s, "\n")
+	return line, rest
+}
+
+// closingQuote returns the index of the closing quote, honoring \" in double quotes
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// escapedDollar stands for \$ between unescapeDotenv and expandDotenv
+const escapedDollar = "\x00"
+
+// unescapeDotenv processes the escapes of a double quoted value. \$ becomes a
+// marker so expandDotenv leaves a literal $
+func unescapeDotenv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '\\', '"':
+			b.WriteByte(s[i])
+		case '$':
+			b.WriteString(escapedDollar)
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+var dotenvVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?-)([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandDotenv expands $VAR, ${VAR}, ${VAR:-default} and ${VAR-default}.
+// Variables lookup doesn't know are left for Portico to resolve: $VAR and
+// ${VAR} as ${VAR}, and the forms with a default unchanged (they fail there)
+func expandDotenv(s string, lookup func(string) (string, bool)) string {
+	expanded := dotenvVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := dotenvVarPattern.FindStringSubmatch(match)
+		name := groups[1]
+		if name == "" {
+			name = groups[4]
+		}
+		value, ok := lookup(name)
+		if !ok {
+			if groups[2] == "" {
+				return "${" + name + "}"
+			}
+			return match
+		}
+		switch groups[2] {
+		case ":-":
+			if value == "" {
+				return groups[3]
+			}
+		case "-":
+			if !ok {
+				return groups[3]
+			}
+		}
+		return value
+	})
+	expanded = strings.ReplaceAll(expanded, escapedDollar+"{", "$${")
+	return strings.ReplaceAll(expanded, escapedDollar, "$")
+}
+
+var dotenvPlainValue = regexp.MustCompile(`^[A-Za-z0-9_./:@+,=%^-]*$`)
+
+// FormatDotenv formats a variable as a dotenv line that ParseDotenv (and docker
+// compose) reads back unchanged
+func FormatDotenv(key, value string) string {
+	switch {
+	case dotenvPlainValue.MatchString(value):
+		return key + "=" + value
+	case !strings.ContainsAny(value, "'\n\r"):
+		// Single quotes: literal, no expansion
+		return key + "='" + value + "'"
+	default:
+		replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+		return key + `="` + replacer.Replace(value) + `"`
+	}
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Charsets are the named character sets accepted by RandomString
+var Charsets = map[string]string{
+	"alnum":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"hex":     "0123456789abcdef",
+	"numeric": "0123456789",
+	// Only the symbols URLs keep unencoded (RFC 3986 unreserved), so values can
+	// be used as they are in the connection URLs of drivers
+	"symbols": "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~",
+}
+
+// CharsetNames returns the names of the available charsets, sorted
+func CharsetNames() []string {
+	names := make([]string, 0, len(Charsets))
+	for name := range Charsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RandomString returns a random string of the given length from a named
+// charset, using crypto/rand
+func RandomString(length int, charset string) (string, error) {
+	chars, ok := Charsets[charset]
+	if !ok {
+		return "", fmt.Errorf("unknown charset %s (use %s)", charset, strings.Join(CharsetNames(), ", "))
+	}
+	if length < 1 {
+		return "", fmt.Errorf("invalid length %d", length)
+	}
+
+	size := big.NewInt(int64(len(chars)))
+	var b strings.Builder
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", fmt.Errorf("error generating random value: %w", err)
+		}
+		b.WriteByte(chars[n.Int64()])
+	}
+	return b.String(), nil
+}
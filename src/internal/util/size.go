@@ -0,0 +1,69 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatBytes formats a size in bytes with binary units (e.g. 12.5MiB)
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// SizeUnits selects what the units of a size mean
+type SizeUnits int
+
+const (
+	// BinarySizes reads every unit as a power of 1024, the way docker reads
+	// memory limits: 512m, 512mb and 512MiB are the same
+	BinarySizes SizeUnits = iota
+	// DecimalSizes reads k, m, g, t (kB, MB...) as powers of 1000 and KiB,
+	// MiB... as powers of 1024, the way docker stats prints sizes
+	DecimalSizes
+)
+
+// sizePowers are the exponents of the unit prefixes
+var sizePowers = map[string]int{"": 0, "k": 1, "m": 2, "g": 3, "t": 4}
+
+// ParseBytes parses a size, a number with an optional unit b, k, m, g or t
+// (e.g. 512m, 1.5g), also written as KB, MiB... units says whether k, kb, m,
+// mb... are powers of 1024 or 1000; kib, mib... are always powers of 1024
+func ParseBytes(value string, units SizeUnits) (uint64, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := s, ""
+	if i >= 0 {
+		number, unit = s[:i], strings.TrimSpace(s[i:])
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512m, 1g)", value)
+	}
+
+	base := 1024.0
+	if units == DecimalSizes {
+		base = 1000
+	}
+	prefix := strings.TrimSuffix(unit, "b")
+	if p, ok := strings.CutSuffix(unit, "ib"); ok && p != "" {
+		prefix, base = p, 1024
+	}
+	power, ok := sizePowers[prefix]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512m, 1g)", value)
+	}
+	return uint64(n * math.Pow(base, float64(power))), nil
+}